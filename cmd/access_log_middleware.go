@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type requestStatsContextKey struct{}
+
+// requestStats collects libpostal usage of a single request for access log
+type requestStats struct {
+	mu            sync.Mutex
+	start         time.Time
+	firstCall     time.Time
+	libpostalTime time.Duration
+	calls         int
+	inputLength   int
+	resultCount   int
+}
+
+func withRequestStats(ctx context.Context, stats *requestStats) context.Context {
+	return context.WithValue(ctx, requestStatsContextKey{}, stats)
+}
+
+// requestStatsFrom returns stats of the request or nil (methods are nil safe)
+func requestStatsFrom(ctx context.Context) *requestStats {
+	stats, _ := ctx.Value(requestStatsContextKey{}).(*requestStats)
+	return stats
+}
+
+// recordCall registers a single libpostal call, which started at startedAt
+func (s *requestStats) recordCall(startedAt time.Time, input string, results int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.calls == 0 || startedAt.Before(s.firstCall) {
+		s.firstCall = startedAt
+	}
+	s.calls++
+	s.libpostalTime += time.Since(startedAt)
+	s.inputLength += utf8.RuneCountInString(input)
+	s.resultCount += results
+}
+
+// AccessLogMiddleware logs every request with request scoped logger. Latency
+// is split into queue time (before first libpostal call) and libpostal time
+func AccessLogMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stats := &requestStats{start: time.Now()}
+		ctx := withRequestStats(c.Request.Context(), stats)
+		// every log line of traced request can be found by trace ID
+		if traceID, ok := traceIDFrom(ctx); ok {
+			ctx = requestLogger(c).With().Str("trace_id", traceID).Logger().WithContext(ctx)
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		logger := requestLogger(c)
+
+		var event *zerolog.Event
+		switch {
+		case status >= 500:
+			event = logger.Error()
+		case status >= 400:
+			event = logger.Warn()
+		default:
+			event = logger.Info()
+		}
+
+		event = event.
+			Str("method", c.Request.Method).
+			Str("path", c.Request.URL.Path).
+			Str("route", c.FullPath()).
+			Int("status", status).
+			Str("client_ip", c.ClientIP()).
+			Dur("latency", time.Since(stats.start)).
+			Int("response_size", c.Writer.Size())
+
+		// addresses in query follow log_address_policy
+		if query := c.Request.URL.Query(); len(query) > 0 {
+			event = event.Interface("query", redactQuery(query))
+		}
+
+		stats.mu.Lock()
+		if stats.calls > 0 {
+			event = event.
+				Dur("queue_time", stats.firstCall.Sub(stats.start)).
+				Dur("libpostal_time", stats.libpostalTime).
+				Int("libpostal_calls", stats.calls).
+				Int("input_length", stats.inputLength).
+				Int("result_count", stats.resultCount)
+		}
+		stats.mu.Unlock()
+
+		if identity := c.GetString(gin.AuthUserKey); identity != "" {
+			event = event.Str("auth", identity)
+		}
+
+		msg := c.Errors.String()
+		if msg == "" {
+			msg = "Request"
+		}
+		event.Msg(msg)
+	}
+}
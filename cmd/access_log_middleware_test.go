@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// captureLogs redirects global logger into buffer for the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	logger, level := log.Logger, zerolog.GlobalLevel()
+	log.Logger = zerolog.New(&buf)
+	zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	t.Cleanup(func() {
+		log.Logger = logger
+		zerolog.SetGlobalLevel(level)
+	})
+	return &buf
+}
+
+func TestAccessLogMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	buf := captureLogs(t)
+
+	router := gin.New()
+	router.Use(RequestIDMiddleware())
+	router.Use(AccessLogMiddleware())
+	router.Use(BasicAuthMiddleware(func() (string, string) { return "admin", "secret" }))
+	router.GET("/expand", func(c *gin.Context) {
+		stats := requestStatsFrom(c.Request.Context())
+		stats.recordCall(time.Now(), "781 Franklin Ave", 3)
+		stats.recordCall(time.Now(), "Brooklyn", 1)
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/expand?address=test", nil)
+	req.Header.Set(RequestIDHeader, "access-log-test")
+	req.SetBasicAuth("admin", "secret")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var entry map[string]any
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "access-log-test", entry["request_id"])
+	assert.Equal(t, "GET", entry["method"])
+	assert.Equal(t, "/expand", entry["route"])
+	assert.Equal(t, float64(http.StatusOK), entry["status"])
+	assert.Equal(t, "admin", entry["auth"])
+	assert.Equal(t, float64(2), entry["libpostal_calls"])
+	assert.Equal(t, float64(24), entry["input_length"])
+	assert.Equal(t, float64(4), entry["result_count"])
+	assert.Contains(t, entry, "latency")
+	assert.Contains(t, entry, "queue_time")
+	assert.Contains(t, entry, "libpostal_time")
+	assert.Equal(t, map[string]any{"address": "test"}, entry["query"])
+}
+
+func TestAccessLogMiddlewareRedactsAddress(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	buf := captureLogs(t)
+	useConfig(t, &Config{LogFormat: "json", LogAddressPolicy: AddressLogPolicyLengthOnly})
+
+	router := gin.New()
+	router.Use(AccessLogMiddleware())
+	router.GET("/parse", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/parse?address=781+Franklin+Ave&language=en", nil)
+	router.ServeHTTP(w, req)
+
+	assert.NotContains(t, buf.String(), "Franklin")
+
+	var entry map[string]any
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "/parse", entry["path"])
+	assert.Equal(t, map[string]any{"address": "[length=16]", "language": "en"}, entry["query"])
+}
+
+func TestAccessLogMiddlewareWithoutLibpostalCalls(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	buf := captureLogs(t)
+
+	router := gin.New()
+	router.Use(RequestIDMiddleware())
+	router.Use(AccessLogMiddleware())
+	router.NoRoute(func(c *gin.Context) {
+		abortWithError(c, http.StatusNotFound, "not found")
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/missing", nil)
+	router.ServeHTTP(w, req)
+
+	var entry map[string]any
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "warn", entry["level"])
+	assert.Equal(t, w.Header().Get(RequestIDHeader), entry["request_id"])
+	assert.NotContains(t, entry, "queue_time")
+	assert.NotContains(t, entry, "auth")
+}
+
+func TestRequestStatsNilSafe(t *testing.T) {
+	var stats *requestStats
+	assert.NotPanics(t, func() {
+		stats.recordCall(time.Now(), "address", 1)
+	})
+}
@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+)
+
+// output standards of /parse and /normalize, which map parsed components
+// into address schemas
+const (
+	StandardSchemaOrg       string = "schema_org"
+	StandardVCard           string = "vcard"
+	StandardOSM             string = "osm"
+	StandardLibaddressinput string = "libaddressinput"
+)
+
+// addressFormatters map parsed components (with resolved ISO codes) into
+// output standard
+var addressFormatters = map[string]func([]ParsedAddressComponent) any{
+	StandardUSPS: func(components []ParsedAddressComponent) any {
+		return formatUSPS(componentValues(components))
+	},
+	StandardSchemaOrg: func(components []ParsedAddressComponent) any {
+		return formatSchemaOrg(components)
+	},
+	StandardVCard: func(components []ParsedAddressComponent) any {
+		return formatVCard(components)
+	},
+	StandardOSM: func(components []ParsedAddressComponent) any {
+		return formatOSM(components)
+	},
+	StandardLibaddressinput: func(components []ParsedAddressComponent) any {
+		return formatLibaddressinput(components)
+	},
+}
+
+// labels of street line and of unit inside the building
+var (
+	streetLabels = []string{"house_number", "road"}
+	unitLabels   = []string{"unit", "level", "staircase", "entrance"}
+)
+
+// SchemaOrgPostalAddress is schema.org PostalAddress in JSON-LD
+type SchemaOrgPostalAddress struct {
+	Context             string `json:"@context"`
+	Type                string `json:"@type"`
+	Name                string `json:"name,omitempty"`
+	StreetAddress       string `json:"streetAddress,omitempty"`
+	PostOfficeBoxNumber string `json:"postOfficeBoxNumber,omitempty"`
+	AddressLocality     string `json:"addressLocality,omitempty"`
+	AddressRegion       string `json:"addressRegion,omitempty"`
+	PostalCode          string `json:"postalCode,omitempty"`
+	AddressCountry      string `json:"addressCountry,omitempty"`
+}
+
+// VCardAddress is vCard ADR property (RFC 6350) and its components
+type VCardAddress struct {
+	ADR             string `json:"adr"`
+	PostOfficeBox   string `json:"post_office_box,omitempty"`
+	ExtendedAddress string `json:"extended_address,omitempty"`
+	StreetAddress   string `json:"street_address,omitempty"`
+	Locality        string `json:"locality,omitempty"`
+	Region          string `json:"region,omitempty"`
+	PostalCode      string `json:"postal_code,omitempty"`
+	CountryName     string `json:"country_name,omitempty"`
+}
+
+// LibaddressinputAddress is address in libaddressinput AddressData fields
+type LibaddressinputAddress struct {
+	RegionCode         string   `json:"region_code,omitempty"`
+	AdministrativeArea string   `json:"administrative_area,omitempty"`
+	Locality           string   `json:"locality,omitempty"`
+	DependentLocality  string   `json:"dependent_locality,omitempty"`
+	PostalCode         string   `json:"postal_code,omitempty"`
+	AddressLines       []string `json:"address_line,omitempty"`
+	Organization       string   `json:"organization,omitempty"`
+}
+
+// joinComponents joins values of components with the labels in parsed order,
+// so "10 main st" and "main st 10" keep order of the address
+func joinComponents(components []ParsedAddressComponent, labels ...string) string {
+	values := make([]string, 0, len(labels))
+	for _, component := range components {
+		for _, label := range labels {
+			if component.Label == label && component.Value != "" {
+				values = append(values, component.Value)
+			}
+		}
+	}
+	return strings.Join(values, " ")
+}
+
+// localityLabels returns labels of locality and sub-locality. City is
+// locality and city_district (or suburb) is sub-locality. Without city
+// first of city_district and suburb is locality (e.g. "brooklyn" in NYC)
+func localityLabels(values map[string]string) (string, string) {
+	var labels []string
+	for _, label := range []string{"city", "city_district", "suburb"} {
+		if values[label] != "" {
+			labels = append(labels, label)
+		}
+	}
+	switch len(labels) {
+	case 0:
+		return "", ""
+	case 1:
+		return labels[0], ""
+	}
+	return labels[0], labels[1]
+}
+
+// countryCode returns resolved ISO 3166-1 code of parsed country
+func countryCode(components []ParsedAddressComponent) string {
+	for _, component := range components {
+		if component.Label == "country" && component.CountryCode != "" {
+			return component.CountryCode
+		}
+	}
+	return ""
+}
+
+// formatSchemaOrg maps components into schema.org PostalAddress. Venue name
+// is name, units are part of street address, sub-locality, near and
+// category have no schema.org property and are dropped
+func formatSchemaOrg(components []ParsedAddressComponent) SchemaOrgPostalAddress {
+	values := componentValues(components)
+	locality, _ := localityLabels(values)
+	country := countryCode(components)
+	if country == "" {
+		country = values["country"]
+	}
+
+	return SchemaOrgPostalAddress{
+		Context:             "https://schema.org",
+		Type:                "PostalAddress",
+		Name:                values["house"],
+		StreetAddress:       joinComponents(components, slices.Concat(streetLabels, unitLabels)...),
+		PostOfficeBoxNumber: values["po_box"],
+		AddressLocality:     values[locality],
+		AddressRegion:       values["state"],
+		PostalCode:          values["postcode"],
+		AddressCountry:      country,
+	}
+}
+
+// vcardEscaper escapes ADR component values (RFC 6350, section 3.4)
+var vcardEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\n", `\n`)
+
+// formatVCard maps components into vCard ADR. Venue name and units are
+// extended address, sub-locality, near and category are dropped
+func formatVCard(components []ParsedAddressComponent) VCardAddress {
+	values := componentValues(components)
+	locality, _ := localityLabels(values)
+
+	address := VCardAddress{
+		PostOfficeBox:   values["po_box"],
+		ExtendedAddress: joinComponents(components, slices.Concat([]string{"house"}, unitLabels)...),
+		StreetAddress:   joinComponents(components, streetLabels...),
+		Locality:        values[locality],
+		Region:          values["state"],
+		PostalCode:      values["postcode"],
+		CountryName:     values["country"],
+	}
+	fields := []string{
+		address.PostOfficeBox,
+		address.ExtendedAddress,
+		address.StreetAddress,
+		address.Locality,
+		address.Region,
+		address.PostalCode,
+		address.CountryName,
+	}
+	for i, field := range fields {
+		fields[i] = vcardEscaper.Replace(field)
+	}
+	address.ADR = "ADR:" + strings.Join(fields, ";")
+	return address
+}
+
+// osmTags are OSM addr:* keys of libpostal labels. Country is mapped
+// separately, because OSM requires ISO code
+var osmTags = map[string]string{
+	"house":          "addr:housename",
+	"house_number":   "addr:housenumber",
+	"road":           "addr:street",
+	"unit":           "addr:unit",
+	"level":          "addr:floor",
+	"suburb":         "addr:suburb",
+	"city_district":  "addr:district",
+	"city":           "addr:city",
+	"state_district": "addr:county",
+	"state":          "addr:state",
+	"postcode":       "addr:postcode",
+}
+
+// formatOSM maps components into OSM addr:* tags. Labels without
+// addr:* key (near, category, etc) are dropped
+func formatOSM(components []ParsedAddressComponent) map[string]string {
+	values := componentValues(components)
+	tags := make(map[string]string)
+	for label, tag := range osmTags {
+		if value := values[label]; value != "" {
+			tags[tag] = value
+		}
+	}
+	if country := countryCode(components); country != "" {
+		tags["addr:country"] = country
+	}
+	return tags
+}
+
+// formatLibaddressinput maps components into libaddressinput fields.
+// Venue name is organization, street and units are address lines,
+// near and category are dropped
+func formatLibaddressinput(components []ParsedAddressComponent) LibaddressinputAddress {
+	values := componentValues(components)
+	locality, dependentLocality := localityLabels(values)
+
+	address := LibaddressinputAddress{
+		RegionCode:         countryCode(components),
+		AdministrativeArea: values["state"],
+		Locality:           values[locality],
+		DependentLocality:  values[dependentLocality],
+		PostalCode:         values["postcode"],
+		Organization:       values["house"],
+	}
+	for _, line := range []string{
+		joinComponents(components, slices.Concat(streetLabels, []string{"po_box"})...),
+		joinComponents(components, unitLabels...),
+	} {
+		if line != "" {
+			address.AddressLines = append(address.AddressLines, line)
+		}
+	}
+	return address
+}
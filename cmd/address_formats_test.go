@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// brooklynComponents are parsed components of
+// "Barboncino, 781 Franklin Ave Apt 2 Crown Heights Brooklyn NY 11216 USA"
+func brooklynComponents() []ParsedAddressComponent {
+	components := []ParsedAddressComponent{
+		{Label: "house", Value: "barboncino"},
+		{Label: "house_number", Value: "781"},
+		{Label: "road", Value: "franklin ave"},
+		{Label: "unit", Value: "apt 2"},
+		{Label: "suburb", Value: "crown heights"},
+		{Label: "city_district", Value: "brooklyn"},
+		{Label: "state", Value: "ny"},
+		{Label: "postcode", Value: "11216"},
+		{Label: "country", Value: "usa"},
+	}
+	annotateISOCodes(components, "")
+	return components
+}
+
+func TestLocalityLabels(t *testing.T) {
+	locality, subLocality := localityLabels(map[string]string{"city": "beijing", "city_district": "chaoyang", "suburb": "sanlitun"})
+	assert.Equal(t, "city", locality)
+	assert.Equal(t, "city_district", subLocality)
+
+	locality, subLocality = localityLabels(map[string]string{"city_district": "brooklyn", "suburb": "crown heights"})
+	assert.Equal(t, "city_district", locality)
+	assert.Equal(t, "suburb", subLocality)
+
+	locality, subLocality = localityLabels(map[string]string{"road": "main st"})
+	assert.Empty(t, locality)
+	assert.Empty(t, subLocality)
+}
+
+func TestFormatSchemaOrg(t *testing.T) {
+	assert.Equal(t, SchemaOrgPostalAddress{
+		Context:         "https://schema.org",
+		Type:            "PostalAddress",
+		Name:            "barboncino",
+		StreetAddress:   "781 franklin ave apt 2",
+		AddressLocality: "brooklyn",
+		AddressRegion:   "ny",
+		PostalCode:      "11216",
+		AddressCountry:  "US",
+	}, formatSchemaOrg(brooklynComponents()))
+}
+
+func TestFormatVCard(t *testing.T) {
+	address := formatVCard(brooklynComponents())
+	assert.Equal(t, "ADR:;barboncino apt 2;781 franklin ave;brooklyn;ny;11216;usa", address.ADR)
+	assert.Equal(t, "781 franklin ave", address.StreetAddress)
+
+	// separators are escaped
+	address = formatVCard([]ParsedAddressComponent{{Label: "road", Value: "main st; rear, left"}})
+	assert.Equal(t, `ADR:;;main st\; rear\, left;;;;`, address.ADR)
+}
+
+func TestFormatOSM(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		"addr:housename":   "barboncino",
+		"addr:housenumber": "781",
+		"addr:street":      "franklin ave",
+		"addr:unit":        "apt 2",
+		"addr:suburb":      "crown heights",
+		"addr:district":    "brooklyn",
+		"addr:state":       "ny",
+		"addr:postcode":    "11216",
+		"addr:country":     "US",
+	}, formatOSM(brooklynComponents()))
+
+	// near and category have no addr:* tags, not resolved country is dropped
+	tags := formatOSM([]ParsedAddressComponent{
+		{Label: "category", Value: "restaurant"},
+		{Label: "near", Value: "near"},
+		{Label: "country", Value: "atlantis"},
+	})
+	assert.Empty(t, tags)
+}
+
+func TestFormatLibaddressinput(t *testing.T) {
+	assert.Equal(t, LibaddressinputAddress{
+		RegionCode:         "US",
+		AdministrativeArea: "ny",
+		Locality:           "brooklyn",
+		DependentLocality:  "crown heights",
+		PostalCode:         "11216",
+		AddressLines:       []string{"781 franklin ave", "apt 2"},
+		Organization:       "barboncino",
+	}, formatLibaddressinput(brooklynComponents()))
+}
+
+func TestParseRouteStandards(t *testing.T) {
+	router := SetupRouter()
+	address := url.QueryEscape("781 Franklin Ave Crown Heights Brooklyn NY 11216 USA")
+
+	for standard := range addressFormatters {
+		t.Run(standard, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/parse?address="+address+"&standard="+standard, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.True(t, json.Valid(w.Body.Bytes()))
+		})
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/parse?address="+address+"&standard=osm", nil)
+	router.ServeHTTP(w, req)
+
+	var tags map[string]string
+	err := json.Unmarshal(w.Body.Bytes(), &tags)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "781", tags["addr:housenumber"])
+	assert.Equal(t, "US", tags["addr:country"])
+}
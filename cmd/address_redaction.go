@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// policies for addresses (personal data) in logs
+const (
+	// auto is privacy safe for json logs (hashed if hash key is set,
+	// otherwise redacted) and full for text logs (local development)
+	AddressLogPolicyAuto       string = "auto"
+	AddressLogPolicyFull       string = "full"
+	AddressLogPolicyHashed     string = "hashed"
+	AddressLogPolicyRedacted   string = "redacted"
+	AddressLogPolicyLengthOnly string = "length_only"
+)
+
+// query params, which contain addresses (or parts of them, e.g. postal code
+// of /postcode) and must follow the log policy
+var addressQueryParams = map[string]bool{
+	"address":  true,
+	"postcode": true,
+}
+
+// addressLogPolicy resolves "auto" policy for the config
+func addressLogPolicy(cfg *Config) string {
+	policy := strings.ToLower(cfg.LogAddressPolicy)
+	if policy != AddressLogPolicyAuto && policy != "" {
+		return policy
+	}
+	if strings.ToLower(cfg.LogFormat) != "json" {
+		return AddressLogPolicyFull
+	}
+	if cfg.LogAddressHashKey != "" {
+		return AddressLogPolicyHashed
+	}
+	return AddressLogPolicyRedacted
+}
+
+// redactAddress prepares address for logging according to current policy
+func redactAddress(address string) string {
+	cfg := currentConfig()
+	return redactAddressWithPolicy(address, addressLogPolicy(cfg), cfg.LogAddressHashKey)
+}
+
+func redactAddressWithPolicy(address, policy, hashKey string) string {
+	switch policy {
+	case AddressLogPolicyFull:
+		return address
+	case AddressLogPolicyHashed:
+		// keyed hash: same address gives same value in every log line and
+		// replica, but can not be brute forced without the key
+		mac := hmac.New(sha256.New, []byte(hashKey))
+		mac.Write([]byte(address))
+		return "hmac:" + hex.EncodeToString(mac.Sum(nil))[:32]
+	case AddressLogPolicyLengthOnly:
+		return fmt.Sprintf("[length=%d]", utf8.RuneCountInString(address))
+	default:
+		return RedactedValue
+	}
+}
+
+// redactQuery applies address log policy to the query params with addresses
+func redactQuery(query url.Values) map[string]any {
+	redacted := make(map[string]any, len(query))
+	for key, values := range query {
+		if addressQueryParams[key] {
+			values = mapStrings(values, redactAddress)
+		}
+		if len(values) == 1 {
+			redacted[key] = values[0]
+		} else {
+			redacted[key] = values
+		}
+	}
+	return redacted
+}
+
+func mapStrings(values []string, fn func(string) string) []string {
+	mapped := make([]string, len(values))
+	for i, value := range values {
+		mapped[i] = fn(value)
+	}
+	return mapped
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// useConfig makes cfg active for the test
+func useConfig(t *testing.T, cfg *Config) {
+	previous := activeConfig.Load()
+	activeConfig.Store(cfg)
+	t.Cleanup(func() { activeConfig.Store(previous) })
+}
+
+func TestAddressLogPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		expected string
+	}{
+		{name: "Auto Text", cfg: Config{LogFormat: "text", LogAddressPolicy: "auto"}, expected: AddressLogPolicyFull},
+		{name: "Auto Json", cfg: Config{LogFormat: "json", LogAddressPolicy: "auto"}, expected: AddressLogPolicyRedacted},
+		{name: "Auto Json With Key", cfg: Config{LogFormat: "JSON", LogAddressHashKey: "key"}, expected: AddressLogPolicyHashed},
+		{name: "Explicit", cfg: Config{LogFormat: "json", LogAddressPolicy: "length_only"}, expected: AddressLogPolicyLengthOnly},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, addressLogPolicy(&tt.cfg))
+		})
+	}
+}
+
+func TestRedactAddressWithPolicy(t *testing.T) {
+	address := "781 Franklin Ave Crown Heights Brooklyn NY 11216"
+
+	assert.Equal(t, address, redactAddressWithPolicy(address, AddressLogPolicyFull, ""))
+	assert.Equal(t, RedactedValue, redactAddressWithPolicy(address, AddressLogPolicyRedacted, ""))
+	assert.Equal(t, "[length=48]", redactAddressWithPolicy(address, AddressLogPolicyLengthOnly, ""))
+
+	hashed := redactAddressWithPolicy(address, AddressLogPolicyHashed, "key")
+	assert.Regexp(t, `^hmac:[0-9a-f]{32}$`, hashed)
+	assert.NotContains(t, hashed, "Franklin")
+	// same address and key can be correlated across log lines
+	assert.Equal(t, hashed, redactAddressWithPolicy(address, AddressLogPolicyHashed, "key"))
+	// but another key gives another value
+	assert.NotEqual(t, hashed, redactAddressWithPolicy(address, AddressLogPolicyHashed, "other-key"))
+}
+
+func TestRedactQuery(t *testing.T) {
+	useConfig(t, &Config{LogFormat: "json", LogAddressPolicy: AddressLogPolicyRedacted})
+
+	query := url.Values{
+		"address":   []string{"781 Franklin Ave"},
+		"languages": []string{"en", "fr"},
+		"lowercase": []string{"false"},
+	}
+
+	assert.Equal(t, map[string]any{
+		"address":   RedactedValue,
+		"languages": []string{"en", "fr"},
+		"lowercase": "false",
+	}, redactQuery(query))
+
+	postcodeQuery := url.Values{
+		"postcode": []string{"SW1A 1AA"},
+		"country":  []string{"GB"},
+	}
+	assert.Equal(t, map[string]any{
+		"postcode": RedactedValue,
+		"country":  "GB",
+	}, redactQuery(postcodeQuery))
+}
+
+func TestConfigValidateHashedPolicy(t *testing.T) {
+	cfg := validConfig()
+	cfg.LogAddressPolicy = AddressLogPolicyHashed
+	assert.ErrorContains(t, cfg.Validate(), "log_address_hash_key is required when log_address_policy is hashed")
+
+	cfg.LogAddressHashKey = "key"
+	assert.NoError(t, cfg.Validate())
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CredentialsFunc returns username and password, expected from client
+type CredentialsFunc func() (string, string)
+
+// BasicAuthMiddleware works like gin.BasicAuth, but asks for credentials
+// on every request, so they can be rotated without server restart.
+// Empty username disables the check
+func BasicAuthMiddleware(credentialsFunc CredentialsFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		expectedUser, expectedPassword := credentialsFunc()
+		if expectedUser == "" {
+			c.Next()
+			return
+		}
+
+		user, password, ok := c.Request.BasicAuth()
+		// compare both values to not leak, which one is wrong, by timing
+		userMatch := subtle.ConstantTimeCompare([]byte(user), []byte(expectedUser))
+		passwordMatch := subtle.ConstantTimeCompare([]byte(password), []byte(expectedPassword))
+		if !ok || userMatch&passwordMatch != 1 {
+			c.Header("WWW-Authenticate", `Basic realm="Authorization Required"`)
+			abortWithError(c, http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+			return
+		}
+
+		c.Set(gin.AuthUserKey, user)
+		c.Next()
+	}
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBasicAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	username, password := "admin", "secret"
+
+	router := gin.New()
+	router.Use(BasicAuthMiddleware(func() (string, string) {
+		return username, password
+	}))
+	router.GET("/protected", func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString(gin.AuthUserKey))
+	})
+
+	request := func(user, pass string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/protected", nil)
+		if user != "" || pass != "" {
+			req.SetBasicAuth(user, pass)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Missing Credentials", func(t *testing.T) {
+		w := request("", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, `Basic realm="Authorization Required"`, w.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("Invalid Password", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, request("admin", "wrong").Code)
+	})
+
+	t.Run("Valid Credentials", func(t *testing.T) {
+		w := request("admin", "secret")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "admin", w.Body.String())
+	})
+
+	t.Run("Rotated Password", func(t *testing.T) {
+		password = "rotated"
+		t.Cleanup(func() { password = "secret" })
+
+		assert.Equal(t, http.StatusUnauthorized, request("admin", "secret").Code)
+		assert.Equal(t, http.StatusOK, request("admin", "rotated").Code)
+	})
+
+	t.Run("Disabled Without Username", func(t *testing.T) {
+		username = ""
+		t.Cleanup(func() { username = "admin" })
+
+		assert.Equal(t, http.StatusOK, request("", "").Code)
+	})
+}
@@ -18,7 +18,7 @@ func Middleware(tokenVerificationFunc TokenVerificationFunc) gin.HandlerFunc {
 
 		// Authorization header is missing in HTTP request
 		if authHeader == "" {
-			c.AbortWithStatus(http.StatusUnauthorized)
+			abortWithError(c, http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
 			return
 		}
 
@@ -27,7 +27,7 @@ func Middleware(tokenVerificationFunc TokenVerificationFunc) gin.HandlerFunc {
 		// The value of authorization header is invalid
 		// It should start with "Bearer ", then the token value
 		if len(authTokens) != 2 || strings.ToLower(authTokens[0]) != "bearer" {
-			c.AbortWithStatus(http.StatusUnauthorized)
+			abortWithError(c, http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
 			return
 		}
 
@@ -36,17 +36,38 @@ func Middleware(tokenVerificationFunc TokenVerificationFunc) gin.HandlerFunc {
 			return
 		}
 
+		// Identity for access log, token itself must never be logged
+		if _, exists := c.Get(gin.AuthUserKey); !exists {
+			c.Set(gin.AuthUserKey, "bearer")
+		}
+
 		// Everything looks fine, process next action
 		c.Next()
 	}
 }
 
 func MiddlewareWithStaticToken(token string) gin.HandlerFunc {
-	return Middleware(func(s string, c *gin.Context) bool {
-		if subtle.ConstantTimeCompare([]byte(s), []byte(token)) != 1 {
-			c.AbortWithStatus(http.StatusUnauthorized)
+	return MiddlewareWithTokenSource(func() string {
+		return token
+	})
+}
+
+// MiddlewareWithTokenSource asks for expected token on every request,
+// so it can be rotated without server restart. Empty token disables the check
+func MiddlewareWithTokenSource(tokenSource func() string) gin.HandlerFunc {
+	verify := Middleware(func(s string, c *gin.Context) bool {
+		if subtle.ConstantTimeCompare([]byte(s), []byte(tokenSource())) != 1 {
+			abortWithError(c, http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
 			return false
 		}
 		return true
 	})
+
+	return func(c *gin.Context) {
+		if tokenSource() == "" {
+			c.Next()
+			return
+		}
+		verify(c)
+	}
 }
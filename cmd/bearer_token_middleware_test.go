@@ -63,3 +63,36 @@ func TestMiddlewareWithStaticToken(t *testing.T) {
 		})
 	}
 }
+
+func TestMiddlewareWithTokenSource(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	token := "first-token"
+
+	router := gin.New()
+	router.Use(MiddlewareWithTokenSource(func() string { return token }))
+	router.GET("/protected", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func(authHeader string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/protected", nil)
+		if authHeader != "" {
+			req.Header.Set("Authorization", authHeader)
+		}
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request("Bearer first-token"))
+
+	// token was rotated (config reload)
+	token = "second-token"
+	assert.Equal(t, http.StatusUnauthorized, request("Bearer first-token"))
+	assert.Equal(t, http.StatusOK, request("Bearer second-token"))
+
+	// empty token disables auth
+	token = ""
+	assert.Equal(t, http.StatusOK, request(""))
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	gopostalExpand "github.com/openvenues/gopostal/expand"
+)
+
+// CanonicalRuleVersion is changed every time ranking rule is changed,
+// because it changes canonical values and their hashes
+const CanonicalRuleVersion int = 2
+
+// preferredAddressComponents are dictionaries of street address, expansions
+// made only with them are ranked first ("st" is "street" rather than "saint")
+const preferredAddressComponents uint16 = gopostalExpand.AddressStreet |
+	gopostalExpand.AddressHouseNumber |
+	gopostalExpand.AddressUnit |
+	gopostalExpand.AddressLevel |
+	gopostalExpand.AddressStaircase |
+	gopostalExpand.AddressEntrance |
+	gopostalExpand.AddressPoBox |
+	gopostalExpand.AddressPostalCode
+
+// CanonicalExpansion is single deterministic form of the address
+type CanonicalExpansion struct {
+	Canonical   string   `json:"canonical"`
+	Hash        string   `json:"hash,omitempty"`
+	RuleVersion int      `json:"rule_version"`
+	Expansions  []string `json:"expansions"`
+}
+
+// normalizeExpansion trims and collapses whitespace
+func normalizeExpansion(expansion string) string {
+	return strings.Join(strings.Fields(expansion), " ")
+}
+
+// preferredExpansions expands the address only with dictionaries of street
+// address components (within components of options), nil means that no
+// expansion is preferred
+func preferredExpansions(ctx context.Context, address string, options gopostalExpand.ExpandOptions) []string {
+	options.AddressComponents &= preferredAddressComponents
+	if options.AddressComponents == gopostalExpand.AddressNone {
+		return nil
+	}
+	return expandAddress(ctx, address, options)
+}
+
+// compareExpansions orders normalized expansions by rest of ranking rule
+// (v2), after preferred expansions:
+//  1. more tokens first: word boundaries are kept ("champs elysees"
+//     is better than "champselysees")
+//  2. shorter form first (in characters)
+//  3. lexicographic byte order, so ties never depend on libpostal output order
+func compareExpansions(a, b string) int {
+	if tokensA, tokensB := len(strings.Fields(a)), len(strings.Fields(b)); tokensA != tokensB {
+		return tokensB - tokensA
+	}
+	if lenA, lenB := utf8.RuneCountInString(a), utf8.RuneCountInString(b); lenA != lenB {
+		return lenA - lenB
+	}
+	return strings.Compare(a, b)
+}
+
+// rankExpansions returns normalized unique expansions, ordered by ranking
+// rule: expansions, which are also in preferred (see preferredExpansions),
+// go first, then they are ordered by compareExpansions
+func rankExpansions(expansions, preferred []string) []string {
+	isPreferred := make(map[string]bool, len(preferred))
+	for _, expansion := range preferred {
+		isPreferred[normalizeExpansion(expansion)] = true
+	}
+	ranked := make([]string, 0, len(expansions))
+	for _, expansion := range expansions {
+		if normalized := normalizeExpansion(expansion); normalized != "" {
+			ranked = append(ranked, normalized)
+		}
+	}
+	slices.SortFunc(ranked, func(a, b string) int {
+		if isPreferred[a] != isPreferred[b] {
+			if isPreferred[a] {
+				return -1
+			}
+			return 1
+		}
+		return compareExpansions(a, b)
+	})
+	return slices.Compact(ranked)
+}
+
+// expandRanked expands the address and orders expansions by ranking rule
+func expandRanked(ctx context.Context, address string, options gopostalExpand.ExpandOptions) []string {
+	return rankExpansions(expandAddress(ctx, address, options), preferredExpansions(ctx, address, options))
+}
+
+// pickCanonical returns first expansion by ranking rule, or empty string.
+// Expansions of parsed component are made with dictionaries of its kind, so
+// none of them is preferred
+func pickCanonical(expansions []string) string {
+	ranked := rankExpansions(expansions, nil)
+	if len(ranked) == 0 {
+		return ""
+	}
+	return ranked[0]
+}
+
+// canonicalHash is SHA-256 of canonical form, prefixed with rule version,
+// so hashes of different rules never match
+func canonicalHash(canonical string) string {
+	sum := sha256.Sum256([]byte("v" + strconv.Itoa(CanonicalRuleVersion) + ":" + canonical))
+	return hex.EncodeToString(sum[:])
+}
+
+// canonicalExpansion selects canonical form from ranked expansions
+func canonicalExpansion(ranked []string, withHash bool) CanonicalExpansion {
+	result := CanonicalExpansion{
+		RuleVersion: CanonicalRuleVersion,
+		Expansions:  ranked,
+	}
+	if len(ranked) > 0 {
+		result.Canonical = ranked[0]
+		if withHash {
+			result.Hash = canonicalHash(result.Canonical)
+		}
+	}
+	return result
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRankExpansions(t *testing.T) {
+	expansions := []string{
+		"92 avenue des champselysees",
+		"92 avenue des champs-elysees",
+		" 92  avenue des champs elysees ",
+		"92 avenue des champs elysees",
+		"",
+	}
+
+	assert.Equal(t, []string{
+		"92 avenue des champs elysees",
+		"92 avenue des champselysees",
+		"92 avenue des champs-elysees",
+	}, rankExpansions(expansions, nil))
+}
+
+func TestRankExpansionsPreferred(t *testing.T) {
+	// "st" is expanded with every dictionary, but only "street" with road dictionaries
+	expansions := []string{"main saint", "main street"}
+	preferred := []string{"main street"}
+
+	assert.Equal(t, []string{"main street", "main saint"}, rankExpansions(expansions, preferred))
+	assert.Equal(t, "main saint", rankExpansions(expansions, nil)[0])
+	// preferred expansion, which is not returned by full expansion, is ignored
+	assert.Equal(t, []string{"main saint"}, rankExpansions([]string{"main saint"}, preferred))
+}
+
+func TestPickCanonical(t *testing.T) {
+	t.Run("Shorter Form Wins With Same Tokens", func(t *testing.T) {
+		assert.Equal(t, "1 saint james", pickCanonical([]string{"1 street james", "1 saint james"}))
+	})
+
+	t.Run("Lexicographic Order Breaks Ties", func(t *testing.T) {
+		assert.Equal(t, "abc", pickCanonical([]string{"abd", "abc"}))
+	})
+
+	t.Run("Independent Of Libpostal Order", func(t *testing.T) {
+		expansions := []string{"781 franklin avenue", "781 franklin av", "781 franklinavenue"}
+		reversed := []string{"781 franklinavenue", "781 franklin av", "781 franklin avenue"}
+		assert.Equal(t, pickCanonical(expansions), pickCanonical(reversed))
+		assert.Equal(t, "781 franklin av", pickCanonical(expansions))
+	})
+
+	t.Run("Empty Expansions", func(t *testing.T) {
+		assert.Equal(t, "", pickCanonical(nil))
+	})
+}
+
+func TestCanonicalHash(t *testing.T) {
+	// hash must stay same across restarts, replicas and releases with same rule version
+	assert.Equal(t, "dc27c9caae8edbab3d0acf5f71a32068ae3689e2835212f1aba29776ff9ad89f", canonicalHash("781 franklin avenue"))
+	assert.NotEqual(t, canonicalHash("781 franklin avenue"), canonicalHash("781 franklin av"))
+}
+
+func TestCanonicalExpansion(t *testing.T) {
+	result := canonicalExpansion(rankExpansions([]string{"b street", "a street"}, nil), true)
+	assert.Equal(t, "a street", result.Canonical)
+	assert.Equal(t, canonicalHash("a street"), result.Hash)
+	assert.Equal(t, CanonicalRuleVersion, result.RuleVersion)
+	assert.Equal(t, []string{"a street", "b street"}, result.Expansions)
+
+	withoutHash := canonicalExpansion([]string{"a street"}, false)
+	assert.Empty(t, withoutHash.Hash)
+
+	empty := canonicalExpansion(nil, true)
+	assert.Empty(t, empty.Canonical)
+	assert.Empty(t, empty.Hash)
+}
+
+func TestExpandRouteCanonical(t *testing.T) {
+	router := SetupRouter()
+
+	w := httptest.NewRecorder()
+	query := "?address=" + url.QueryEscape("781 Franklin Ave") + "&canonical=true&canonical_hash=true"
+	req, _ := http.NewRequest(http.MethodGet, "/expand"+query, nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response CanonicalExpansion
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+
+	assert.Nil(t, err)
+	assert.NotEmpty(t, response.Canonical)
+	assert.Equal(t, canonicalHash(response.Canonical), response.Hash)
+	assert.Contains(t, response.Expansions, response.Canonical)
+}
@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	gopostalExpand "github.com/openvenues/gopostal/expand"
+	gopostalParser "github.com/openvenues/gopostal/parser"
+)
+
+// compareDefaultWeights are weights of labels in overall score. Street
+// with house number and postal code identify the address most
+var compareDefaultWeights = map[string]float64{
+	"house":          1,
+	"category":       0.25,
+	"near":           0.25,
+	"house_number":   3,
+	"road":           3,
+	"unit":           1,
+	"level":          0.5,
+	"staircase":      0.5,
+	"entrance":       0.5,
+	"po_box":         2,
+	"postcode":       2,
+	"suburb":         0.5,
+	"city_district":  0.5,
+	"city":           1,
+	"island":         0.5,
+	"state_district": 0.25,
+	"state":          0.5,
+	"country_region": 0.25,
+	"country":        0.5,
+	"world_region":   0.25,
+}
+
+// compareNumericLabels are labels compared by numeric equality only,
+// "12" and "21" share all tokens, but they are different houses
+var compareNumericLabels = map[string]bool{
+	"house_number": true,
+	"postcode":     true,
+}
+
+// AddressPair is pair of addresses to compare
+type AddressPair struct {
+	Address1 string `json:"address1"`
+	Address2 string `json:"address2"`
+}
+
+// CompareRequest is single pair (address1, address2) or batch of pairs
+type CompareRequest struct {
+	AddressPair
+	Pairs    []AddressPair `json:"pairs"`
+	Language string        `json:"language"`
+	Country  string        `json:"country"`
+	// weights of labels in overall score, they override default weights
+	Weights map[string]float64 `json:"weights"`
+}
+
+// ComponentSimilarity is similarity of one label of both addresses.
+// Labels, which are present only in one address, have zero score
+// and are not used in overall score
+type ComponentSimilarity struct {
+	Label            string  `json:"label"`
+	Value1           string  `json:"value1"`
+	Value2           string  `json:"value2"`
+	ExpansionOverlap float64 `json:"expansion_overlap"`
+	TokenJaccard     float64 `json:"token_jaccard"`
+	NumericEqual     *bool   `json:"numeric_equal,omitempty"`
+	Score            float64 `json:"score"`
+	Weight           float64 `json:"weight"`
+}
+
+// AddressSimilarity is weighted overall score of labels, which are
+// present in both addresses, and per label scores
+type AddressSimilarity struct {
+	Score      float64               `json:"score"`
+	Components []ComponentSimilarity `json:"components"`
+}
+
+// labelExpansions is joined value and all expansions of one label
+type labelExpansions struct {
+	value      string
+	expansions []string
+}
+
+// pairs returns pairs of the request. Single pair and batch are exclusive
+func (request CompareRequest) pairs() ([]AddressPair, error) {
+	single := request.Address1 != "" || request.Address2 != ""
+	switch {
+	case single && len(request.Pairs) > 0:
+		return nil, errors.New("address1 and address2 can not be used with pairs")
+	case single:
+		return []AddressPair{request.AddressPair}, nil
+	case len(request.Pairs) == 0:
+		return nil, errors.New("address1 and address2 or pairs are required")
+	}
+	// every pair is parsed and expanded twice, so large batch does not fit into write timeout
+	if maxPairs := currentConfig().MaxComparePairs; maxPairs > 0 && len(request.Pairs) > maxPairs {
+		return nil, fmt.Errorf("too many pairs, got %d, max is %d", len(request.Pairs), maxPairs)
+	}
+	return request.Pairs, nil
+}
+
+// compareWeights returns default weights with request weights on top of them
+func compareWeights(weights map[string]float64) (map[string]float64, error) {
+	merged := make(map[string]float64, len(compareDefaultWeights))
+	for label, weight := range compareDefaultWeights {
+		merged[label] = weight
+	}
+	for label, weight := range weights {
+		if _, ok := compareDefaultWeights[label]; !ok {
+			return nil, fmt.Errorf("unknown weight label %q, must be one of %s", label, strings.Join(parserLabels, ", "))
+		}
+		if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return nil, fmt.Errorf("weight of %q must be non-negative number, got %v", label, weight)
+		}
+		merged[label] = weight
+	}
+	return merged, nil
+}
+
+// compareAddresses parses and normalizes both addresses of every pair
+// and scores their similarity
+func compareAddresses(
+	ctx context.Context,
+	request CompareRequest,
+	expandOptions gopostalExpand.ExpandOptions,
+	maxExpansions int,
+) ([]AddressSimilarity, error) {
+	pairs, err := request.pairs()
+	if err != nil {
+		return nil, err
+	}
+	weights, err := compareWeights(request.Weights)
+	if err != nil {
+		return nil, err
+	}
+
+	parserOptions := gopostalParser.ParserOptions{
+		Language: request.Language,
+		Country:  request.Country,
+	}
+	similarities := make([]AddressSimilarity, 0, len(pairs))
+	for _, pair := range pairs {
+		similarities = append(similarities, addressSimilarity(
+			normalizeAddress(ctx, pair.Address1, parserOptions, expandOptions, maxExpansions),
+			normalizeAddress(ctx, pair.Address2, parserOptions, expandOptions, maxExpansions),
+			weights,
+		))
+	}
+	return similarities, nil
+}
+
+// expansionsByLabel groups normalized components by label. Values of
+// repeated labels are joined and their expansions are merged
+func expansionsByLabel(normalized NormalizedAddress) map[string]labelExpansions {
+	labels := make(map[string]labelExpansions, len(normalized.Components))
+	for _, component := range normalized.Components {
+		expansions := component.Expansions
+		if len(expansions) == 0 {
+			expansions = []string{component.Canonical}
+		}
+		existing := labels[component.Label]
+		labels[component.Label] = labelExpansions{
+			value:      strings.TrimSpace(existing.value + " " + component.Value),
+			expansions: append(existing.expansions, expansions...),
+		}
+	}
+	return labels
+}
+
+// addressSimilarity scores every label of both addresses in parser labels
+// order and returns weighted average of labels, which are present in both
+func addressSimilarity(normalized1, normalized2 NormalizedAddress, weights map[string]float64) AddressSimilarity {
+	labels1, labels2 := expansionsByLabel(normalized1), expansionsByLabel(normalized2)
+
+	similarity := AddressSimilarity{Components: []ComponentSimilarity{}}
+	var weighted, totalWeight float64
+	for _, label := range parserLabels {
+		component1, ok1 := labels1[label]
+		component2, ok2 := labels2[label]
+		if !ok1 && !ok2 {
+			continue
+		}
+		component := ComponentSimilarity{
+			Label:  label,
+			Value1: component1.value,
+			Value2: component2.value,
+			Weight: weights[label],
+		}
+		if ok1 && ok2 {
+			component.score(component1.expansions, component2.expansions)
+			weighted += component.Score * component.Weight
+			totalWeight += component.Weight
+		}
+		similarity.Components = append(similarity.Components, component)
+	}
+	if totalWeight > 0 {
+		similarity.Score = roundScore(weighted / totalWeight)
+	}
+	return similarity
+}
+
+// score sets similarity measures of both expansion lists. Numeric labels
+// are scored by numeric equality, other labels by better of expansion
+// overlap and token Jaccard
+func (component *ComponentSimilarity) score(expansions1, expansions2 []string) {
+	if slices.ContainsFunc(expansions1, func(expansion string) bool {
+		return slices.Contains(expansions2, expansion)
+	}) {
+		component.ExpansionOverlap = 1
+	}
+	component.TokenJaccard = roundScore(tokenJaccard(expansions1, expansions2))
+
+	if compareNumericLabels[component.Label] {
+		equal := numericEqual(
+			append([]string{component.Value1}, expansions1...),
+			append([]string{component.Value2}, expansions2...),
+		)
+		component.NumericEqual = &equal
+		if equal {
+			component.Score = 1
+		}
+		return
+	}
+	component.Score = max(component.ExpansionOverlap, component.TokenJaccard)
+}
+
+// tokenJaccard returns Jaccard index of tokens of all expansions
+func tokenJaccard(expansions1, expansions2 []string) float64 {
+	tokens1, tokens2 := expansionTokens(expansions1), expansionTokens(expansions2)
+	if len(tokens1) == 0 && len(tokens2) == 0 {
+		return 0
+	}
+	intersection := 0
+	for token := range tokens1 {
+		if tokens2[token] {
+			intersection++
+		}
+	}
+	return float64(intersection) / float64(len(tokens1)+len(tokens2)-intersection)
+}
+
+// expansionTokens returns set of tokens of all expansions
+func expansionTokens(expansions []string) map[string]bool {
+	tokens := make(map[string]bool)
+	for _, expansion := range expansions {
+		for _, token := range strings.Fields(expansion) {
+			tokens[token] = true
+		}
+	}
+	return tokens
+}
+
+// numericEqual reports whether any forms of both values are same without
+// spaces and hyphens ("11216-1234" and "112161234", "12 a" and "12A")
+func numericEqual(values1, values2 []string) bool {
+	for _, value1 := range values1 {
+		compact1 := compactPostcode(value1)
+		if compact1 == "" {
+			continue
+		}
+		for _, value2 := range values2 {
+			if compact1 == compactPostcode(value2) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// roundScore rounds score to 4 decimal places
+func roundScore(score float64) float64 {
+	return math.Round(score*10000) / 10000
+}
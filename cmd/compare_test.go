@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareRequestPairs(t *testing.T) {
+	pairs, err := CompareRequest{AddressPair: AddressPair{Address1: "a", Address2: "b"}}.pairs()
+	assert.NoError(t, err)
+	assert.Equal(t, []AddressPair{{Address1: "a", Address2: "b"}}, pairs)
+
+	_, err = CompareRequest{}.pairs()
+	assert.ErrorContains(t, err, "are required")
+
+	_, err = CompareRequest{
+		AddressPair: AddressPair{Address1: "a"},
+		Pairs:       []AddressPair{{Address1: "a", Address2: "b"}},
+	}.pairs()
+	assert.ErrorContains(t, err, "can not be used with pairs")
+
+	useConfig(t, &Config{MaxComparePairs: 2})
+	_, err = CompareRequest{Pairs: make([]AddressPair, 3)}.pairs()
+	assert.ErrorContains(t, err, "too many pairs, got 3, max is 2")
+
+	useConfig(t, &Config{})
+	_, err = CompareRequest{Pairs: make([]AddressPair, 3)}.pairs()
+	assert.NoError(t, err)
+}
+
+func TestCompareWeights(t *testing.T) {
+	weights, err := compareWeights(map[string]float64{"road": 5, "country": 0})
+	assert.NoError(t, err)
+	assert.Equal(t, 5.0, weights["road"])
+	assert.Equal(t, 0.0, weights["country"])
+	assert.Equal(t, compareDefaultWeights["house_number"], weights["house_number"])
+
+	_, err = compareWeights(map[string]float64{"street": 1})
+	assert.ErrorContains(t, err, `unknown weight label "street"`)
+
+	_, err = compareWeights(map[string]float64{"road": -1})
+	assert.ErrorContains(t, err, "must be non-negative")
+}
+
+func TestAddressSimilarity(t *testing.T) {
+	address1 := NormalizedAddress{Components: []NormalizedComponent{
+		{Label: "house_number", Value: "781", Canonical: "781", Expansions: []string{"781"}},
+		{Label: "road", Value: "franklin ave", Canonical: "franklin avenue", Expansions: []string{"franklin avenue"}},
+		{Label: "postcode", Value: "11216", Canonical: "11216", Expansions: []string{"11216"}},
+	}}
+	address2 := NormalizedAddress{Components: []NormalizedComponent{
+		{Label: "house_number", Value: "781", Canonical: "781", Expansions: []string{"781"}},
+		{Label: "road", Value: "franklin avenue", Canonical: "franklin avenue", Expansions: []string{"franklin avenue"}},
+		{Label: "city", Value: "brooklyn", Canonical: "brooklyn", Expansions: []string{"brooklyn"}},
+	}}
+
+	t.Run("Same Address", func(t *testing.T) {
+		similarity := addressSimilarity(address1, address2, compareDefaultWeights)
+		assert.Equal(t, 1.0, similarity.Score)
+		if assert.Len(t, similarity.Components, 4) {
+			assert.Equal(t, "house_number", similarity.Components[0].Label)
+			assert.True(t, *similarity.Components[0].NumericEqual)
+			assert.Equal(t, 1.0, similarity.Components[1].ExpansionOverlap)
+			// labels of one address are listed, but not scored
+			assert.Equal(t, "postcode", similarity.Components[2].Label)
+			assert.Equal(t, "", similarity.Components[2].Value2)
+			assert.Nil(t, similarity.Components[2].NumericEqual)
+			assert.Equal(t, 0.0, similarity.Components[2].Score)
+		}
+	})
+
+	t.Run("Different House Number", func(t *testing.T) {
+		other := NormalizedAddress{Components: []NormalizedComponent{
+			{Label: "house_number", Value: "187", Canonical: "187", Expansions: []string{"187"}},
+			{Label: "road", Value: "franklin st", Canonical: "franklin street", Expansions: []string{"franklin street", "franklin saint"}},
+		}}
+		similarity := addressSimilarity(address1, other, compareDefaultWeights)
+		if assert.Len(t, similarity.Components, 3) {
+			assert.False(t, *similarity.Components[0].NumericEqual)
+			assert.Equal(t, 0.0, similarity.Components[0].Score)
+			assert.Equal(t, 0.0, similarity.Components[1].ExpansionOverlap)
+			assert.Equal(t, 0.25, similarity.Components[1].TokenJaccard)
+			assert.Equal(t, 0.25, similarity.Components[1].Score)
+		}
+		// (3 * 0 + 3 * 0.25) / 6
+		assert.Equal(t, 0.125, similarity.Score)
+	})
+
+	t.Run("Custom Weights", func(t *testing.T) {
+		other := NormalizedAddress{Components: []NormalizedComponent{
+			{Label: "house_number", Value: "187", Canonical: "187", Expansions: []string{"187"}},
+			{Label: "road", Value: "franklin ave", Canonical: "franklin avenue", Expansions: []string{"franklin avenue"}},
+		}}
+		weights, _ := compareWeights(map[string]float64{"house_number": 0})
+		assert.Equal(t, 1.0, addressSimilarity(address1, other, weights).Score)
+	})
+
+	t.Run("Nothing In Common", func(t *testing.T) {
+		similarity := addressSimilarity(address1, NormalizedAddress{}, compareDefaultWeights)
+		assert.Equal(t, 0.0, similarity.Score)
+		assert.Len(t, similarity.Components, 3)
+	})
+}
+
+func TestNumericEqual(t *testing.T) {
+	assert.True(t, numericEqual([]string{"11216-1234"}, []string{"112161234"}))
+	assert.True(t, numericEqual([]string{"12 a"}, []string{"12A"}))
+	assert.False(t, numericEqual([]string{"12"}, []string{"21"}))
+	assert.False(t, numericEqual([]string{""}, []string{""}))
+}
+
+func TestCompareRoute(t *testing.T) {
+	router := SetupRouter()
+
+	t.Run("Compare Pair", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		body := `{"address1": "781 Franklin Ave Brooklyn NY 11216", "address2": "781 franklin avenue, brooklyn, new york 11216", "language": "en"}`
+		req, _ := http.NewRequest(http.MethodPost, "/compare", strings.NewReader(body))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response AddressSimilarity
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1.0, response.Score)
+	})
+
+	t.Run("Compare Batch", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		body := `{"pairs": [{"address1": "781 Franklin Ave", "address2": "781 Franklin Ave"}, {"address1": "781 Franklin Ave", "address2": "10 Downing St"}]}`
+		req, _ := http.NewRequest(http.MethodPost, "/compare", strings.NewReader(body))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response []AddressSimilarity
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+
+		assert.Nil(t, err)
+		if assert.Len(t, response, 2) {
+			assert.Equal(t, 1.0, response[0].Score)
+			assert.Less(t, response[1].Score, response[0].Score)
+		}
+	})
+
+	t.Run("Invalid Weights", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		body := `{"address1": "781 Franklin Ave", "address2": "781 Franklin Ave", "weights": {"street": 1}}`
+		req, _ := http.NewRequest(http.MethodPost, "/compare", strings.NewReader(body))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Missing Addresses", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/compare", strings.NewReader(`{}`))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+// content codings of responses and request bodies
+const (
+	EncodingGzip     string = "gzip"
+	EncodingBrotli   string = "br"
+	EncodingZstd     string = "zstd"
+	EncodingIdentity string = "identity"
+)
+
+// compression levels (compression_level setting), same level name is mapped
+// on level of every algorithm
+const (
+	CompressionLevelNone    string = "none"
+	CompressionLevelFastest string = "fastest"
+	CompressionLevelDefault string = "default"
+	CompressionLevelBest    string = "best"
+)
+
+// responseEncodings are offered in order of preference, if Accept-Encoding
+// has same quality for several of them
+var responseEncodings = []string{EncodingZstd, EncodingBrotli, EncodingGzip}
+
+// compressionEncoder is pooled compressor of response body
+type compressionEncoder interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+}
+
+// compressionEncoders are pools of encoders per content coding and level
+var compressionEncoders sync.Map
+
+// newCompressionEncoder returns encoder of content coding with level
+func newCompressionEncoder(encoding, level string) compressionEncoder {
+	switch encoding {
+	case EncodingGzip:
+		gzipLevel := gzip.DefaultCompression
+		switch level {
+		case CompressionLevelFastest:
+			gzipLevel = gzip.BestSpeed
+		case CompressionLevelBest:
+			gzipLevel = gzip.BestCompression
+		}
+		encoder, _ := gzip.NewWriterLevel(io.Discard, gzipLevel)
+		return encoder
+	case EncodingBrotli:
+		// brotli default level (6) is too slow for dynamic responses
+		brotliLevel := 4
+		switch level {
+		case CompressionLevelFastest:
+			brotliLevel = brotli.BestSpeed
+		case CompressionLevelBest:
+			brotliLevel = brotli.BestCompression
+		}
+		return brotli.NewWriterLevel(io.Discard, brotliLevel)
+	case EncodingZstd:
+		zstdLevel := zstd.SpeedDefault
+		switch level {
+		case CompressionLevelFastest:
+			zstdLevel = zstd.SpeedFastest
+		case CompressionLevelBest:
+			zstdLevel = zstd.SpeedBestCompression
+		}
+		encoder, _ := zstd.NewWriter(io.Discard, zstd.WithEncoderLevel(zstdLevel), zstd.WithEncoderConcurrency(1))
+		return encoder
+	}
+	return nil
+}
+
+// acquireCompressionEncoder returns pooled encoder, which writes into w
+func acquireCompressionEncoder(encoding, level string, w io.Writer) (compressionEncoder, func()) {
+	pool, _ := compressionEncoders.LoadOrStore(encoding+":"+level, &sync.Pool{
+		New: func() any { return newCompressionEncoder(encoding, level) },
+	})
+	encoder := pool.(*sync.Pool).Get().(compressionEncoder)
+	encoder.Reset(w)
+	return encoder, func() {
+		encoder.Reset(io.Discard)
+		pool.(*sync.Pool).Put(encoder)
+	}
+}
+
+// negotiateEncoding returns content coding of response by Accept-Encoding
+// header, empty string means response is not compressed
+func negotiateEncoding(acceptEncoding string) string {
+	qualities := make(map[string]float64)
+	for part := range strings.SplitSeq(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				quality = parsed
+			}
+		}
+		qualities[name] = quality
+	}
+
+	best, bestQuality := "", 0.0
+	for _, encoding := range responseEncodings {
+		quality, ok := qualities[encoding]
+		if !ok {
+			quality, ok = qualities["*"]
+		}
+		if ok && quality > bestQuality {
+			best, bestQuality = encoding, quality
+		}
+	}
+	return best
+}
+
+// compressResponseWriter buffers response until it has min size, then
+// compresses the rest of it. Smaller responses are written as is
+type compressResponseWriter struct {
+	gin.ResponseWriter
+	encoding string
+	level    string
+	minSize  int
+	buffer   bytes.Buffer
+	encoder  compressionEncoder
+	release  func()
+	// response is written without compression
+	passthrough bool
+}
+
+func (w *compressResponseWriter) Write(data []byte) (int, error) {
+	if w.passthrough {
+		return w.ResponseWriter.Write(data)
+	}
+	if w.encoder != nil {
+		return w.encoder.Write(data)
+	}
+	w.buffer.Write(data)
+	if w.buffer.Len() < w.minSize {
+		return len(data), nil
+	}
+	if err := w.start(); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+func (w *compressResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// start writes headers and buffered body with or without compression
+func (w *compressResponseWriter) start() error {
+	header := w.Header()
+	status := w.Status()
+	// already encoded body, partial content and responses without body
+	// are not compressed
+	if header.Get("Content-Encoding") != "" || status == http.StatusPartialContent || status < http.StatusOK ||
+		status == http.StatusNoContent || status == http.StatusNotModified {
+		w.passthrough = true
+	} else {
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		// ranges of compressed body are not supported
+		header.Del("Accept-Ranges")
+		// strong ETag is different for every content coding
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", encodedETag(etag, w.encoding))
+		}
+		w.encoder, w.release = acquireCompressionEncoder(w.encoding, w.level, w.ResponseWriter)
+	}
+
+	data := w.buffer.Bytes()
+	w.buffer = bytes.Buffer{}
+	if w.passthrough {
+		_, err := w.ResponseWriter.Write(data)
+		return err
+	}
+	_, err := w.encoder.Write(data)
+	return err
+}
+
+// Flush writes compressed data of streamed response
+func (w *compressResponseWriter) Flush() {
+	if !w.passthrough && w.encoder == nil && w.buffer.Len() > 0 {
+		w.start()
+	}
+	if flusher, ok := w.encoder.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// Unwrap allows http.ResponseController to reach connection of the response
+func (w *compressResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// finish writes small response as is or closes compressed stream
+func (w *compressResponseWriter) finish() error {
+	if w.encoder != nil {
+		err := w.encoder.Close()
+		w.release()
+		return err
+	}
+	if w.passthrough || w.buffer.Len() == 0 {
+		return nil
+	}
+	w.passthrough = true
+	_, err := w.ResponseWriter.Write(w.buffer.Bytes())
+	return err
+}
+
+// decompressRequestBody replaces body with Content-Encoding (e.g. gzip
+// compressed CSV upload) with decoded one. Reading of decoded body fails with
+// *http.MaxBytesError after maxSize bytes, so small compressed body can not
+// fill memory or disk
+func decompressRequestBody(w http.ResponseWriter, r *http.Request, maxSize int64) error {
+	contentEncoding := r.Header.Get("Content-Encoding")
+	if contentEncoding == "" || r.Body == nil {
+		return nil
+	}
+	encodings := strings.Split(contentEncoding, ",")
+	body := r.Body
+	closers := []io.Closer{r.Body}
+	// codings are listed in order they were applied
+	for _, encoding := range slices.Backward(encodings) {
+		switch strings.ToLower(strings.TrimSpace(encoding)) {
+		case EncodingGzip, "x-gzip":
+			reader, err := gzip.NewReader(body)
+			if err != nil {
+				return fmt.Errorf("invalid gzip body: %w", err)
+			}
+			body, closers = reader, append(closers, reader)
+		case EncodingBrotli:
+			body = io.NopCloser(brotli.NewReader(body))
+		case EncodingZstd:
+			reader, err := zstd.NewReader(body, zstd.WithDecoderConcurrency(1))
+			if err != nil {
+				return fmt.Errorf("invalid zstd body: %w", err)
+			}
+			decoded := reader.IOReadCloser()
+			body, closers = decoded, append(closers, decoded)
+		case EncodingIdentity:
+		default:
+			return errUnsupportedContentEncoding
+		}
+	}
+
+	r.Body = http.MaxBytesReader(w, &decodedBody{Reader: body, closers: closers}, maxSize)
+	r.Header.Del("Content-Encoding")
+	r.Header.Del("Content-Length")
+	r.ContentLength = -1
+	return nil
+}
+
+var errUnsupportedContentEncoding = errors.New("unsupported content encoding, must be one of gzip, br, zstd")
+
+// abortWithBodyError stops the request with 413, if decompressed body is
+// larger than max_decompressed_body_mb, or with 400 for other errors
+func abortWithBodyError(c *gin.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		abortWithError(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("decompressed request body is larger than %d bytes", tooLarge.Limit))
+		return
+	}
+	abortWithError(c, http.StatusBadRequest, err.Error())
+}
+
+// decodedBody closes decoders and original body of the request
+type decodedBody struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (b *decodedBody) Close() error {
+	var errs []error
+	for _, closer := range slices.Backward(b.closers) {
+		errs = append(errs, closer.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// CompressionMiddleware compresses responses with gzip, br or zstd (by
+// Accept-Encoding header), if they have at least compression_min_size bytes.
+// Request bodies with Content-Encoding are decompressed (up to
+// max_decompressed_body_mb)
+func CompressionMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := currentConfig()
+		if err := decompressRequestBody(c.Writer, c.Request, int64(cfg.MaxDecompressedBodyMB)<<20); err != nil {
+			if errors.Is(err, errUnsupportedContentEncoding) {
+				abortWithError(c, http.StatusUnsupportedMediaType, err.Error())
+				return
+			}
+			abortWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		level := strings.ToLower(cfg.CompressionLevel)
+		if level == CompressionLevelNone || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		writer := &compressResponseWriter{
+			ResponseWriter: c.Writer,
+			encoding:       encoding,
+			level:          level,
+			minSize:        cfg.CompressionMinSize,
+		}
+		c.Writer = writer
+		defer func() {
+			if err := writer.finish(); err != nil {
+				requestLogger(c).Warn().Err(err).Str("encoding", encoding).Msg("Unable to write compressed response")
+			}
+			c.Writer = writer.ResponseWriter
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		acceptEncoding string
+		expected       string
+	}{
+		{acceptEncoding: "", expected: ""},
+		{acceptEncoding: "gzip", expected: EncodingGzip},
+		{acceptEncoding: "gzip, deflate, br", expected: EncodingBrotli},
+		{acceptEncoding: "gzip, br, zstd", expected: EncodingZstd},
+		{acceptEncoding: "zstd;q=0.5, GZIP", expected: EncodingGzip},
+		{acceptEncoding: "*", expected: EncodingZstd},
+		{acceptEncoding: "br;q=0, *;q=0.1", expected: EncodingZstd},
+		{acceptEncoding: "gzip;q=0", expected: ""},
+		{acceptEncoding: "deflate, identity", expected: ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, negotiateEncoding(tt.acceptEncoding), tt.acceptEncoding)
+	}
+}
+
+func TestCompressionMiddleware(t *testing.T) {
+	body := strings.Repeat("781 franklin avenue crown heights brooklyn ny 11216\n", 50)
+	router := gin.New()
+	router.Use(CompressionMiddleware())
+	router.GET("/large", func(c *gin.Context) {
+		c.String(http.StatusOK, body)
+	})
+	router.GET("/small", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	router.POST("/echo", func(c *gin.Context) {
+		data, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			abortWithBodyError(c, err)
+			return
+		}
+		c.Data(http.StatusOK, "text/plain", data)
+	})
+
+	get := func(target, acceptEncoding string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	decoders := map[string]func(r io.Reader) (io.Reader, error){
+		EncodingGzip: func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		EncodingBrotli: func(r io.Reader) (io.Reader, error) {
+			return brotli.NewReader(r), nil
+		},
+		EncodingZstd: func(r io.Reader) (io.Reader, error) { return zstd.NewReader(r) },
+	}
+
+	for _, level := range []string{CompressionLevelFastest, CompressionLevelDefault, CompressionLevelBest} {
+		for encoding, decode := range decoders {
+			t.Run(level+" "+encoding, func(t *testing.T) {
+				useConfig(t, &Config{CompressionLevel: level, CompressionMinSize: 1024})
+
+				w := get("/large", encoding)
+				assert.Equal(t, http.StatusOK, w.Code)
+				assert.Equal(t, encoding, w.Header().Get("Content-Encoding"))
+				assert.Equal(t, []string{"Accept-Encoding"}, w.Header().Values("Vary"))
+				assert.Less(t, w.Body.Len(), len(body))
+
+				reader, err := decode(w.Body)
+				assert.NoError(t, err)
+				decoded, err := io.ReadAll(reader)
+				assert.NoError(t, err)
+				assert.Equal(t, body, string(decoded))
+			})
+		}
+	}
+
+	t.Run("Smaller Than Min Size", func(t *testing.T) {
+		useConfig(t, &Config{CompressionLevel: CompressionLevelDefault, CompressionMinSize: 1024})
+
+		w := get("/small", "gzip")
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+		assert.Equal(t, "ok", w.Body.String())
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		useConfig(t, &Config{CompressionLevel: CompressionLevelNone})
+
+		w := get("/large", "gzip")
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Empty(t, w.Header().Get("Vary"))
+		assert.Equal(t, body, w.Body.String())
+	})
+
+	t.Run("Compressed Request Body", func(t *testing.T) {
+		useConfig(t, &Config{CompressionLevel: CompressionLevelNone, MaxDecompressedBodyMB: 1})
+
+		var compressed bytes.Buffer
+		encoder, _ := zstd.NewWriter(&compressed)
+		encoder.Write([]byte(body))
+		encoder.Close()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/echo", &compressed)
+		req.Header.Set("Content-Encoding", "zstd")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, body, w.Body.String())
+	})
+
+	t.Run("Decompressed Body Too Large", func(t *testing.T) {
+		useConfig(t, &Config{CompressionLevel: CompressionLevelNone, MaxDecompressedBodyMB: 1})
+
+		// 2 MB of zeros are compressed into few kilobytes
+		var compressed bytes.Buffer
+		encoder := gzip.NewWriter(&compressed)
+		encoder.Write(make([]byte, 2<<20))
+		encoder.Close()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/echo", &compressed)
+		req.Header.Set("Content-Encoding", "gzip")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Contains(t, w.Body.String(), "decompressed request body is larger than 1048576 bytes")
+	})
+
+	t.Run("Invalid Request Body", func(t *testing.T) {
+		useConfig(t, &Config{CompressionLevel: CompressionLevelNone, MaxDecompressedBodyMB: 1})
+
+		for encoding, status := range map[string]int{EncodingGzip: http.StatusBadRequest, "compress": http.StatusUnsupportedMediaType} {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/echo", strings.NewReader(body))
+			req.Header.Set("Content-Encoding", encoding)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, status, w.Code, encoding)
+		}
+	})
+}
+
+func TestCompressedJobUpload(t *testing.T) {
+	manager := useTestJobManager(t)
+	router := SetupRouter()
+
+	var compressed bytes.Buffer
+	encoder := gzip.NewWriter(&compressed)
+	encoder.Write([]byte("id,address\n1,10 Downing St\n2,781 Franklin Ave\n"))
+	encoder.Close()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/jobs?operation=expand", &compressed)
+	req.Header.Set("Content-Type", "text/csv")
+	req.Header.Set("Content-Encoding", "gzip")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	id := strings.TrimPrefix(w.Header().Get("Location"), "/jobs/")
+	job := waitForFinishedJob(t, manager, id)
+	assert.Equal(t, JobStatusDone, job.Status)
+	assert.Equal(t, 2, job.Progress.Rows)
+}
+
+func TestCompressedJobUploadTooLarge(t *testing.T) {
+	useTestJobManager(t)
+	router := SetupRouter()
+	useConfig(t, &Config{CompressionLevel: CompressionLevelNone, MaxDecompressedBodyMB: 1})
+
+	var compressed bytes.Buffer
+	encoder := gzip.NewWriter(&compressed)
+	encoder.Write([]byte("id,address\n"))
+	encoder.Write([]byte(strings.Repeat("1,10 Downing St\n", 1<<17)))
+	encoder.Close()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/jobs?operation=expand", &compressed)
+	req.Header.Set("Content-Type", "text/csv")
+	req.Header.Set("Content-Encoding", "gzip")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
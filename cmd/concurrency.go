@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// libpostalSlots limit libpostal work in flight (max_concurrency setting).
+// Interactive requests and every record of batch jobs share same slots,
+// so batch jobs can not starve interactive traffic
+var libpostalSlots struct {
+	mu    sync.Mutex
+	size  int
+	slots chan struct{}
+}
+
+// acquireLibpostal waits for free slot and returns function, which releases
+// it. Slots are recreated, if max_concurrency is changed on reload
+func acquireLibpostal(ctx context.Context) (func(), error) {
+	size := currentConfig().MaxConcurrency
+	if size <= 0 {
+		return func() {}, nil
+	}
+
+	libpostalSlots.mu.Lock()
+	if libpostalSlots.size != size {
+		libpostalSlots.size = size
+		libpostalSlots.slots = make(chan struct{}, size)
+	}
+	slots := libpostalSlots.slots
+	libpostalSlots.mu.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// ConcurrencyLimitMiddleware holds libpostal slot while request is processed.
+// Request, which is canceled while waiting for slot, gets 503
+func ConcurrencyLimitMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		release, err := acquireLibpostal(c.Request.Context())
+		if err != nil {
+			abortWithError(c, http.StatusServiceUnavailable, "server is busy")
+			return
+		}
+		defer release()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcquireLibpostal(t *testing.T) {
+	t.Run("No Limit", func(t *testing.T) {
+		useConfig(t, &Config{})
+
+		for range 3 {
+			_, err := acquireLibpostal(context.Background())
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		useConfig(t, &Config{MaxConcurrency: 1})
+
+		release, err := acquireLibpostal(context.Background())
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = acquireLibpostal(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		release()
+		release, err = acquireLibpostal(context.Background())
+		assert.NoError(t, err)
+		release()
+	})
+}
+
+func TestConcurrencyLimitMiddleware(t *testing.T) {
+	useConfig(t, &Config{MaxConcurrency: 1})
+
+	router := gin.New()
+	router.GET("/", ConcurrencyLimitMiddleware(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	t.Run("Free Slot", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Busy", func(t *testing.T) {
+		release, err := acquireLibpostal(context.Background())
+		assert.NoError(t, err)
+		defer release()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Contains(t, w.Body.String(), "server is busy")
+	})
+}
@@ -0,0 +1,442 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// RedactedValue replaces secret values in any config output
+const RedactedValue string = "[REDACTED]"
+
+// Config is the typed view of the settings merged by viper from flags,
+// environment variables, the config file and defaults.
+//
+// Every field must have a matching persistent flag on the root command:
+// descriptions and defaults for `config print` and `config schema` are
+// taken from it. Fields tagged `secret:"true"` are never printed.
+type Config struct {
+	Host                 string   `mapstructure:"host"`
+	Port                 int      `mapstructure:"port"`
+	TrustedProxies       []string `mapstructure:"trusted_proxies"`
+	H2C                  bool     `mapstructure:"h2c"`
+	Debug                bool     `mapstructure:"debug"`
+	LogFormat            string   `mapstructure:"log_format" enum:"text,json"`
+	LogLevel             string   `mapstructure:"log_level" enum:"trace,debug,info,warn,error,fatal,panic,disabled"`
+	LogAddressPolicy     string   `mapstructure:"log_address_policy" enum:"auto,full,hashed,redacted,length_only"`
+	LogAddressHashKey    string   `mapstructure:"log_address_hash_key" secret:"true"`
+	MaxExpansions        int      `mapstructure:"max_expansions"`
+	MaxExtractTextLength int      `mapstructure:"max_extract_text_length"`
+	MaxComparePairs      int      `mapstructure:"max_compare_pairs"`
+	MaxConcurrency       int      `mapstructure:"max_concurrency"`
+	CompressionLevel     string   `mapstructure:"compression_level" enum:"none,fastest,default,best"`
+	CompressionMinSize   int      `mapstructure:"compression_min_size"`
+	// limit of request body with Content-Encoding after decompression
+	MaxDecompressedBodyMB       int     `mapstructure:"max_decompressed_body_mb"`
+	CacheMaxAge                 int     `mapstructure:"cache_max_age_seconds"`
+	LibpostalDataDir            string  `mapstructure:"libpostal_data_dir"`
+	JobsDir                     string  `mapstructure:"jobs_dir"`
+	JobsWorkers                 int     `mapstructure:"jobs_workers"`
+	JobsRetentionHours          int     `mapstructure:"jobs_retention_hours"`
+	WebhookSecret               string  `mapstructure:"webhook_secret" secret:"true"`
+	WebhookMaxAttempts          int     `mapstructure:"webhook_max_attempts"`
+	WebhookTimeout              int     `mapstructure:"webhook_timeout_seconds"`
+	WebhookAllowPrivateNetworks bool    `mapstructure:"webhook_allow_private_networks"`
+	TracingExporter             string  `mapstructure:"tracing_exporter" enum:"none,otlp,stdout,file"`
+	TracingEndpoint             string  `mapstructure:"tracing_endpoint"`
+	TracingFile                 string  `mapstructure:"tracing_file"`
+	TracingSampleRatio          float64 `mapstructure:"tracing_sample_ratio"`
+	TracingServiceName          string  `mapstructure:"tracing_service_name"`
+	BasicAuthUsername           string  `mapstructure:"basic_auth_username"`
+	BasicAuthPassword           string  `mapstructure:"basic_auth_password" secret:"true"`
+	BearerAuthToken             string  `mapstructure:"bearer_auth_token" secret:"true"`
+	// each secret can be read from a mounted file (docker or k8s secrets)
+	// instead of the plain value: setting has same name with "_file" suffix
+	BasicAuthPasswordFile string `mapstructure:"basic_auth_password_file"`
+	BearerAuthTokenFile   string `mapstructure:"bearer_auth_token_file"`
+	LogAddressHashKeyFile string `mapstructure:"log_address_hash_key_file"`
+	WebhookSecretFile     string `mapstructure:"webhook_secret_file"`
+}
+
+// SecretFileSuffix is appended to secret setting name to get path of
+// the file with its value
+const SecretFileSuffix string = "_file"
+
+// ConfigError holds every problem found while validating the config
+type ConfigError struct {
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+	return "invalid configuration: " + strings.Join(e.Problems, "; ")
+}
+
+// configField describes a single setting of the Config struct
+type configField struct {
+	Key    string
+	Index  int
+	Kind   reflect.Kind
+	Secret bool
+	Enum   []string
+}
+
+// env variables, which can be used for setting in addition to the prefixed one
+var configEnvAliases = map[string][]string{
+	"port": {"PORT"},
+}
+
+func configFields() []configField {
+	t := reflect.TypeFor[Config]()
+	fields := make([]configField, 0, t.NumField())
+	for i := range t.NumField() {
+		f := t.Field(i)
+		field := configField{
+			Key:    f.Tag.Get("mapstructure"),
+			Index:  i,
+			Kind:   f.Type.Kind(),
+			Secret: f.Tag.Get("secret") == "true",
+		}
+		if enum := f.Tag.Get("enum"); enum != "" {
+			field.Enum = strings.Split(enum, ",")
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func decodeConfig(v *viper.Viper) (*Config, error) {
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("unable to decode configuration: %w", err)
+	}
+	return &cfg, nil
+}
+
+// LoadConfig decodes the merged viper settings, reads secret files
+// and validates the result
+func LoadConfig(v *viper.Viper) (*Config, error) {
+	cfg, err := decodeConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.readSecretFiles(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// readSecretFiles replaces secrets with content of their "_file" settings.
+// Errors never contain the secret itself, only the setting name and path
+func (cfg *Config) readSecretFiles() error {
+	value := reflect.ValueOf(cfg).Elem()
+	indexes := make(map[string]int)
+	for _, field := range configFields() {
+		indexes[field.Key] = field.Index
+	}
+
+	var problems []string
+	for _, field := range configFields() {
+		if !field.Secret {
+			continue
+		}
+		fileKey := field.Key + SecretFileSuffix
+		fileIndex, ok := indexes[fileKey]
+		if !ok {
+			continue
+		}
+		path := value.Field(fileIndex).String()
+		if path == "" {
+			continue
+		}
+		if !value.Field(field.Index).IsZero() {
+			problems = append(problems, fmt.Sprintf("%s and %s must not be set together", field.Key, fileKey))
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: unable to read secret file %q", fileKey, path))
+			continue
+		}
+		// editors and `echo` usually leave a trailing newline
+		value.Field(field.Index).SetString(strings.TrimRight(string(content), "\r\n"))
+	}
+
+	if len(problems) > 0 {
+		return &ConfigError{Problems: problems}
+	}
+	return nil
+}
+
+// MarshalZerologObject logs config with redacted secrets, so it is safe
+// to pass the whole config into logger
+func (cfg *Config) MarshalZerologObject(e *zerolog.Event) {
+	for _, field := range configFields() {
+		e.Interface(field.Key, configFieldValue(cfg, field))
+	}
+}
+
+// activeConfig is config, loaded on startup and replaced on every reload
+var activeConfig atomic.Pointer[Config]
+
+// currentConfig returns config from the last (re)load. If server was not
+// started yet (router created directly), settings are decoded from viper
+func currentConfig() *Config {
+	if cfg := activeConfig.Load(); cfg != nil {
+		return cfg
+	}
+	cfg, err := decodeConfig(viper.GetViper())
+	if err != nil {
+		return &Config{}
+	}
+	return cfg
+}
+
+// reloadConfig re-reads config file and secret files. On any problem
+// current config stays in use
+func reloadConfig() {
+	if err := viper.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) {
+			log.Error().Err(err).Msg("Unable to read config file, keeping current configuration")
+			return
+		}
+	}
+
+	cfg, err := LoadConfig(viper.GetViper())
+	if err != nil {
+		log.Error().Err(err).Msg("Invalid configuration, keeping current configuration")
+		return
+	}
+	activeConfig.Store(cfg)
+	log.Info().Msg("Configuration reloaded")
+	log.Debug().Object("config", cfg).Msg("Effective configuration")
+}
+
+// Validate returns *ConfigError with all found problems, or nil
+func (cfg *Config) Validate() error {
+	var problems []string
+	addProblem := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if cfg.Port < 1 || cfg.Port > 65535 {
+		addProblem("port: must be between 1 and 65535, got %d", cfg.Port)
+	}
+
+	if cfg.MaxExpansions < 0 {
+		addProblem("max_expansions: must not be negative, got %d", cfg.MaxExpansions)
+	}
+
+	if cfg.MaxExtractTextLength < 0 {
+		addProblem("max_extract_text_length: must not be negative, got %d", cfg.MaxExtractTextLength)
+	}
+
+	if cfg.MaxComparePairs < 0 {
+		addProblem("max_compare_pairs: must not be negative, got %d", cfg.MaxComparePairs)
+	}
+
+	if cfg.MaxConcurrency < 0 {
+		addProblem("max_concurrency: must not be negative, got %d", cfg.MaxConcurrency)
+	}
+	if cfg.CompressionMinSize < 0 {
+		addProblem("compression_min_size: must not be negative, got %d", cfg.CompressionMinSize)
+	}
+	if cfg.MaxDecompressedBodyMB < 1 {
+		addProblem("max_decompressed_body_mb: must be at least 1, got %d", cfg.MaxDecompressedBodyMB)
+	}
+	if cfg.CacheMaxAge < 0 {
+		addProblem("cache_max_age_seconds: must not be negative, got %d", cfg.CacheMaxAge)
+	}
+	if cfg.JobsWorkers < 1 {
+		addProblem("jobs_workers: must be at least 1, got %d", cfg.JobsWorkers)
+	}
+	if cfg.JobsRetentionHours < 0 {
+		addProblem("jobs_retention_hours: must not be negative, got %d", cfg.JobsRetentionHours)
+	}
+	if cfg.WebhookMaxAttempts < 1 {
+		addProblem("webhook_max_attempts: must be at least 1, got %d", cfg.WebhookMaxAttempts)
+	}
+	if cfg.WebhookTimeout < 1 {
+		addProblem("webhook_timeout_seconds: must be at least 1, got %d", cfg.WebhookTimeout)
+	}
+
+	for _, proxy := range cfg.TrustedProxies {
+		if net.ParseIP(proxy) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(proxy); err != nil {
+			addProblem("trusted_proxies: %q is not a valid IP address or CIDR range", proxy)
+		}
+	}
+
+	for _, field := range configFields() {
+		if len(field.Enum) == 0 {
+			continue
+		}
+		value := strings.ToLower(reflect.ValueOf(cfg).Elem().Field(field.Index).String())
+		if !slices.Contains(field.Enum, value) {
+			addProblem("%s: must be one of %s, got %q", field.Key, strings.Join(field.Enum, ", "), value)
+		}
+	}
+
+	if (cfg.BasicAuthUsername == "") != (cfg.BasicAuthPassword == "") {
+		addProblem("basic_auth_username and basic_auth_password must be set together")
+	}
+	if strings.ContainsAny(cfg.BearerAuthToken, " \t\r\n") {
+		addProblem("bearer_auth_token: must not contain whitespace")
+	}
+	if strings.ToLower(cfg.TracingExporter) == TracingExporterFile && cfg.TracingFile == "" {
+		addProblem("tracing_file is required when tracing_exporter is file")
+	}
+	if cfg.TracingEndpoint != "" {
+		if endpoint, err := url.Parse(cfg.TracingEndpoint); err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+			addProblem("tracing_endpoint: %q is not a valid URL", cfg.TracingEndpoint)
+		}
+	}
+	if cfg.TracingSampleRatio < 0 || cfg.TracingSampleRatio > 1 {
+		addProblem("tracing_sample_ratio: must be between 0 and 1, got %v", cfg.TracingSampleRatio)
+	}
+	if strings.ToLower(cfg.LogAddressPolicy) == AddressLogPolicyHashed && cfg.LogAddressHashKey == "" {
+		addProblem("log_address_hash_key is required when log_address_policy is hashed")
+	}
+
+	if len(problems) > 0 {
+		return &ConfigError{Problems: problems}
+	}
+	return nil
+}
+
+// configValueSource reports where viper took the value for key from,
+// following viper precedence: flag, env, config file, default. Secrets
+// which are read from file report source of the "_file" setting
+func configValueSource(v *viper.Viper, flags *pflag.FlagSet, key string) string {
+	fileKey := key + SecretFileSuffix
+	isFileKey := func(field configField) bool { return field.Key == fileKey }
+	if slices.ContainsFunc(configFields(), isFileKey) && v.GetString(fileKey) != "" {
+		return "secret file " + v.GetString(fileKey) + " (" + configValueSource(v, flags, fileKey) + ")"
+	}
+	if flag := flags.Lookup(key); flag != nil && flag.Changed {
+		return "flag --" + key
+	}
+	for _, env := range configEnvNames(key) {
+		if _, ok := os.LookupEnv(env); ok {
+			return "env " + env
+		}
+	}
+	if v.InConfig(key) {
+		return "file " + v.ConfigFileUsed()
+	}
+	return "default"
+}
+
+func configEnvNames(key string) []string {
+	env := EnvPrefix + "_" + strings.ToUpper(EnvStrReplacer.Replace(key))
+	return append([]string{env}, configEnvAliases[key]...)
+}
+
+// configFieldValue returns value of the field, with secrets redacted
+func configFieldValue(cfg *Config, field configField) any {
+	value := reflect.ValueOf(cfg).Elem().Field(field.Index)
+	if field.Secret {
+		if value.IsZero() {
+			return ""
+		}
+		return RedactedValue
+	}
+	return value.Interface()
+}
+
+// configSchema builds JSON Schema of the config file from the Config
+// struct and flags, registered for it
+func configSchema(flags *pflag.FlagSet) map[string]any {
+	properties := make(map[string]any)
+	for _, field := range configFields() {
+		property := map[string]any{}
+		switch field.Kind {
+		case reflect.Bool:
+			property["type"] = "boolean"
+		case reflect.Int:
+			property["type"] = "integer"
+		case reflect.Float64:
+			property["type"] = "number"
+		case reflect.Slice:
+			property["type"] = "array"
+			property["items"] = map[string]any{"type": "string"}
+		default:
+			property["type"] = "string"
+		}
+		if len(field.Enum) > 0 {
+			property["enum"] = field.Enum
+		}
+		if field.Secret {
+			property["writeOnly"] = true
+		}
+		if flag := flags.Lookup(field.Key); flag != nil {
+			property["description"] = flag.Usage
+			if def, ok := flagDefaultValue(flag, field.Kind); ok {
+				property["default"] = def
+			}
+		}
+		properties[field.Key] = property
+	}
+
+	return map[string]any{
+		"$schema":              "https://json-schema.org/draft/2020-12/schema",
+		"title":                "postal_server configuration",
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+func flagDefaultValue(flag *pflag.Flag, kind reflect.Kind) (any, bool) {
+	switch kind {
+	case reflect.Bool:
+		value, err := strconv.ParseBool(flag.DefValue)
+		return value, err == nil
+	case reflect.Int:
+		value, err := strconv.Atoi(flag.DefValue)
+		return value, err == nil
+	case reflect.Float64:
+		value, err := strconv.ParseFloat(flag.DefValue, 64)
+		return value, err == nil
+	case reflect.Slice:
+		value := []string{}
+		// pflag renders slice defaults as "[a,b]"
+		trimmed := strings.Trim(flag.DefValue, "[]")
+		if trimmed != "" {
+			value = strings.Split(trimmed, ",")
+		}
+		return value, true
+	default:
+		if flag.DefValue == "" {
+			return nil, false
+		}
+		return flag.DefValue, true
+	}
+}
+
+// formatConfigValue encodes value as JSON, which is also valid YAML
+func formatConfigValue(value any) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%q", fmt.Sprint(value))
+	}
+	return string(encoded)
+}
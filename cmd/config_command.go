@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// configCmd groups helpers to inspect server configuration
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect postal server configuration",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate merged configuration and exit",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := LoadConfig(viper.GetViper())
+		if err != nil {
+			var cfgErr *ConfigError
+			if errors.As(err, &cfgErr) {
+				for _, problem := range cfgErr.Problems {
+					fmt.Fprintln(cmd.ErrOrStderr(), "-", problem)
+				}
+			} else {
+				fmt.Fprintln(cmd.ErrOrStderr(), err)
+			}
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "Configuration is valid")
+		return nil
+	},
+}
+
+var configPrintCmd = &cobra.Command{
+	Use:   "print",
+	Short: "Print effective configuration (secrets are redacted) and source of each value",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := decodeConfig(viper.GetViper())
+		if err != nil {
+			return err
+		}
+		if err := cfg.readSecretFiles(); err != nil {
+			fmt.Fprintln(cmd.ErrOrStderr(), err)
+		}
+		for _, field := range configFields() {
+			fmt.Fprintf(
+				cmd.OutOrStdout(),
+				"%s: %s # %s\n",
+				field.Key,
+				formatConfigValue(configFieldValue(cfg, field)),
+				configValueSource(viper.GetViper(), rootCmd.PersistentFlags(), field.Key),
+			)
+		}
+		return nil
+	},
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print JSON Schema of the config file",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(configSchema(rootCmd.PersistentFlags()))
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd, configPrintCmd, configSchemaCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,295 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func validConfig() *Config {
+	return &Config{
+		Host:                  "0.0.0.0",
+		Port:                  8000,
+		LogFormat:             "text",
+		LogLevel:              "info",
+		LogAddressPolicy:      AddressLogPolicyAuto,
+		TracingExporter:       TracingExporterNone,
+		CompressionLevel:      CompressionLevelDefault,
+		CompressionMinSize:    1024,
+		MaxExtractTextLength:  10000,
+		MaxComparePairs:       100,
+		MaxDecompressedBodyMB: 256,
+		CacheMaxAge:           3600,
+		JobsWorkers:           1,
+		WebhookMaxAttempts:    8,
+		WebhookTimeout:        10,
+	}
+}
+
+// newTestViper returns viper with defaults, which flags provide in the server
+func newTestViper() *viper.Viper {
+	v := viper.New()
+	for _, field := range configFields() {
+		if flag := rootCmd.PersistentFlags().Lookup(field.Key); flag != nil {
+			if def, ok := flagDefaultValue(flag, field.Kind); ok {
+				v.SetDefault(field.Key, def)
+			}
+		}
+	}
+	return v
+}
+
+func TestConfigValidate(t *testing.T) {
+	t.Run("Valid Config", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.TrustedProxies = []string{"10.0.0.1", "192.168.0.0/16"}
+		assert.NoError(t, cfg.Validate())
+	})
+
+	tests := []struct {
+		name    string
+		mutate  func(*Config)
+		problem string
+	}{
+		{
+			name:    "Invalid Port",
+			mutate:  func(cfg *Config) { cfg.Port = 70000 },
+			problem: "port: must be between 1 and 65535, got 70000",
+		},
+		{
+			name:    "Invalid Log Level",
+			mutate:  func(cfg *Config) { cfg.LogLevel = "verbose" },
+			problem: `log_level: must be one of trace, debug, info, warn, error, fatal, panic, disabled, got "verbose"`,
+		},
+		{
+			name:    "Invalid Log Format",
+			mutate:  func(cfg *Config) { cfg.LogFormat = "xml" },
+			problem: `log_format: must be one of text, json, got "xml"`,
+		},
+		{
+			name:    "Invalid Trusted Proxy",
+			mutate:  func(cfg *Config) { cfg.TrustedProxies = []string{"localhost"} },
+			problem: `trusted_proxies: "localhost" is not a valid IP address or CIDR range`,
+		},
+		{
+			name:    "Basic Auth Without Password",
+			mutate:  func(cfg *Config) { cfg.BasicAuthUsername = "admin" },
+			problem: "basic_auth_username and basic_auth_password must be set together",
+		},
+		{
+			name:    "Bearer Token With Whitespace",
+			mutate:  func(cfg *Config) { cfg.BearerAuthToken = "my token" },
+			problem: "bearer_auth_token: must not contain whitespace",
+		},
+		{
+			name:    "Negative Max Concurrency",
+			mutate:  func(cfg *Config) { cfg.MaxConcurrency = -1 },
+			problem: "max_concurrency: must not be negative, got -1",
+		},
+		{
+			name:    "Unknown Compression Level",
+			mutate:  func(cfg *Config) { cfg.CompressionLevel = "max" },
+			problem: `compression_level: must be one of none, fastest, default, best, got "max"`,
+		},
+		{
+			name:    "Negative Compression Min Size",
+			mutate:  func(cfg *Config) { cfg.CompressionMinSize = -1 },
+			problem: "compression_min_size: must not be negative, got -1",
+		},
+		{
+			name:    "Negative Max Extract Text Length",
+			mutate:  func(cfg *Config) { cfg.MaxExtractTextLength = -1 },
+			problem: "max_extract_text_length: must not be negative, got -1",
+		},
+		{
+			name:    "Negative Max Compare Pairs",
+			mutate:  func(cfg *Config) { cfg.MaxComparePairs = -1 },
+			problem: "max_compare_pairs: must not be negative, got -1",
+		},
+		{
+			name:    "No Decompressed Body Size",
+			mutate:  func(cfg *Config) { cfg.MaxDecompressedBodyMB = 0 },
+			problem: "max_decompressed_body_mb: must be at least 1, got 0",
+		},
+		{
+			name:    "Negative Cache Max Age",
+			mutate:  func(cfg *Config) { cfg.CacheMaxAge = -1 },
+			problem: "cache_max_age_seconds: must not be negative, got -1",
+		},
+		{
+			name:    "No Job Workers",
+			mutate:  func(cfg *Config) { cfg.JobsWorkers = 0 },
+			problem: "jobs_workers: must be at least 1, got 0",
+		},
+		{
+			name:    "Negative Jobs Retention",
+			mutate:  func(cfg *Config) { cfg.JobsRetentionHours = -24 },
+			problem: "jobs_retention_hours: must not be negative, got -24",
+		},
+		{
+			name:    "No Webhook Attempts",
+			mutate:  func(cfg *Config) { cfg.WebhookMaxAttempts = 0 },
+			problem: "webhook_max_attempts: must be at least 1, got 0",
+		},
+		{
+			name:    "No Webhook Timeout",
+			mutate:  func(cfg *Config) { cfg.WebhookTimeout = 0 },
+			problem: "webhook_timeout_seconds: must be at least 1, got 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.mutate(cfg)
+
+			err := cfg.Validate()
+			assert.ErrorContains(t, err, tt.problem)
+
+			var cfgErr *ConfigError
+			assert.ErrorAs(t, err, &cfgErr)
+			assert.Equal(t, []string{tt.problem}, cfgErr.Problems)
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	v := newTestViper()
+	v.Set("port", "8080")
+	v.Set("log_format", "json")
+	v.Set("log_level", "debug")
+	v.Set("trusted_proxies", "10.0.0.1,10.0.0.2")
+
+	cfg, err := LoadConfig(v)
+	assert.NoError(t, err)
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, "json", cfg.LogFormat)
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, cfg.TrustedProxies)
+
+	v.Set("port", 0)
+	_, err = LoadConfig(v)
+	assert.ErrorContains(t, err, "port: must be between 1 and 65535")
+}
+
+func TestConfigValueSource(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("host", "0.0.0.0", "server host")
+	flags.String("log_level", "info", "logger level")
+	flags.Int("port", 8000, "server port")
+	assert.NoError(t, flags.Parse([]string{"--host", "127.0.0.1"}))
+
+	t.Setenv("PORT", "9000")
+
+	v := viper.New()
+	assert.Equal(t, "flag --host", configValueSource(v, flags, "host"))
+	assert.Equal(t, "env PORT", configValueSource(v, flags, "port"))
+	assert.Equal(t, "default", configValueSource(v, flags, "log_level"))
+}
+
+func TestConfigFieldValueRedactsSecrets(t *testing.T) {
+	cfg := validConfig()
+	cfg.BasicAuthUsername = "admin"
+	cfg.BasicAuthPassword = "super-secret"
+
+	values := make(map[string]any)
+	for _, field := range configFields() {
+		values[field.Key] = configFieldValue(cfg, field)
+	}
+
+	assert.Equal(t, "admin", values["basic_auth_username"])
+	assert.Equal(t, RedactedValue, values["basic_auth_password"])
+	// unset secrets are shown as empty, so it is visible they are not configured
+	assert.Equal(t, "", values["bearer_auth_token"])
+}
+
+func TestConfigSchema(t *testing.T) {
+	schema := configSchema(rootCmd.PersistentFlags())
+	properties := schema["properties"].(map[string]any)
+
+	assert.Len(t, properties, len(configFields()))
+
+	port := properties["port"].(map[string]any)
+	assert.Equal(t, "integer", port["type"])
+	assert.Equal(t, 8000, port["default"])
+
+	logFormat := properties["log_format"].(map[string]any)
+	assert.Equal(t, []string{"text", "json"}, logFormat["enum"])
+
+	token := properties["bearer_auth_token"].(map[string]any)
+	assert.Equal(t, true, token["writeOnly"])
+	assert.NotContains(t, token, "default")
+}
+
+func TestEverySecretHasFileSetting(t *testing.T) {
+	keys := make(map[string]bool)
+	for _, field := range configFields() {
+		keys[field.Key] = true
+	}
+	for _, field := range configFields() {
+		if field.Secret {
+			assert.True(t, keys[field.Key+SecretFileSuffix], "secret %s must have %s%s setting", field.Key, field.Key, SecretFileSuffix)
+		}
+	}
+}
+
+func TestLoadConfigSecretFiles(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	assert.NoError(t, os.WriteFile(tokenFile, []byte("file-token\n"), 0o600))
+
+	t.Run("Secret Read From File", func(t *testing.T) {
+		v := newTestViper()
+		v.Set("bearer_auth_token_file", tokenFile)
+
+		cfg, err := LoadConfig(v)
+		assert.NoError(t, err)
+		assert.Equal(t, "file-token", cfg.BearerAuthToken)
+
+		// file is re-read on every load
+		assert.NoError(t, os.WriteFile(tokenFile, []byte("rotated-token"), 0o600))
+		cfg, err = LoadConfig(v)
+		assert.NoError(t, err)
+		assert.Equal(t, "rotated-token", cfg.BearerAuthToken)
+	})
+
+	t.Run("Missing File", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.BasicAuthPasswordFile = filepath.Join(dir, "missing")
+
+		err := cfg.readSecretFiles()
+		assert.ErrorContains(t, err, "basic_auth_password_file: unable to read secret file")
+	})
+
+	t.Run("Both Value And File", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.BearerAuthToken = "plain-token"
+		cfg.BearerAuthTokenFile = tokenFile
+
+		err := cfg.readSecretFiles()
+		assert.ErrorContains(t, err, "bearer_auth_token and bearer_auth_token_file must not be set together")
+		assert.NotContains(t, err.Error(), "plain-token")
+	})
+}
+
+func TestConfigLogObjectRedactsSecrets(t *testing.T) {
+	cfg := validConfig()
+	cfg.BearerAuthToken = "super-secret-token"
+
+	// logging is disabled globally in tests
+	level := zerolog.GlobalLevel()
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	t.Cleanup(func() { zerolog.SetGlobalLevel(level) })
+
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf)
+	logger.Info().Object("config", cfg).Msg("config")
+
+	assert.NotContains(t, buf.String(), "super-secret-token")
+	assert.Contains(t, buf.String(), RedactedValue)
+}
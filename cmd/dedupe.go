@@ -0,0 +1,518 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	gopostalExpand "github.com/openvenues/gopostal/expand"
+	gopostalParser "github.com/openvenues/gopostal/parser"
+)
+
+const (
+	dedupeDefaultThreshold    float64 = 0.9
+	dedupeDefaultMaxBlockSize int     = 500
+	// blocking keys are spilled into partitions on disk, only one
+	// partition is loaded into memory at once
+	dedupePartitions int = 64
+	// progress is reported every N rows
+	dedupeProgressEvery int = 1000
+	// expansions of one component, which are used in blocking keys
+	dedupeMaxKeyExpansions int = 3
+	// size of blocking key entry on disk: key hash and row
+	dedupeBlockEntrySize int = 12
+)
+
+// dedupe phases
+const (
+	DedupePhaseNormalize string = "normalize"
+	DedupePhaseCompare   string = "compare"
+	DedupePhaseWrite     string = "write"
+	DedupePhaseDone      string = "done"
+)
+
+// DedupeOptions are options of batch deduplication
+type DedupeOptions struct {
+	// input format (csv or ndjson) and columns (or fields) with address
+	Format  string
+	Columns []string
+	// output format, same as input format by default
+	OutputFormat string
+	// pairs with similarity score of at least threshold are duplicates (0.9 by default)
+	Threshold float64
+	// blocks with more rows are not compared (e.g. postal code of big building)
+	MaxBlockSize int
+	Weights      map[string]float64
+	Parser       gopostalParser.ParserOptions
+	Expand       gopostalExpand.ExpandOptions
+	// directory of temporary files, system temporary directory by default
+	TempDir  string
+	Progress func(DedupeProgress)
+}
+
+// DedupeProgress is progress (and final stats) of deduplication
+type DedupeProgress struct {
+	Phase         string `json:"phase"`
+	Rows          int    `json:"rows"`
+	Blocks        int    `json:"blocks"`
+	SkippedBlocks int    `json:"skipped_blocks"`
+	Comparisons   int    `json:"comparisons"`
+	// clusters with duplicates and rows, which are duplicates of other rows
+	Clusters   int `json:"clusters"`
+	Duplicates int `json:"duplicates"`
+}
+
+// DedupeRow is output row: cluster is number of first row of the cluster,
+// rows without duplicates are clusters of one row
+type DedupeRow struct {
+	Row         int    `json:"row"`
+	Cluster     int    `json:"cluster"`
+	ClusterSize int    `json:"cluster_size"`
+	Address     string `json:"address"`
+}
+
+// dedupeRecord is normalized input row, which is stored in temporary file
+type dedupeRecord struct {
+	Address    string            `json:"address"`
+	Normalized NormalizedAddress `json:"normalized"`
+}
+
+// dedupeRecords reads addresses, groups duplicates into clusters and writes
+// cluster of every input row in order of input. Normalized rows and blocking
+// keys are kept in temporary files, so memory is bounded by number of rows
+// (few bytes per row) and size of the biggest partition of blocking keys
+func dedupeRecords(ctx context.Context, input io.Reader, output io.Writer, options DedupeOptions) (DedupeProgress, error) {
+	if options.OutputFormat == "" {
+		options.OutputFormat = options.Format
+	}
+	if options.Threshold <= 0 {
+		options.Threshold = dedupeDefaultThreshold
+	}
+	if options.MaxBlockSize <= 0 {
+		options.MaxBlockSize = dedupeDefaultMaxBlockSize
+	}
+	weights, err := compareWeights(options.Weights)
+	if err != nil {
+		return DedupeProgress{}, err
+	}
+	reader, err := newRecordReader(input, options.Format, options.Columns)
+	if err != nil {
+		return DedupeProgress{}, err
+	}
+	writer, err := newDedupeRowWriter(output, options.OutputFormat)
+	if err != nil {
+		return DedupeProgress{}, err
+	}
+
+	dir, err := os.MkdirTemp(options.TempDir, "dedupe-")
+	if err != nil {
+		return DedupeProgress{}, err
+	}
+	defer os.RemoveAll(dir)
+
+	d := &deduper{
+		ctx:       ctx,
+		dir:       dir,
+		options:   options,
+		weights:   weights,
+		recordEnd: []int64{0},
+	}
+	defer d.close()
+
+	steps := []func() error{
+		func() error { return d.normalize(reader) },
+		d.compare,
+		func() error { return d.write(writer) },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return d.progress, err
+		}
+	}
+	d.report(DedupePhaseDone)
+	return d.progress, nil
+}
+
+// deduper is state of one deduplication run
+type deduper struct {
+	ctx      context.Context
+	dir      string
+	options  DedupeOptions
+	weights  map[string]float64
+	progress DedupeProgress
+
+	records *os.File
+	// end offsets of rows in records file, recordEnd[0] is 0
+	recordEnd  []int64
+	partitions []*os.File
+	// union-find of rows, root is first row of the cluster
+	parent []int32
+}
+
+func (d *deduper) report(phase string) {
+	d.progress.Phase = phase
+	if d.options.Progress != nil {
+		d.options.Progress(d.progress)
+	}
+}
+
+func (d *deduper) close() {
+	if d.records != nil {
+		d.records.Close()
+	}
+	for _, partition := range d.partitions {
+		partition.Close()
+	}
+}
+
+// normalize parses and expands every row, stores it and spills its
+// blocking keys into partitions
+func (d *deduper) normalize(reader recordReader) error {
+	var err error
+	if d.records, err = os.Create(filepath.Join(d.dir, "records.ndjson")); err != nil {
+		return err
+	}
+	records := bufio.NewWriter(d.records)
+
+	partitions := make([]*bufio.Writer, dedupePartitions)
+	for i := range partitions {
+		file, err := os.Create(filepath.Join(d.dir, fmt.Sprintf("blocks-%02d", i)))
+		if err != nil {
+			return err
+		}
+		d.partitions = append(d.partitions, file)
+		partitions[i] = bufio.NewWriter(file)
+	}
+
+	d.report(DedupePhaseNormalize)
+	entry := make([]byte, dedupeBlockEntrySize)
+	for {
+		address, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("row %d: %w", d.progress.Rows+1, err)
+		}
+		if err := d.ctx.Err(); err != nil {
+			return err
+		}
+		if d.progress.Rows == math.MaxInt32 {
+			return fmt.Errorf("too many rows, max is %d", math.MaxInt32)
+		}
+
+		row := d.progress.Rows
+		record := dedupeRecord{Address: address}
+		if strings.TrimSpace(address) != "" {
+			release, err := acquireLibpostal(d.ctx)
+			if err != nil {
+				return err
+			}
+			record.Normalized = normalizeAddress(d.ctx, address, d.options.Parser, d.options.Expand, 0)
+			release()
+		}
+		line, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		line = append(line, '\n')
+		if _, err := records.Write(line); err != nil {
+			return err
+		}
+		d.recordEnd = append(d.recordEnd, d.recordEnd[row]+int64(len(line)))
+
+		for _, key := range dedupeBlockingKeys(record.Normalized) {
+			hash := blockingKeyHash(key)
+			binary.LittleEndian.PutUint64(entry, hash)
+			binary.LittleEndian.PutUint32(entry[8:], uint32(row))
+			if _, err := partitions[hash%uint64(dedupePartitions)].Write(entry); err != nil {
+				return err
+			}
+		}
+
+		d.progress.Rows++
+		if d.progress.Rows%dedupeProgressEvery == 0 {
+			d.report(DedupePhaseNormalize)
+		}
+	}
+
+	if err := records.Flush(); err != nil {
+		return err
+	}
+	for _, partition := range partitions {
+		if err := partition.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compare loads partitions one by one and compares rows of every block
+func (d *deduper) compare() error {
+	d.parent = make([]int32, d.progress.Rows)
+	for i := range d.parent {
+		d.parent[i] = int32(i)
+	}
+
+	d.report(DedupePhaseCompare)
+	for _, partition := range d.partitions {
+		blocks, err := readBlocks(partition)
+		if err != nil {
+			return err
+		}
+		for _, hash := range slices.Sorted(maps.Keys(blocks)) {
+			rows := blocks[hash]
+			if len(rows) < 2 {
+				continue
+			}
+			if err := d.ctx.Err(); err != nil {
+				return err
+			}
+			d.progress.Blocks++
+			if len(rows) > d.options.MaxBlockSize {
+				d.progress.SkippedBlocks++
+				continue
+			}
+			if err := d.compareBlock(rows); err != nil {
+				return err
+			}
+			if d.progress.Blocks%dedupeProgressEvery == 0 {
+				d.report(DedupePhaseCompare)
+			}
+		}
+	}
+	return nil
+}
+
+// compareBlock compares every pair of rows in the block, which are not
+// in the same cluster yet
+func (d *deduper) compareBlock(rows []int32) error {
+	records := make([]NormalizedAddress, len(rows))
+	for i, row := range rows {
+		record, err := d.readRecord(row)
+		if err != nil {
+			return err
+		}
+		records[i] = record.Normalized
+	}
+
+	for i := range rows {
+		for j := i + 1; j < len(rows); j++ {
+			if d.find(rows[i]) == d.find(rows[j]) {
+				continue
+			}
+			d.progress.Comparisons++
+			if addressSimilarity(records[i], records[j], d.weights).Score >= d.options.Threshold {
+				d.union(rows[i], rows[j])
+			}
+		}
+	}
+	return nil
+}
+
+// write writes cluster of every row in order of input
+func (d *deduper) write(writer *dedupeRowWriter) error {
+	sizes := make([]int32, len(d.parent))
+	for row := range d.parent {
+		sizes[d.find(int32(row))]++
+	}
+	for _, size := range sizes {
+		if size > 1 {
+			d.progress.Clusters++
+			d.progress.Duplicates += int(size) - 1
+		}
+	}
+
+	d.report(DedupePhaseWrite)
+	if _, err := d.records.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	records := bufio.NewReader(d.records)
+	for row := range d.parent {
+		line, err := records.ReadBytes('\n')
+		if err != nil {
+			return err
+		}
+		var record struct {
+			Address string `json:"address"`
+		}
+		if err := json.Unmarshal(line, &record); err != nil {
+			return err
+		}
+		cluster := d.find(int32(row))
+		if err := writer.Write(DedupeRow{
+			Row:         row + 1,
+			Cluster:     int(cluster) + 1,
+			ClusterSize: int(sizes[cluster]),
+			Address:     record.Address,
+		}); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// readRecord reads stored row
+func (d *deduper) readRecord(row int32) (dedupeRecord, error) {
+	start, end := d.recordEnd[row], d.recordEnd[row+1]
+	line := make([]byte, end-start)
+	if _, err := d.records.ReadAt(line, start); err != nil {
+		return dedupeRecord{}, err
+	}
+	var record dedupeRecord
+	err := json.Unmarshal(line, &record)
+	return record, err
+}
+
+func (d *deduper) find(row int32) int32 {
+	for d.parent[row] != row {
+		// path halving
+		d.parent[row] = d.parent[d.parent[row]]
+		row = d.parent[row]
+	}
+	return row
+}
+
+// union merges clusters, first row stays the root
+func (d *deduper) union(a, b int32) {
+	rootA, rootB := d.find(a), d.find(b)
+	if rootA < rootB {
+		d.parent[rootB] = rootA
+	} else if rootB < rootA {
+		d.parent[rootA] = rootB
+	}
+}
+
+// readBlocks reads blocking key entries of partition and groups rows by key hash
+func readBlocks(partition *os.File) (map[uint64][]int32, error) {
+	if _, err := partition.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	reader := bufio.NewReader(partition)
+	blocks := make(map[uint64][]int32)
+	entry := make([]byte, dedupeBlockEntrySize)
+	for {
+		if _, err := io.ReadFull(reader, entry); err != nil {
+			if errors.Is(err, io.EOF) {
+				return blocks, nil
+			}
+			return nil, err
+		}
+		hash := binary.LittleEndian.Uint64(entry)
+		row := int32(binary.LittleEndian.Uint32(entry[8:]))
+		// same key can be generated by several expansions of one row
+		if rows := blocks[hash]; len(rows) == 0 || rows[len(rows)-1] != row {
+			blocks[hash] = append(rows, row)
+		}
+	}
+}
+
+// blockingKeyHash hashes blocking key. Collisions only add comparisons
+func blockingKeyHash(key string) uint64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(key))
+	return hash.Sum64()
+}
+
+// dedupeBlockingKeys returns keys of blocks, which the address is compared
+// within: postal code or road with house number, PO box with postal code
+// and venue name with postal code (or city). Address without any of them
+// is never compared and stays cluster of one row
+func dedupeBlockingKeys(normalized NormalizedAddress) []string {
+	labels := expansionsByLabel(normalized)
+	forms := func(label string, compact bool) []string {
+		var values []string
+		for _, expansion := range labels[label].expansions {
+			if compact {
+				expansion = compactPostcode(expansion)
+			}
+			if expansion != "" && !slices.Contains(values, expansion) {
+				values = append(values, expansion)
+			}
+			if len(values) == dedupeMaxKeyExpansions {
+				break
+			}
+		}
+		return values
+	}
+	houseNumbers, postcodes := forms("house_number", true), forms("postcode", true)
+	localities := postcodes
+	if len(localities) == 0 {
+		localities = forms("city", false)
+	}
+
+	var keys []string
+	add := func(prefix string, values1, values2 []string) {
+		for _, value1 := range values1 {
+			for _, value2 := range values2 {
+				if key := prefix + "|" + value1 + "|" + value2; !slices.Contains(keys, key) {
+					keys = append(keys, key)
+				}
+			}
+		}
+	}
+	add("postcode", postcodes, houseNumbers)
+	add("road", forms("road", false), houseNumbers)
+	add("po_box", forms("po_box", true), localities)
+	if len(houseNumbers) == 0 {
+		add("house", forms("house", false), localities)
+	}
+	return keys
+}
+
+// dedupeRowWriter writes output rows as CSV (with header) or NDJSON
+type dedupeRowWriter struct {
+	csv  *csv.Writer
+	json *json.Encoder
+	buf  *bufio.Writer
+}
+
+func newDedupeRowWriter(w io.Writer, format string) (*dedupeRowWriter, error) {
+	buf := bufio.NewWriter(w)
+	switch format {
+	case RecordFormatCSV:
+		writer := csv.NewWriter(buf)
+		if err := writer.Write([]string{"row", "cluster", "cluster_size", "address"}); err != nil {
+			return nil, err
+		}
+		return &dedupeRowWriter{csv: writer, buf: buf}, nil
+	case RecordFormatNDJSON:
+		return &dedupeRowWriter{json: json.NewEncoder(buf), buf: buf}, nil
+	}
+	return nil, fmt.Errorf("unsupported output format %q, must be one of %s, %s", format, RecordFormatCSV, RecordFormatNDJSON)
+}
+
+func (w *dedupeRowWriter) Write(row DedupeRow) error {
+	if w.json != nil {
+		return w.json.Encode(row)
+	}
+	return w.csv.Write([]string{
+		strconv.Itoa(row.Row),
+		strconv.Itoa(row.Cluster),
+		strconv.Itoa(row.ClusterSize),
+		row.Address,
+	})
+}
+
+func (w *dedupeRowWriter) Flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	return w.buf.Flush()
+}
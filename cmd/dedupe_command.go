@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	gopostalExpand "github.com/openvenues/gopostal/expand"
+	gopostalParser "github.com/openvenues/gopostal/parser"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// dedupeCmd groups duplicates of addresses file into clusters
+var dedupeCmd = &cobra.Command{
+	Use:   "dedupe [input]",
+	Short: "Group duplicate addresses of CSV or NDJSON file into clusters",
+	Long: `Parse and expand every address of the input file (stdin if input is "-" or not provided),
+compare addresses within blocks (same postal code or road with house number) and
+write cluster of every input row as CSV or NDJSON`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// config is loaded once, max_concurrency is applied to every row
+		cfg, err := LoadConfig(viper.GetViper())
+		if err != nil {
+			return err
+		}
+		activeConfig.Store(cfg)
+
+		flags := cmd.Flags()
+		inputPath := "-"
+		if len(args) > 0 {
+			inputPath = args[0]
+		}
+		outputPath, _ := flags.GetString("output")
+
+		options := DedupeOptions{
+			Expand: gopostalExpand.GetDefaultExpansionOptions(),
+		}
+		options.Format, _ = flags.GetString("format")
+		options.OutputFormat, _ = flags.GetString("output_format")
+		options.Columns, _ = flags.GetStringArray("column")
+		options.Threshold, _ = flags.GetFloat64("threshold")
+		options.MaxBlockSize, _ = flags.GetInt("max_block_size")
+		options.TempDir, _ = flags.GetString("temp_dir")
+		language, _ := flags.GetString("language")
+		country, _ := flags.GetString("country")
+		options.Parser = gopostalParser.ParserOptions{Language: language, Country: country}
+		if options.Format == "" {
+			options.Format = recordFormatFromPath(inputPath)
+		}
+		if options.OutputFormat == "" && outputPath != "" && outputPath != "-" {
+			options.OutputFormat = recordFormatFromPath(outputPath)
+		}
+
+		var input io.Reader = cmd.InOrStdin()
+		if inputPath != "-" {
+			file, err := os.Open(inputPath)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			input = file
+		}
+		var output io.Writer = cmd.OutOrStdout()
+		if outputPath != "" && outputPath != "-" {
+			file, err := os.Create(outputPath)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			output = file
+		}
+
+		// stderr keeps stdout clean for the output
+		logger := zerolog.New(zerolog.ConsoleWriter{Out: cmd.ErrOrStderr(), TimeFormat: time.RFC3339}).
+			With().Timestamp().Logger()
+		options.Progress = func(progress DedupeProgress) {
+			logger.Info().
+				Str("phase", progress.Phase).
+				Int("rows", progress.Rows).
+				Int("blocks", progress.Blocks).
+				Int("comparisons", progress.Comparisons).
+				Int("clusters", progress.Clusters).
+				Int("duplicates", progress.Duplicates).
+				Msg("Dedupe progress")
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		progress, err := dedupeRecords(ctx, input, output, options)
+		if err != nil {
+			logger.Error().Err(err).Int("rows", progress.Rows).Msg("Dedupe failed")
+			return err
+		}
+		if progress.SkippedBlocks > 0 {
+			logger.Warn().
+				Int("skipped_blocks", progress.SkippedBlocks).
+				Int("max_block_size", options.MaxBlockSize).
+				Msg("Blocks with too many rows were not compared")
+		}
+		return nil
+	},
+}
+
+func init() {
+	flags := dedupeCmd.Flags()
+	flags.StringP("output", "o", "", "output file (default stdout)")
+	flags.String("format", "", "input format, csv or ndjson (default by input file extension, csv for stdin)")
+	flags.String("output_format", "", "output format, csv or ndjson (default by output file extension or input format)")
+	flags.StringArray("column", []string{defaultRecordColumn}, "CSV column or NDJSON field with address (repeat for address split into several columns)")
+	flags.Float64("threshold", dedupeDefaultThreshold, "min similarity score of duplicates (0-1)")
+	flags.Int("max_block_size", dedupeDefaultMaxBlockSize, "blocks with more rows are not compared")
+	flags.String("language", "", "language of addresses for libpostal parser and expansions")
+	flags.String("country", "", "country of addresses for libpostal parser")
+	flags.String("temp_dir", "", "directory of temporary files (default system temporary directory)")
+
+	rootCmd.AddCommand(dedupeCmd)
+}
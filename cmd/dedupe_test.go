@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gopostalExpand "github.com/openvenues/gopostal/expand"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDedupeBlockingKeys(t *testing.T) {
+	address := NormalizedAddress{Components: []NormalizedComponent{
+		{Label: "house_number", Value: "781", Expansions: []string{"781"}},
+		{Label: "road", Value: "franklin ave", Expansions: []string{"franklin avenue"}},
+		{Label: "postcode", Value: "11216-1234", Expansions: []string{"11216-1234", "11216 1234"}},
+	}}
+	assert.Equal(t, []string{
+		"postcode|112161234|781",
+		"road|franklin avenue|781",
+	}, dedupeBlockingKeys(address))
+
+	venue := NormalizedAddress{Components: []NormalizedComponent{
+		{Label: "house", Value: "barboncino", Expansions: []string{"barboncino"}},
+		{Label: "city", Value: "brooklyn", Expansions: []string{"brooklyn"}},
+	}}
+	assert.Equal(t, []string{"house|barboncino|brooklyn"}, dedupeBlockingKeys(venue))
+
+	// address without house number, postal code or venue is never compared
+	assert.Empty(t, dedupeBlockingKeys(NormalizedAddress{Components: []NormalizedComponent{
+		{Label: "city", Value: "brooklyn", Expansions: []string{"brooklyn"}},
+	}}))
+}
+
+func TestDeduperUnionFind(t *testing.T) {
+	d := &deduper{parent: []int32{0, 1, 2, 3, 4}}
+	d.union(3, 1)
+	d.union(4, 3)
+	d.union(2, 0)
+
+	// first row of the cluster is its root
+	assert.Equal(t, int32(1), d.find(4))
+	assert.Equal(t, int32(1), d.find(3))
+	assert.Equal(t, int32(0), d.find(2))
+	assert.NotEqual(t, d.find(0), d.find(1))
+}
+
+func TestDedupeRecords(t *testing.T) {
+	options := DedupeOptions{
+		Format: RecordFormatCSV,
+		Expand: gopostalExpand.GetDefaultExpansionOptions(),
+	}
+
+	t.Run("Rows Without Duplicates", func(t *testing.T) {
+		input := "id,address\n1,781 Franklin Ave Brooklyn NY 11216\n2,10 Downing St London\n3,\n"
+		var output bytes.Buffer
+		var phases []string
+		options := options
+		options.OutputFormat = RecordFormatNDJSON
+		options.Progress = func(progress DedupeProgress) {
+			phases = append(phases, progress.Phase)
+		}
+
+		progress, err := dedupeRecords(context.Background(), strings.NewReader(input), &output, options)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, progress.Rows)
+		assert.Equal(t, 0, progress.Clusters)
+		assert.Equal(t, []string{DedupePhaseNormalize, DedupePhaseCompare, DedupePhaseWrite, DedupePhaseDone}, phases)
+
+		var rows []DedupeRow
+		decoder := json.NewDecoder(&output)
+		for decoder.More() {
+			var row DedupeRow
+			assert.NoError(t, decoder.Decode(&row))
+			rows = append(rows, row)
+		}
+		assert.Equal(t, []DedupeRow{
+			{Row: 1, Cluster: 1, ClusterSize: 1, Address: "781 Franklin Ave Brooklyn NY 11216"},
+			{Row: 2, Cluster: 2, ClusterSize: 1, Address: "10 Downing St London"},
+			{Row: 3, Cluster: 3, ClusterSize: 1, Address: ""},
+		}, rows)
+	})
+
+	t.Run("Duplicates", func(t *testing.T) {
+		input := strings.Join([]string{
+			"address",
+			"781 Franklin Ave Brooklyn NY 11216",
+			"10 Downing St London SW1A 2AA",
+			`"781 franklin avenue, brooklyn, new york 11216"`,
+			"781 Franklin Avenue Brooklyn 11216",
+		}, "\n")
+		var output bytes.Buffer
+
+		progress, err := dedupeRecords(context.Background(), strings.NewReader(input), &output, options)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, progress.Clusters)
+		assert.Equal(t, 2, progress.Duplicates)
+		assert.Equal(t, strings.Join([]string{
+			"row,cluster,cluster_size,address",
+			"1,1,3,781 Franklin Ave Brooklyn NY 11216",
+			"2,2,1,10 Downing St London SW1A 2AA",
+			`3,1,3,"781 franklin avenue, brooklyn, new york 11216"`,
+			"4,1,3,781 Franklin Avenue Brooklyn 11216",
+			"",
+		}, "\n"), output.String())
+	})
+
+	t.Run("Invalid Input", func(t *testing.T) {
+		_, err := dedupeRecords(context.Background(), strings.NewReader("street\nFranklin Ave\n"), &bytes.Buffer{}, options)
+		assert.ErrorContains(t, err, `column "address" is not found`)
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := dedupeRecords(ctx, strings.NewReader("address\n781 Franklin Ave\n"), &bytes.Buffer{}, options)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestDedupeRoutes(t *testing.T) {
+	useTestJobManager(t)
+	router := SetupRouter()
+
+	waitForJob := func(t *testing.T, id string) Job {
+		t.Helper()
+		var job Job
+		assert.Eventually(t, func() bool {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/dedupe/"+id, nil)
+			router.ServeHTTP(w, req)
+			json.Unmarshal(w.Body.Bytes(), &job)
+			return job.finished()
+		}, 5*time.Second, 10*time.Millisecond)
+		return job
+	}
+
+	t.Run("Dedupe File", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		body := "\"10 Downing St London\"\n{\"address\": \"781 Franklin Ave Brooklyn NY 11216\"}\n"
+		req, _ := http.NewRequest(http.MethodPost, "/dedupe?output_format=csv", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-ndjson")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+
+		var job Job
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+		assert.Equal(t, "/dedupe/"+job.ID, w.Header().Get("Location"))
+
+		job = waitForJob(t, job.ID)
+		assert.Equal(t, JobStatusDone, job.Status)
+		assert.Equal(t, 2, job.Progress.Rows)
+		assert.Equal(t, DedupePhaseDone, job.Progress.Dedupe.Phase)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(http.MethodGet, "/dedupe/"+job.ID+"/result", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, "row,cluster,cluster_size,address\n1,1,1,10 Downing St London\n2,2,1,781 Franklin Ave Brooklyn NY 11216\n", w.Body.String())
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(http.MethodDelete, "/dedupe/"+job.ID, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(http.MethodGet, "/dedupe/"+job.ID, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Failed Job", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/dedupe?column=full_address", strings.NewReader("address\n781 Franklin Ave\n"))
+		req.Header.Set("Content-Type", "text/csv")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+
+		var job Job
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+		job = waitForJob(t, job.ID)
+		assert.Equal(t, JobStatusFailed, job.Status)
+		assert.Contains(t, job.Error, `column "full_address" is not found`)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(http.MethodGet, "/dedupe/"+job.ID+"/result", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Unsupported Content Type", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/dedupe", strings.NewReader(`{"address": "781 Franklin Ave"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Invalid Threshold", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/dedupe?threshold=2", strings.NewReader("address\n"))
+		req.Header.Set("Content-Type", "text/csv")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Unknown Job", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/dedupe/unknown/result", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
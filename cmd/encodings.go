@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// media types of responses and request bodies
+const (
+	MediaTypeJSON     string = "application/json"
+	MediaTypeMsgPack  string = "application/msgpack"
+	MediaTypeProtobuf string = "application/x-protobuf"
+)
+
+// responseMediaTypes are offered to Accept header in order of preference,
+// JSON is used for missing or unsupported Accept header
+var responseMediaTypes = []string{
+	MediaTypeJSON,
+	MediaTypeMsgPack,
+	"application/x-msgpack",
+	MediaTypeProtobuf,
+	"application/protobuf",
+}
+
+// mediaTypeAliases map other names of the encodings
+var mediaTypeAliases = map[string]string{
+	"application/x-msgpack": MediaTypeMsgPack,
+	"application/protobuf":  MediaTypeProtobuf,
+}
+
+// msgpackHandle decodes maps as JSON objects and sorts keys of encoded
+// maps, so same response has same bytes. Struct fields have names and
+// omitempty options of json tags, same as JSON response
+var msgpackHandle = func() *codec.MsgpackHandle {
+	h := &codec.MsgpackHandle{}
+	h.TypeInfos = codec.NewTypeInfos([]string{"json"})
+	h.MapType = reflect.TypeFor[map[string]any]()
+	h.RawToString = true
+	h.WriteExt = true
+	h.Canonical = true
+	return h
+}()
+
+// msgpackEncoder is reusable encoder with its buffer
+type msgpackEncoder struct {
+	*codec.Encoder
+	buffer bytes.Buffer
+}
+
+// msgpackEncoders keep encoders (and grown buffers) between responses
+var msgpackEncoders = sync.Pool{
+	New: func() any {
+		encoder := &msgpackEncoder{}
+		encoder.Encoder = codec.NewEncoder(&encoder.buffer, msgpackHandle)
+		return encoder
+	},
+}
+
+// mediaType returns media type without parameters, aliases are replaced
+// by main name
+func mediaType(contentType string) string {
+	parsed, _, _ := mime.ParseMediaType(contentType)
+	if alias, ok := mediaTypeAliases[parsed]; ok {
+		return alias
+	}
+	return parsed
+}
+
+// respond writes response in encoding from Accept header. MessagePack
+// response has same fields as JSON one, Protobuf response is message of
+// postalpb/postal_server.proto
+func respond(c *gin.Context, status int, obj any) {
+	// caches must not return MessagePack response to JSON client
+	c.Writer.Header().Add("Vary", "Accept")
+	if status == http.StatusOK {
+		setCacheHeaders(c)
+	}
+	contentType := mediaType(c.NegotiateFormat(responseMediaTypes...))
+	if contentType != MediaTypeMsgPack && contentType != MediaTypeProtobuf {
+		c.JSON(status, obj)
+		return
+	}
+
+	body, err := encodeResponse(contentType, obj)
+	if err != nil {
+		requestLogger(c).Error().Err(err).Str("content_type", contentType).Msg("Unable to encode response")
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	c.Data(status, contentType, body)
+}
+
+// encodeResponse returns MessagePack or Protobuf encoding of the value
+func encodeResponse(contentType string, obj any) ([]byte, error) {
+	switch contentType {
+	case MediaTypeMsgPack:
+		encoder := msgpackEncoders.Get().(*msgpackEncoder)
+		defer msgpackEncoders.Put(encoder)
+		encoder.buffer.Reset()
+		encoder.Reset(&encoder.buffer)
+		if err := encoder.Encode(obj); err != nil {
+			return nil, err
+		}
+		return bytes.Clone(encoder.buffer.Bytes()), nil
+	case MediaTypeProtobuf:
+		message, err := protobufMessage(obj)
+		if err != nil {
+			return nil, err
+		}
+		return proto.Marshal(message)
+	}
+	return nil, fmt.Errorf("unsupported content type %q", contentType)
+}
+
+// bindRequestBody decodes JSON, MessagePack or Protobuf (google.protobuf.Value)
+// request body by Content-Type. Body of other encodings is converted into
+// JSON, so field names and validation are same for every encoding
+func bindRequestBody(c *gin.Context, obj any) error {
+	contentType := mediaType(c.ContentType())
+	if contentType != MediaTypeMsgPack && contentType != MediaTypeProtobuf {
+		return c.ShouldBindJSON(obj)
+	}
+	if c.Request.Body == nil {
+		return errors.New("invalid request")
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	switch contentType {
+	case MediaTypeMsgPack:
+		var value any
+		if err := codec.NewDecoderBytes(body, msgpackHandle).Decode(&value); err != nil {
+			return fmt.Errorf("invalid MessagePack body: %w", err)
+		}
+		if data, err = json.Marshal(value); err != nil {
+			return fmt.Errorf("invalid MessagePack body: %w", err)
+		}
+	case MediaTypeProtobuf:
+		var value structpb.Value
+		if err := proto.Unmarshal(body, &value); err != nil {
+			return fmt.Errorf("invalid Protobuf body: %w", err)
+		}
+		if data, err = protojson.Marshal(&value); err != nil {
+			return fmt.Errorf("invalid Protobuf body: %w", err)
+		}
+	}
+	return binding.JSON.BindBody(data, obj)
+}
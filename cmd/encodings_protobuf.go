@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/le0pard/postal_server/libpostal"
+	"github.com/le0pard/postal_server/postalpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// protobufMessage returns message of postalpb/postal_server.proto for the
+// response. Lists are wrapped into response message of the endpoint
+func protobufMessage(obj any) (proto.Message, error) {
+	switch obj := obj.(type) {
+	case ErrorResponse:
+		return &postalpb.Error{Error: obj.Error, RequestId: obj.RequestID}, nil
+	case HealthResponse:
+		return &postalpb.Health{Status: obj.Status}, nil
+	case VersionResponse:
+		return &postalpb.Version{Version: obj.Version}, nil
+	case []ParsedAddressComponent:
+		return &postalpb.ParseResponse{Components: protobufParsedComponents(obj)}, nil
+	case []string:
+		return &postalpb.ExpandResponse{Expansions: obj}, nil
+	case CanonicalExpansion:
+		return &postalpb.CanonicalExpansion{
+			Canonical:   obj.Canonical,
+			Hash:        obj.Hash,
+			RuleVersion: int32(obj.RuleVersion),
+			Expansions:  obj.Expansions,
+		}, nil
+	case []ExplainedExpansion:
+		expansions := make([]*postalpb.ExplainedExpansion, len(obj))
+		for i, expansion := range obj {
+			expansions[i] = &postalpb.ExplainedExpansion{Expansion: expansion.Expansion, Transformations: expansion.Transformations}
+		}
+		return &postalpb.ExplainResponse{Expansions: expansions}, nil
+	case NormalizedAddress:
+		return &postalpb.NormalizedAddress{Canonical: obj.Canonical, Components: protobufNormalizedComponents(obj.Components)}, nil
+	case []NormalizedComponent:
+		return &postalpb.NormalizedComponents{Components: protobufNormalizedComponents(obj)}, nil
+	case []libpostal.Language:
+		languages := make([]*postalpb.Language, len(obj))
+		for i, language := range obj {
+			languages[i] = &postalpb.Language{Language: language.Language, Probability: language.Probability}
+		}
+		return &postalpb.LanguagesResponse{Languages: languages}, nil
+	case []ExtractedAddress:
+		addresses := make([]*postalpb.ExtractedAddress, len(obj))
+		for i, address := range obj {
+			addresses[i] = &postalpb.ExtractedAddress{
+				Text:       address.Text,
+				Start:      int64(address.Start),
+				End:        int64(address.End),
+				Confidence: address.Confidence,
+				Components: protobufParsedComponents(address.Components),
+			}
+		}
+		return &postalpb.ExtractResponse{Addresses: addresses}, nil
+	case AddressSimilarity:
+		return protobufAddressSimilarity(obj), nil
+	case []AddressSimilarity:
+		similarities := make([]*postalpb.AddressSimilarity, len(obj))
+		for i, similarity := range obj {
+			similarities[i] = protobufAddressSimilarity(similarity)
+		}
+		return &postalpb.CompareResponse{Similarities: similarities}, nil
+	case []libpostal.Token:
+		tokens := make([]*postalpb.Token, len(obj))
+		for i, token := range obj {
+			tokens[i] = &postalpb.Token{Token: token.Token, Type: token.Type, Offset: int64(token.Offset), Length: int64(token.Length)}
+		}
+		return &postalpb.TokenizeResponse{Tokens: tokens}, nil
+	case NormalizedString:
+		tokens := make([]*postalpb.NormalizedToken, len(obj.Tokens))
+		for i, token := range obj.Tokens {
+			tokens[i] = &postalpb.NormalizedToken{Normalized: token.Normalized, Type: token.Type, Offset: int64(token.Offset), Length: int64(token.Length)}
+		}
+		return &postalpb.NormalizedString{Normalized: obj.Normalized, Tokens: tokens}, nil
+	case PostcodeResult:
+		return &postalpb.PostcodeResult{
+			Postcode:  obj.Postcode,
+			Country:   obj.Country,
+			Supported: obj.Supported,
+			Valid:     obj.Valid,
+			Canonical: obj.Canonical,
+			Countries: obj.Countries,
+		}, nil
+	case USPSAddress:
+		return &postalpb.USPSAddress{
+			DeliveryLine:        obj.DeliveryLine,
+			LastLine:            obj.LastLine,
+			PrimaryNumber:       obj.PrimaryNumber,
+			Predirectional:      obj.Predirectional,
+			StreetName:          obj.StreetName,
+			Suffix:              obj.Suffix,
+			Postdirectional:     obj.Postdirectional,
+			SecondaryDesignator: obj.SecondaryDesignator,
+			SecondaryNumber:     obj.SecondaryNumber,
+			PoBox:               obj.POBox,
+			City:                obj.City,
+			State:               obj.State,
+			ZipCode:             obj.ZIPCode,
+		}, nil
+	case SchemaOrgPostalAddress:
+		return &postalpb.SchemaOrgPostalAddress{
+			Context:             obj.Context,
+			Type:                obj.Type,
+			Name:                obj.Name,
+			StreetAddress:       obj.StreetAddress,
+			PostOfficeBoxNumber: obj.PostOfficeBoxNumber,
+			AddressLocality:     obj.AddressLocality,
+			AddressRegion:       obj.AddressRegion,
+			PostalCode:          obj.PostalCode,
+			AddressCountry:      obj.AddressCountry,
+		}, nil
+	case VCardAddress:
+		return &postalpb.VCardAddress{
+			Adr:             obj.ADR,
+			PostOfficeBox:   obj.PostOfficeBox,
+			ExtendedAddress: obj.ExtendedAddress,
+			StreetAddress:   obj.StreetAddress,
+			Locality:        obj.Locality,
+			Region:          obj.Region,
+			PostalCode:      obj.PostalCode,
+			CountryName:     obj.CountryName,
+		}, nil
+	case map[string]string:
+		// only tags of osm standard are plain map
+		return &postalpb.OSMAddress{Tags: obj}, nil
+	case LibaddressinputAddress:
+		return &postalpb.LibaddressinputAddress{
+			RegionCode:         obj.RegionCode,
+			AdministrativeArea: obj.AdministrativeArea,
+			Locality:           obj.Locality,
+			DependentLocality:  obj.DependentLocality,
+			PostalCode:         obj.PostalCode,
+			AddressLine:        obj.AddressLines,
+			Organization:       obj.Organization,
+		}, nil
+	case Job:
+		return protobufJob(obj), nil
+	}
+	return nil, fmt.Errorf("no Protobuf message for %T", obj)
+}
+
+// protobufParsedComponents returns messages of parsed components
+func protobufParsedComponents(components []ParsedAddressComponent) []*postalpb.ParsedComponent {
+	messages := make([]*postalpb.ParsedComponent, len(components))
+	for i, component := range components {
+		messages[i] = &postalpb.ParsedComponent{
+			Label:       component.Label,
+			Value:       component.Value,
+			CountryCode: component.CountryCode,
+			StateCode:   component.StateCode,
+			Canonical:   component.Canonical,
+			Valid:       component.Valid,
+			ParsedAs:    component.ParsedAs,
+		}
+	}
+	return messages
+}
+
+// protobufNormalizedComponents returns messages of normalized components
+func protobufNormalizedComponents(components []NormalizedComponent) []*postalpb.NormalizedComponent {
+	messages := make([]*postalpb.NormalizedComponent, len(components))
+	for i, component := range components {
+		messages[i] = &postalpb.NormalizedComponent{
+			Label:      component.Label,
+			Value:      component.Value,
+			Canonical:  component.Canonical,
+			Expansions: component.Expansions,
+			ParsedAs:   component.ParsedAs,
+			Valid:      component.Valid,
+		}
+	}
+	return messages
+}
+
+// protobufAddressSimilarity returns message of compare result
+func protobufAddressSimilarity(similarity AddressSimilarity) *postalpb.AddressSimilarity {
+	components := make([]*postalpb.ComponentSimilarity, len(similarity.Components))
+	for i, component := range similarity.Components {
+		components[i] = &postalpb.ComponentSimilarity{
+			Label:            component.Label,
+			Value1:           component.Value1,
+			Value2:           component.Value2,
+			ExpansionOverlap: component.ExpansionOverlap,
+			TokenJaccard:     component.TokenJaccard,
+			NumericEqual:     component.NumericEqual,
+			Score:            component.Score,
+			Weight:           component.Weight,
+		}
+	}
+	return &postalpb.AddressSimilarity{Score: similarity.Score, Components: components}
+}
+
+// protobufJob returns message of async job, times are Timestamp messages
+func protobufJob(job Job) *postalpb.Job {
+	message := &postalpb.Job{
+		Id:             job.ID,
+		Operation:      job.Operation,
+		Status:         job.Status,
+		Error:          job.Error,
+		Options:        make(map[string]*postalpb.Job_Values, len(job.Options)),
+		Progress:       &postalpb.JobProgress{Rows: int64(job.Progress.Rows)},
+		IdempotencyKey: job.IdempotencyKey,
+		InputSha256:    job.InputSHA256,
+		CreatedAt:      timestamppb.New(job.CreatedAt),
+		StartedAt:      protobufTimestamp(job.StartedAt),
+		FinishedAt:     protobufTimestamp(job.FinishedAt),
+	}
+	for key, values := range job.Options {
+		message.Options[key] = &postalpb.Job_Values{Values: values}
+	}
+	if dedupe := job.Progress.Dedupe; dedupe != nil {
+		message.Progress.Dedupe = &postalpb.DedupeProgress{
+			Phase:         dedupe.Phase,
+			Rows:          int64(dedupe.Rows),
+			Blocks:        int64(dedupe.Blocks),
+			SkippedBlocks: int64(dedupe.SkippedBlocks),
+			Comparisons:   int64(dedupe.Comparisons),
+			Clusters:      int64(dedupe.Clusters),
+			Duplicates:    int64(dedupe.Duplicates),
+		}
+	}
+	if webhook := job.Webhook; webhook != nil {
+		message.Webhook = &postalpb.JobWebhook{Url: webhook.URL, Status: webhook.Status}
+		for _, delivery := range webhook.Deliveries {
+			message.Webhook.Deliveries = append(message.Webhook.Deliveries, &postalpb.WebhookDelivery{
+				Attempt:    int64(delivery.Attempt),
+				Event:      delivery.Event,
+				At:         timestamppb.New(delivery.At),
+				DurationMs: delivery.DurationMs,
+				StatusCode: int64(delivery.StatusCode),
+				Error:      delivery.Error,
+			})
+		}
+	}
+	return message
+}
+
+// protobufTimestamp returns nil for missing time
+func protobufTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
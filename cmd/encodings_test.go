@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/le0pard/postal_server/libpostal"
+	"github.com/le0pard/postal_server/postalpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestResponseEncodings(t *testing.T) {
+	router := SetupRouter()
+
+	get := func(target, accept string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	jsonResponse := get("/postcode?postcode=sw1a2aa&country=gb", "")
+	assert.Equal(t, "application/json; charset=utf-8", jsonResponse.Header().Get("Content-Type"))
+	var expected map[string]any
+	assert.NoError(t, json.Unmarshal(jsonResponse.Body.Bytes(), &expected))
+
+	t.Run("MessagePack", func(t *testing.T) {
+		for _, accept := range []string{"application/msgpack", "application/x-msgpack"} {
+			w := get("/postcode?postcode=sw1a2aa&country=gb", accept)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, MediaTypeMsgPack, w.Header().Get("Content-Type"))
+			assert.Contains(t, w.Header().Values("Vary"), "Accept")
+
+			var value any
+			assert.NoError(t, codec.NewDecoderBytes(w.Body.Bytes(), msgpackHandle).Decode(&value))
+			assert.Equal(t, expected, value)
+		}
+	})
+
+	t.Run("Protobuf", func(t *testing.T) {
+		w := get("/postcode?postcode=sw1a2aa&country=gb", "application/x-protobuf")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, MediaTypeProtobuf, w.Header().Get("Content-Type"))
+
+		var message postalpb.PostcodeResult
+		assert.NoError(t, proto.Unmarshal(w.Body.Bytes(), &message))
+		// protojson uses JSON names of the fields, same as JSON response
+		body, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(&message)
+		assert.NoError(t, err)
+		assert.JSONEq(t, jsonResponse.Body.String(), string(body))
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		w := get("/unknown", "application/x-protobuf")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		var message postalpb.Error
+		assert.NoError(t, proto.Unmarshal(w.Body.Bytes(), &message))
+		assert.Equal(t, "Not Found", message.GetError())
+	})
+
+	t.Run("Unsupported Accept", func(t *testing.T) {
+		w := get("/postcode?postcode=sw1a2aa&country=gb", "text/html")
+		assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.JSONEq(t, jsonResponse.Body.String(), w.Body.String())
+	})
+}
+
+func TestMsgpackFieldNames(t *testing.T) {
+	valid := true
+	var body []byte
+	assert.NoError(t, codec.NewEncoderBytes(&body, msgpackHandle).Encode([]ParsedAddressComponent{
+		{Label: "postcode", Value: "sw1a 2aa", Valid: &valid},
+	}))
+
+	var value any
+	assert.NoError(t, codec.NewDecoderBytes(body, msgpackHandle).Decode(&value))
+	// names of json tags, empty fields are omitted
+	assert.Equal(t, []any{map[string]any{"label": "postcode", "value": "sw1a 2aa", "valid": true}}, value)
+}
+
+func TestProtobufMessage(t *testing.T) {
+	now := time.Now()
+	valid := true
+	responses := []any{
+		ErrorResponse{Error: "Not Found"},
+		HealthResponse{Status: "ok"},
+		VersionResponse{Version: Version},
+		[]ParsedAddressComponent{{Label: "postcode", Value: "sw1a 2aa", Valid: &valid}},
+		[]string{"10 downing street"},
+		CanonicalExpansion{Canonical: "10 downing street", RuleVersion: CanonicalRuleVersion},
+		[]ExplainedExpansion{{Expansion: "10 downing street", Transformations: []string{"abbreviation"}}},
+		NormalizedAddress{Canonical: map[string]string{"road": "downing street"}, Components: []NormalizedComponent{{Label: "road"}}},
+		[]NormalizedComponent{{Label: "road"}},
+		[]libpostal.Language{{Language: "en", Probability: 0.9}},
+		[]ExtractedAddress{{Text: "10 Downing St", End: 13}},
+		AddressSimilarity{Score: 1, Components: []ComponentSimilarity{{Label: "house_number", NumericEqual: &valid}}},
+		[]AddressSimilarity{{Score: 1}},
+		[]libpostal.Token{{Token: "10", Type: "numeric", Length: 2}},
+		NormalizedString{Normalized: "10 downing st", Tokens: []libpostal.NormalizedToken{{Normalized: "10"}}},
+		PostcodeResult{Postcode: "sw1a2aa", Valid: true},
+		USPSAddress{DeliveryLine: "10 DOWNING ST"},
+		SchemaOrgPostalAddress{Type: "PostalAddress"},
+		VCardAddress{ADR: ";;10 Downing St;;;;"},
+		map[string]string{"addr:housenumber": "10"},
+		LibaddressinputAddress{AddressLines: []string{"10 Downing St"}},
+		Job{
+			ID:        "job",
+			Options:   url.Values{"format": {RecordFormatCSV}},
+			Progress:  JobProgress{Rows: 2, Dedupe: &DedupeProgress{Phase: "done"}},
+			Webhook:   &JobWebhook{Deliveries: []WebhookDelivery{{Attempt: 1, At: now}}},
+			CreatedAt: now,
+		},
+	}
+	for _, response := range responses {
+		message, err := protobufMessage(response)
+		if assert.NoError(t, err, "%T", response) {
+			_, err := proto.Marshal(message)
+			assert.NoError(t, err, "%T", response)
+		}
+	}
+
+	message, err := protobufMessage(Job{CreatedAt: now, StartedAt: &now})
+	assert.NoError(t, err)
+	job := message.(*postalpb.Job)
+	assert.True(t, now.Equal(job.GetStartedAt().AsTime()))
+	assert.Nil(t, job.GetFinishedAt())
+
+	_, err = protobufMessage(StructuredAddressRequest{})
+	assert.ErrorContains(t, err, "no Protobuf message for cmd.StructuredAddressRequest")
+}
+
+// BenchmarkRespond compares encodings of /compare batch response
+func BenchmarkRespond(b *testing.B) {
+	numericEqual := true
+	similarities := make([]AddressSimilarity, 100)
+	for i := range similarities {
+		for _, label := range []string{"house_number", "road", "city", "postcode"} {
+			similarities[i].Components = append(similarities[i].Components, ComponentSimilarity{
+				Label:            label,
+				Value1:           "781 franklin avenue",
+				Value2:           "781 franklin ave",
+				ExpansionOverlap: 0.75,
+				TokenJaccard:     0.5,
+				NumericEqual:     &numericEqual,
+				Score:            0.75,
+				Weight:           3,
+			})
+		}
+		similarities[i].Score = 0.875
+	}
+
+	for _, accept := range []string{MediaTypeJSON, MediaTypeMsgPack, MediaTypeProtobuf} {
+		b.Run(accept, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				w := httptest.NewRecorder()
+				c, _ := gin.CreateTestContext(w)
+				c.Request, _ = http.NewRequest(http.MethodPost, "/compare", nil)
+				c.Request.Header.Set("Accept", accept)
+				respond(c, http.StatusOK, similarities)
+			}
+		})
+	}
+}
+
+func TestBindRequestBody(t *testing.T) {
+	router := gin.New()
+	router.POST("/", func(c *gin.Context) {
+		var request StructuredAddressRequest
+		if err := bindRequestBody(c, &request); err != nil {
+			abortWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		respond(c, http.StatusOK, request)
+	})
+
+	post := func(contentType string, body []byte) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		router.ServeHTTP(w, req)
+		return w
+	}
+	request := map[string]any{"components": map[string]any{"road": "Downing St", "house_number": "10"}, "country": "gb"}
+
+	t.Run("MessagePack", func(t *testing.T) {
+		var body []byte
+		assert.NoError(t, codec.NewEncoderBytes(&body, msgpackHandle).Encode(request))
+
+		w := post("application/msgpack", body)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"components":{"road":"Downing St","house_number":"10"},"country":"gb","language":"","validate":false}`, w.Body.String())
+	})
+
+	t.Run("Protobuf", func(t *testing.T) {
+		value, err := structpb.NewValue(request)
+		assert.NoError(t, err)
+		body, err := proto.Marshal(value)
+		assert.NoError(t, err)
+
+		w := post("application/x-protobuf", body)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"components":{"road":"Downing St","house_number":"10"},"country":"gb","language":"","validate":false}`, w.Body.String())
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		value, _ := structpb.NewValue(map[string]any{"country": "gb"})
+		body, _ := protojson.Marshal(value)
+		assert.Equal(t, http.StatusBadRequest, post("application/json", body).Code)
+
+		body, _ = proto.Marshal(value)
+		w := post("application/x-protobuf", body)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "required")
+	})
+
+	t.Run("Invalid Body", func(t *testing.T) {
+		w := post("application/msgpack", []byte{0xc1})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid MessagePack body")
+	})
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// transformations, which are detected between input address and expansion
+const (
+	TransformationLowercased           string = "lowercased"
+	TransformationAccentsStripped      string = "accents_stripped"
+	TransformationAbbreviationExpanded string = "abbreviation_expanded"
+	TransformationNumexConverted       string = "numex_converted"
+	TransformationRomanNumeral         string = "roman_numeral_converted"
+	TransformationHyphenReplaced       string = "hyphen_replaced"
+	TransformationHyphenDeleted        string = "hyphen_deleted"
+	TransformationPeriodsDeleted       string = "periods_deleted"
+	TransformationPossessiveDropped    string = "possessive_dropped"
+	TransformationApostropheDeleted    string = "apostrophe_deleted"
+	TransformationAlphaNumericSplit    string = "alpha_numeric_split"
+	TransformationReplaced             string = "replaced"
+)
+
+// ExplainedExpansion is expansion with transformations, which produced it
+type ExplainedExpansion struct {
+	Expansion       string   `json:"expansion"`
+	Transformations []string `json:"transformations"`
+}
+
+var (
+	romanNumeralToken = regexp.MustCompile(`^[ivxlcdm]+$`)
+	numericToken      = regexp.MustCompile(`^\d+(st|nd|rd|th|er|e|o|a)?$`)
+	alphaNumericSplit = regexp.MustCompile(`(\pL)(\d)|(\d)(\pL)`)
+)
+
+// stripAccents removes combining marks ("élysées" -> "elysees")
+func stripAccents(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	result, _, err := transform.String(t, s)
+	if err != nil {
+		return s
+	}
+	return result
+}
+
+// explainExpansions returns every expansion with detected transformations
+func explainExpansions(input string, expansions []string) []ExplainedExpansion {
+	explained := make([]ExplainedExpansion, 0, len(expansions))
+	for _, expansion := range expansions {
+		explained = append(explained, ExplainedExpansion{
+			Expansion:       expansion,
+			Transformations: detectTransformations(input, expansion),
+		})
+	}
+	return explained
+}
+
+// detectTransformations compares input with expansion token by token and
+// names the differences. libpostal does not report what it did, so this is
+// a heuristic: differences which can not be classified are "replaced"
+func detectTransformations(input, expansion string) []string {
+	transformations := []string{}
+	add := func(name string) {
+		if !slices.Contains(transformations, name) {
+			transformations = append(transformations, name)
+		}
+	}
+
+	normalizedInput := input
+	if lowered := strings.ToLower(normalizedInput); lowered != normalizedInput && strings.ToLower(expansion) == expansion {
+		add(TransformationLowercased)
+		normalizedInput = lowered
+	}
+	if stripped := stripAccents(normalizedInput); stripped != normalizedInput && stripAccents(expansion) == expansion {
+		add(TransformationAccentsStripped)
+		normalizedInput = stripped
+	}
+
+	inputTokens := strings.Fields(normalizedInput)
+	expansionTokens := strings.Fields(expansion)
+	for _, segment := range diffTokens(inputTokens, expansionTokens) {
+		for _, name := range classifySegmentTokens(segment[0], segment[1]) {
+			add(name)
+		}
+	}
+
+	return transformations
+}
+
+// diffTokens aligns tokens by longest common subsequence and returns
+// pairs of differing segments (input tokens, expansion tokens)
+func diffTokens(a, b []string) [][2][]string {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var segments [][2][]string
+	var segmentA, segmentB []string
+	flush := func() {
+		if len(segmentA) > 0 || len(segmentB) > 0 {
+			segments = append(segments, [2][]string{segmentA, segmentB})
+			segmentA, segmentB = nil, nil
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			flush()
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			segmentB = append(segmentB, b[j])
+			j++
+		default:
+			segmentA = append(segmentA, a[i])
+			i++
+		}
+	}
+	flush()
+
+	return segments
+}
+
+// maximum number of tokens on each side, which can be one transformation
+// (e.g. "u. s. a." -> "usa" or "3a" -> "3 a")
+const maxTransformationTokens = 3
+
+// classifySegmentTokens splits differing segment into transformations.
+// Groups of tokens with specific transformation (numex, hyphens, etc) are
+// preferred: input tokens are taken one by one, while expansion tokens
+// are tried from the biggest group ("3a" -> "3 a" before "3a" -> "3")
+func classifySegmentTokens(from, to []string) []string {
+	var names []string
+	i, j := 0, 0
+	for i < len(from) && j < len(to) {
+		name, consumedFrom, consumedTo := "", 1, 1
+	search:
+		for in := 1; in <= min(maxTransformationTokens, len(from)-i); in++ {
+			for out := min(maxTransformationTokens, len(to)-j); out >= 1; out-- {
+				candidate := classifySegment(from[i:i+in], to[j:j+out])
+				if candidate != TransformationReplaced && candidate != TransformationAbbreviationExpanded {
+					name, consumedFrom, consumedTo = candidate, in, out
+					break search
+				}
+			}
+		}
+		if name == "" {
+			name = classifySegment(from[i:i+1], to[j:j+1])
+		}
+		names = append(names, name)
+		i += consumedFrom
+		j += consumedTo
+	}
+	// tokens, which were removed or added
+	if i < len(from) || j < len(to) {
+		names = append(names, classifySegment(from[i:], to[j:]))
+	}
+	return names
+}
+
+// classifySegment names transformation of input tokens into expansion tokens
+func classifySegment(from, to []string) string {
+	a, b := strings.Join(from, " "), strings.Join(to, " ")
+
+	switch {
+	case strings.ReplaceAll(a, "-", " ") == b:
+		return TransformationHyphenReplaced
+	case strings.ReplaceAll(a, "-", "") == b:
+		return TransformationHyphenDeleted
+	case strings.ReplaceAll(a, ".", "") == b || strings.ReplaceAll(a, ".", " ") == b:
+		return TransformationPeriodsDeleted
+	case strings.ReplaceAll(a, "'s", "") == b:
+		return TransformationPossessiveDropped
+	case strings.ReplaceAll(a, "'", "") == b:
+		return TransformationApostropheDeleted
+	case alphaNumericSplit.ReplaceAllString(a, "$1$3 $2$4") == b:
+		return TransformationAlphaNumericSplit
+	case len(to) > 0 && numericToken.MatchString(b) && !numericToken.MatchString(a):
+		if romanNumeralToken.MatchString(a) {
+			return TransformationRomanNumeral
+		}
+		return TransformationNumexConverted
+	case len(from) > 0 && len(b) > len(a) && strings.HasPrefix(b, a[:1]):
+		return TransformationAbbreviationExpanded
+	default:
+		return TransformationReplaced
+	}
+}
+
+// limitExpansions truncates expansions by the lowest positive limit
+func limitExpansions(expansions []string, limits ...int) []string {
+	limit := 0
+	for _, l := range limits {
+		if l > 0 && (limit == 0 || l < limit) {
+			limit = l
+		}
+	}
+	if limit > 0 && len(expansions) > limit {
+		return expansions[:limit]
+	}
+	return expansions
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectTransformations(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		expansion string
+		expected  []string
+	}{
+		{
+			name:      "Abbreviation Expanded",
+			input:     "781 Franklin Ave",
+			expansion: "781 franklin avenue",
+			expected:  []string{TransformationLowercased, TransformationAbbreviationExpanded},
+		},
+		{
+			name:      "Numex And Accents",
+			input:     "Quatre-vingt-douze Ave des Champs-Élysées",
+			expansion: "92 avenue des champs elysees",
+			expected: []string{
+				TransformationLowercased,
+				TransformationAccentsStripped,
+				TransformationNumexConverted,
+				TransformationAbbreviationExpanded,
+				TransformationHyphenReplaced,
+			},
+		},
+		{
+			name:      "Hyphen Deleted",
+			input:     "champs-elysees",
+			expansion: "champselysees",
+			expected:  []string{TransformationHyphenDeleted},
+		},
+		{
+			name:      "Roman Numeral",
+			input:     "louis xiv street",
+			expansion: "louis 14 street",
+			expected:  []string{TransformationRomanNumeral},
+		},
+		{
+			name:      "Periods Deleted",
+			input:     "u.s.a.",
+			expansion: "usa",
+			expected:  []string{TransformationPeriodsDeleted},
+		},
+		{
+			name:      "Possessive Dropped",
+			input:     "st james's park",
+			expansion: "saint james park",
+			expected:  []string{TransformationAbbreviationExpanded, TransformationPossessiveDropped},
+		},
+		{
+			name:      "Alpha Numeric Split",
+			input:     "apt 3a",
+			expansion: "apartment 3 a",
+			expected:  []string{TransformationAbbreviationExpanded, TransformationAlphaNumericSplit},
+		},
+		{
+			name:      "Unchanged",
+			input:     "main street",
+			expansion: "main street",
+			expected:  []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, detectTransformations(tt.input, tt.expansion))
+		})
+	}
+}
+
+func TestLimitExpansions(t *testing.T) {
+	expansions := []string{"a", "b", "c"}
+
+	assert.Equal(t, expansions, limitExpansions(expansions))
+	assert.Equal(t, expansions, limitExpansions(expansions, 0, 0))
+	assert.Equal(t, []string{"a", "b"}, limitExpansions(expansions, 2, 0))
+	// the lowest positive limit wins (request param vs server config)
+	assert.Equal(t, []string{"a"}, limitExpansions(expansions, 2, 1))
+	assert.Equal(t, expansions, limitExpansions(expansions, 10))
+}
+
+func TestExpandRouteLimitAndExplain(t *testing.T) {
+	router := SetupRouter()
+	address := url.QueryEscape("Quatre-vingt-douze Ave des Champs-Élysées")
+
+	t.Run("Max Expansions", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/expand?address="+address+"&max_expansions=1", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response []string
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Len(t, response, 1)
+	})
+
+	t.Run("Explain", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/expand?address="+address+"&explain=true", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response []ExplainedExpansion
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.NotEmpty(t, response)
+		assert.Contains(t, response[0].Transformations, TransformationNumexConverted)
+	})
+}
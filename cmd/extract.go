@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/le0pard/postal_server/libpostal"
+	gopostalParser "github.com/openvenues/gopostal/parser"
+)
+
+const (
+	extractDefaultMinConfidence float64 = 0.5
+	extractDefaultMaxTokens     int     = 16
+	extractMaxTokensLimit       int     = 32
+	// word tokens before number, which can start the address ("PO Box 12", "Rue de Rivoli 10")
+	extractLeadingWords int = 3
+	// road with more words is most likely prose, which parser labeled as road
+	extractMaxRoadWords int = 6
+	// max parsed candidate windows per request, text after them is not searched
+	extractMaxWindows int = 2000
+)
+
+// extractWordTypes are token types, which can be part of the address
+var extractWordTypes = map[string]bool{
+	"word":               true,
+	"abbreviation":       true,
+	"ideographic_char":   true,
+	"hangul_syllable":    true,
+	"acronym":            true,
+	"phrase":             true,
+	"numeric":            true,
+	"ordinal":            true,
+	"roman_numeral":      true,
+	"ideographic_number": true,
+}
+
+// extractBreakTypes are token types, which never appear inside the address
+var extractBreakTypes = map[string]bool{
+	"exclamation":   true,
+	"question_mark": true,
+	"colon":         true,
+	"semicolon":     true,
+	"email":         true,
+	"url":           true,
+	"us_phone":      true,
+	"intl_phone":    true,
+}
+
+// extractNoiseLabels are parser labels, which parser uses for words
+// around the address (prose before or after it)
+var extractNoiseLabels = []string{"house", "category", "near"}
+
+// ExtractRequest is free-form text (e.g. email body) with addresses inside
+type ExtractRequest struct {
+	Text     string `json:"text" binding:"required"`
+	Language string `json:"language"`
+	Country  string `json:"country"`
+	// addresses with lower confidence are skipped (0.5 by default)
+	MinConfidence *float64 `json:"min_confidence"`
+	// max tokens in the address (16 by default, 32 at most)
+	MaxTokens int `json:"max_tokens"`
+}
+
+// ExtractedAddress is address found in the text. Start and end are byte
+// offsets in the text
+type ExtractedAddress struct {
+	Text       string                   `json:"text"`
+	Start      int                      `json:"start"`
+	End        int                      `json:"end"`
+	Confidence float64                  `json:"confidence"`
+	Components []ParsedAddressComponent `json:"components"`
+}
+
+// extractCandidate is span of tokens [first, last] with its confidence
+type extractCandidate struct {
+	first, last int
+	confidence  float64
+}
+
+// extractAddresses finds address spans in the text: every token with digits
+// (and few words before it) starts a window, which grows token by token while
+// parsed window scores better. Best windows, which do not overlap, are returned
+// in order of the text. At most extractMaxWindows windows are parsed
+func extractAddresses(ctx context.Context, request ExtractRequest) []ExtractedAddress {
+	minConfidence := extractDefaultMinConfidence
+	if request.MinConfidence != nil {
+		minConfidence = *request.MinConfidence
+	}
+	maxTokens := request.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = extractDefaultMaxTokens
+	}
+	maxTokens = min(maxTokens, extractMaxTokensLimit)
+
+	parserOptions := gopostalParser.ParserOptions{
+		Language: request.Language,
+		Country:  request.Country,
+	}
+	tokens := tokenizeAddress(ctx, request.Text, false)
+
+	var candidates []extractCandidate
+	windows := 0
+	for _, first := range extractionStarts(tokens) {
+		best := extractCandidate{first: first, last: -1}
+		for last := first; last < len(tokens) && last-first < maxTokens && windows < extractMaxWindows; last++ {
+			if extractBreakTypes[tokens[last].Type] {
+				break
+			}
+			if !extractWordTypes[tokens[last].Type] {
+				continue
+			}
+			windows++
+			span := tokenSpanText(request.Text, tokens, first, last)
+			components := toParsedAddressComponents(parseAddress(ctx, span, parserOptions))
+			// same confidence with more tokens is not better
+			if confidence := addressConfidence(components, request.Country); confidence > best.confidence {
+				best.last, best.confidence = last, confidence
+			}
+		}
+		if best.last >= 0 && best.confidence >= minConfidence {
+			candidates = append(candidates, best)
+		}
+	}
+
+	addresses := make([]ExtractedAddress, 0)
+	for _, candidate := range selectExtractCandidates(candidates) {
+		start := tokens[candidate.first].Offset
+		end := tokens[candidate.last].Offset + tokens[candidate.last].Length
+		span := request.Text[start:end]
+		addresses = append(addresses, ExtractedAddress{
+			Text:       span,
+			Start:      start,
+			End:        end,
+			Confidence: candidate.confidence,
+			Components: parseAndResolve(ctx, span, ParseOptions{
+				Parser:           parserOptions,
+				ValidatePostcode: true,
+			}),
+		})
+	}
+	return addresses
+}
+
+// extractionStarts returns indexes of tokens, which can start the address:
+// tokens with digits (house number, postal code) and up to
+// extractLeadingWords word tokens right before them
+func extractionStarts(tokens []libpostal.Token) []int {
+	var starts []int
+	seen := make(map[int]bool)
+	for i, token := range tokens {
+		if !extractWordTypes[token.Type] || !strings.ContainsFunc(token.Token, unicode.IsDigit) {
+			continue
+		}
+		for j := max(0, i-extractLeadingWords); j <= i; j++ {
+			// leading words are consecutive words, so punctuation before the number breaks them
+			if !slices.ContainsFunc(tokens[j:i], func(t libpostal.Token) bool { return !extractWordTypes[t.Type] }) && !seen[j] {
+				seen[j] = true
+				starts = append(starts, j)
+			}
+		}
+	}
+	slices.Sort(starts)
+	return starts
+}
+
+// tokenSpanText returns text from first to last token (inclusive)
+func tokenSpanText(text string, tokens []libpostal.Token, first, last int) string {
+	return text[tokens[first].Offset : tokens[last].Offset+tokens[last].Length]
+}
+
+// addressConfidence scores parsed span from 0 to 1: street with house
+// number (or PO box) and valid postal code weight most, locality, state
+// and country add to them, labels of surrounding prose reduce the score
+func addressConfidence(components []ParsedAddressComponent, country string) float64 {
+	values := componentValues(components)
+
+	var score float64
+	switch {
+	case values["house_number"] != "" && values["road"] != "":
+		score += 0.4
+	case values["po_box"] != "":
+		score += 0.4
+	case values["road"] != "":
+		score += 0.1
+	}
+	if houseNumber := values["house_number"]; houseNumber != "" && !strings.ContainsFunc(houseNumber, unicode.IsDigit) {
+		score -= 0.2
+	}
+	if len(strings.Fields(values["road"])) > extractMaxRoadWords {
+		score -= 0.2
+	}
+
+	if postcode := values["postcode"]; postcode != "" {
+		if country == "" {
+			country = resolveCountryCode(values["country"])
+		}
+		if validatePostcode(postcode, country).Valid {
+			score += 0.25
+		} else {
+			score += 0.05
+		}
+	}
+	for _, labels := range [][]string{
+		{"city", "city_district", "suburb"},
+		{"state", "state_district"},
+		{"country"},
+		unitLabels,
+	} {
+		if slices.ContainsFunc(labels, func(label string) bool { return values[label] != "" }) {
+			score += 0.1
+		}
+	}
+	for _, label := range extractNoiseLabels {
+		if values[label] != "" {
+			score -= 0.1
+		}
+	}
+
+	return math.Round(min(max(score, 0), 1)*100) / 100
+}
+
+// selectExtractCandidates picks candidates with best confidence, which do
+// not overlap with better ones, and returns them in order of the text.
+// On same confidence shorter candidate wins
+func selectExtractCandidates(candidates []extractCandidate) []extractCandidate {
+	candidates = slices.Clone(candidates)
+	slices.SortStableFunc(candidates, func(a, b extractCandidate) int {
+		if c := cmp.Compare(b.confidence, a.confidence); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.last-a.first, b.last-b.first); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.first, b.first)
+	})
+
+	var selected []extractCandidate
+	for _, candidate := range candidates {
+		overlaps := slices.ContainsFunc(selected, func(s extractCandidate) bool {
+			return candidate.first <= s.last && s.first <= candidate.last
+		})
+		if !overlaps {
+			selected = append(selected, candidate)
+		}
+	}
+	slices.SortFunc(selected, func(a, b extractCandidate) int {
+		return cmp.Compare(a.first, b.first)
+	})
+	return selected
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/le0pard/postal_server/libpostal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractionStarts(t *testing.T) {
+	tokens := []libpostal.Token{
+		{Token: "Send", Type: "word"},
+		{Token: "it", Type: "word"},
+		{Token: "to", Type: "word"},
+		{Token: "the", Type: "word"},
+		{Token: "office", Type: "word"},
+		{Token: ",", Type: "comma"},
+		{Token: "781", Type: "numeric"},
+		{Token: "Franklin", Type: "word"},
+		{Token: "Ave", Type: "word"},
+		{Token: "PO", Type: "word"},
+		{Token: "Box", Type: "word"},
+		{Token: "12", Type: "numeric"},
+	}
+	// punctuation breaks leading words, so "office , 781" is not a start
+	assert.Equal(t, []int{6, 8, 9, 10, 11}, extractionStarts(tokens))
+	assert.Empty(t, extractionStarts(tokens[:5]))
+}
+
+func TestAddressConfidence(t *testing.T) {
+	full := []ParsedAddressComponent{
+		{Label: "house_number", Value: "781"},
+		{Label: "road", Value: "franklin ave"},
+		{Label: "city_district", Value: "brooklyn"},
+		{Label: "state", Value: "ny"},
+		{Label: "postcode", Value: "11216"},
+	}
+	assert.Equal(t, 0.85, addressConfidence(full, ""))
+	// invalid postal code of the country adds less
+	assert.Equal(t, 0.65, addressConfidence(full, "GB"))
+
+	assert.Equal(t, 0.4, addressConfidence([]ParsedAddressComponent{
+		{Label: "po_box", Value: "po box 12"},
+	}, ""))
+	// road alone is weak, prose labels reduce the score
+	assert.Equal(t, 0.1, addressConfidence([]ParsedAddressComponent{
+		{Label: "road", Value: "franklin ave"},
+	}, ""))
+	assert.Equal(t, 0.0, addressConfidence([]ParsedAddressComponent{
+		{Label: "house", Value: "please send"},
+		{Label: "road", Value: "it to the office"},
+	}, ""))
+	assert.Equal(t, 0.2, addressConfidence([]ParsedAddressComponent{
+		{Label: "house_number", Value: "781"},
+		{Label: "road", Value: "franklin ave and please call me when you are here"},
+	}, ""))
+}
+
+func TestSelectExtractCandidates(t *testing.T) {
+	assert.Equal(t, []extractCandidate{
+		{first: 2, last: 6, confidence: 0.9},
+		{first: 10, last: 12, confidence: 0.6},
+	}, selectExtractCandidates([]extractCandidate{
+		{first: 10, last: 12, confidence: 0.6},
+		{first: 1, last: 6, confidence: 0.9},
+		{first: 2, last: 6, confidence: 0.9},
+		{first: 5, last: 11, confidence: 0.7},
+	}))
+	assert.Empty(t, selectExtractCandidates(nil))
+}
+
+func TestExtractRoute(t *testing.T) {
+	router := SetupRouter()
+
+	t.Run("Extract Addresses", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		text := "Hi team, please ship the order to 781 Franklin Ave, Brooklyn, NY 11216 before Friday. Thanks!"
+		body, _ := json.Marshal(map[string]string{"text": text})
+		req, _ := http.NewRequest(http.MethodPost, "/extract", strings.NewReader(string(body)))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response []ExtractedAddress
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+
+		assert.Nil(t, err)
+		if assert.Len(t, response, 1) {
+			assert.Equal(t, text[response[0].Start:response[0].End], response[0].Text)
+			assert.True(t, strings.HasPrefix(response[0].Text, "781 Franklin Ave"))
+			assert.Contains(t, response[0].Text, "11216")
+			assert.GreaterOrEqual(t, response[0].Confidence, extractDefaultMinConfidence)
+			assert.Contains(t, response[0].Components, ParsedAddressComponent{Label: "road", Value: "franklin ave"})
+		}
+	})
+
+	t.Run("Text Without Addresses", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/extract", strings.NewReader(`{"text": "Thanks, see you tomorrow!"}`))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[]`, w.Body.String())
+	})
+
+	t.Run("Text Too Long", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.MaxExtractTextLength = 10
+		useConfig(t, cfg)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/extract", strings.NewReader(`{"text": "781 Franklin Ave"}`))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "text is longer than 10 characters")
+	})
+
+	t.Run("Missing Text", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/extract", strings.NewReader(`{}`))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestExtractAddressesMaxWindows(t *testing.T) {
+	// every number starts a window, which grows token by token
+	numbers := make([]string, 0, extractMaxWindows)
+	for i := range extractMaxWindows {
+		numbers = append(numbers, strconv.Itoa(i+1))
+	}
+	stats := &requestStats{}
+	ctx := withRequestStats(context.Background(), stats)
+	noCandidates := 2.0
+	extractAddresses(ctx, ExtractRequest{Text: strings.Join(numbers, " "), MinConfidence: &noCandidates})
+
+	// tokenization and parsed windows
+	assert.Equal(t, 1+extractMaxWindows, stats.calls)
+}
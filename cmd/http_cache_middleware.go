@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/le0pard/postal_server/version"
+)
+
+// cacheETagKey stores ETag of cacheable request in gin context
+const cacheETagKey string = "cache_etag"
+
+// cacheKeyVersion is changed every time format of cache key is changed
+const cacheKeyVersion int = 1
+
+// libpostalVersionFiles are written into data directory by libpostal_data
+// script, when models are downloaded
+var libpostalVersionFiles = []string{
+	"data_version",
+	"base_data_file_version",
+	"parser_model_file_version",
+	"language_classifier_model_file_version",
+}
+
+// libpostalDataDirs are checked, if libpostal_data_dir is not set
+var libpostalDataDirs = []string{
+	"/usr/share/libpostal/libpostal",
+	"/usr/share/libpostal",
+	"/usr/local/share/libpostal",
+}
+
+// parseCacheParams are query parameters, which change /parse response
+var parseCacheParams = []string{
+	"address", "language", "country", "standard",
+	"auto_language", "second_pass", "validate_postcode",
+}
+
+// expandCacheParams are query parameters, which change /expand response
+var expandCacheParams = slices.Concat(
+	[]string{
+		"address", "languages", "auto_language", "max_expansions", "canonical", "canonical_hash", "explain",
+		"latin_ascii", "transliterate", "strip_accents", "lowercase", "trim_string",
+		"replace_word_hyphens", "delete_word_hyphens", "replace_numeric_hyphens", "delete_numeric_hyphens",
+		"split_alpha_from_numeric", "delete_final_periods", "delete_acronym_periods",
+		"drop_english_possessives", "delete_apostrophes", "expand_numex", "roman_numerals",
+	},
+	slices.Sorted(maps.Keys(queryParamToAddressComponent)),
+)
+
+// cacheBoolParams have normalized values, so "1" and "true" have same ETag
+var cacheBoolParams = map[string]bool{
+	"auto_language": true, "second_pass": true, "validate_postcode": true,
+	"canonical": true, "canonical_hash": true, "explain": true,
+}
+
+// readLibpostalDataVersion returns versions of libpostal data files in
+// directory, or in default directories if dir is empty
+func readLibpostalDataVersion(dir string) string {
+	dirs := libpostalDataDirs
+	if dir != "" {
+		dirs = []string{dir}
+	}
+	for _, dir := range dirs {
+		var versions []string
+		for _, name := range libpostalVersionFiles {
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				continue
+			}
+			versions = append(versions, name+"="+strings.TrimSpace(string(data)))
+		}
+		if len(versions) > 0 {
+			return strings.Join(versions, ",")
+		}
+	}
+	return "unknown"
+}
+
+// libpostalDataVersion is read once, libpostal loads models only on start
+var libpostalDataVersion = sync.OnceValue(func() string {
+	return readLibpostalDataVersion(currentConfig().LibpostalDataDir)
+})
+
+// requestETag returns strong ETag of response. It is hash of server and
+// libpostal data versions, route, response encoding and query parameters,
+// which change response (with normalized booleans)
+func requestETag(c *gin.Context, params []string) string {
+	query := c.Request.URL.Query()
+	hash := sha256.New()
+	fmt.Fprintf(hash, "v%d\n%s\n%s\n%s\n%s\n%s\nmax_expansions=%d\n",
+		cacheKeyVersion,
+		version.Version, version.GitCommit,
+		libpostalDataVersion(),
+		c.FullPath(),
+		mediaType(c.NegotiateFormat(responseMediaTypes...)),
+		currentConfig().MaxExpansions,
+	)
+	for _, param := range params {
+		values, ok := query[param]
+		if !ok {
+			continue
+		}
+		if cacheBoolParams[param] {
+			values = []string{strconv.FormatBool(stringToBool(values[0]))}
+		}
+		fmt.Fprintf(hash, "%s=%q\n", param, values)
+	}
+	return `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+// matchETag returns tag from If-None-Match header, which matches ETag.
+// Tag of compressed response has content coding suffix (e.g. "abc-gzip").
+// "*" never matches: it matches only existing representation (RFC 9110),
+// and response is not known before the handler (e.g. it can be 400)
+func matchETag(ifNoneMatch, etag string) (string, bool) {
+	for tag := range strings.SplitSeq(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		// If-None-Match uses weak comparison
+		opaque := strings.TrimPrefix(tag, "W/")
+		if opaque == etag {
+			return tag, true
+		}
+		for _, encoding := range responseEncodings {
+			if opaque == encodedETag(etag, encoding) {
+				return tag, true
+			}
+		}
+	}
+	return "", false
+}
+
+// encodedETag returns ETag of response compressed with content coding
+func encodedETag(etag, encoding string) string {
+	return strings.TrimSuffix(etag, `"`) + "-" + encoding + `"`
+}
+
+// cacheControl returns Cache-Control of cacheable responses. Responses of
+// server with auth are not stored by shared caches (CDN)
+func cacheControl(cfg *Config) string {
+	if cfg.CacheMaxAge == 0 {
+		return "no-cache"
+	}
+	visibility := "public"
+	if cfg.BasicAuthUsername != "" || cfg.BearerAuthToken != "" {
+		visibility = "private"
+	}
+	return fmt.Sprintf("%s, max-age=%d", visibility, cfg.CacheMaxAge)
+}
+
+// setCacheHeaders adds ETag and Cache-Control to successful response of
+// cacheable request
+func setCacheHeaders(c *gin.Context) {
+	etag := c.GetString(cacheETagKey)
+	if etag == "" {
+		return
+	}
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", cacheControl(currentConfig()))
+}
+
+// HTTPCacheMiddleware adds ETag and Cache-Control to deterministic responses
+// and returns 304 Not Modified for If-None-Match with same ETag, before
+// request waits for libpostal. params are query parameters of the route,
+// which change response
+func HTTPCacheMiddleware(params []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		etag := requestETag(c, params)
+		if tag, ok := matchETag(c.GetHeader("If-None-Match"), etag); ok {
+			c.Writer.Header().Add("Vary", "Accept")
+			c.Header("ETag", tag)
+			c.Header("Cache-Control", cacheControl(currentConfig()))
+			c.AbortWithStatus(http.StatusNotModified)
+			return
+		}
+		c.Set(cacheETagKey, etag)
+		c.Next()
+	}
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadLibpostalDataVersion(t *testing.T) {
+	dir := t.TempDir()
+	assert.Equal(t, "unknown", readLibpostalDataVersion(dir))
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "data_version"), []byte("v1\n"), 0o640))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "parser_model_file_version"), []byte("v1.1.0\n"), 0o640))
+	assert.Equal(t, "data_version=v1,parser_model_file_version=v1.1.0", readLibpostalDataVersion(dir))
+}
+
+func TestMatchETag(t *testing.T) {
+	etag := `"abc"`
+	tests := []struct {
+		ifNoneMatch string
+		tag         string
+		ok          bool
+	}{
+		{ifNoneMatch: "", ok: false},
+		{ifNoneMatch: `"abc"`, tag: `"abc"`, ok: true},
+		{ifNoneMatch: `W/"abc"`, tag: `W/"abc"`, ok: true},
+		{ifNoneMatch: `"other", "abc-gzip"`, tag: `"abc-gzip"`, ok: true},
+		{ifNoneMatch: `"abc-deflate"`, ok: false},
+		{ifNoneMatch: `*`, ok: false},
+		{ifNoneMatch: `"ab"`, ok: false},
+	}
+	for _, tt := range tests {
+		tag, ok := matchETag(tt.ifNoneMatch, etag)
+		assert.Equal(t, tt.ok, ok, tt.ifNoneMatch)
+		assert.Equal(t, tt.tag, tag, tt.ifNoneMatch)
+	}
+}
+
+func TestCacheControl(t *testing.T) {
+	assert.Equal(t, "public, max-age=3600", cacheControl(&Config{CacheMaxAge: 3600}))
+	assert.Equal(t, "private, max-age=60", cacheControl(&Config{CacheMaxAge: 60, BearerAuthToken: "token"}))
+	assert.Equal(t, "private, max-age=60", cacheControl(&Config{CacheMaxAge: 60, BasicAuthUsername: "user", BasicAuthPassword: "password"}))
+	assert.Equal(t, "no-cache", cacheControl(&Config{}))
+}
+
+func TestHTTPCacheRoutes(t *testing.T) {
+	useConfig(t, &Config{CacheMaxAge: 3600, CompressionLevel: CompressionLevelNone})
+	router := SetupRouter()
+
+	get := func(target string, headers map[string]string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/parse?address=10+Downing+St&second_pass=1", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, etag)
+	assert.Equal(t, "public, max-age=3600", w.Header().Get("Cache-Control"))
+
+	t.Run("Not Modified", func(t *testing.T) {
+		w := get("/parse?address=10+Downing+St&second_pass=1", map[string]string{"If-None-Match": etag})
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.String())
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+		assert.Equal(t, "public, max-age=3600", w.Header().Get("Cache-Control"))
+	})
+
+	t.Run("Normalized Request", func(t *testing.T) {
+		// order of parameters, booleans and unknown parameters do not change ETag
+		w := get("/parse?second_pass=true&utm_source=mail&address=10+Downing+St", nil)
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+
+		for _, target := range []string{
+			"/parse?address=10+Downing+St",
+			"/parse?address=11+Downing+St&second_pass=1",
+			"/parse?address=10+Downing+St&second_pass=1&language=en",
+			"/expand?address=10+Downing+St&second_pass=1",
+		} {
+			w := get(target, nil)
+			assert.NotEmpty(t, w.Header().Get("ETag"), target)
+			assert.NotEqual(t, etag, w.Header().Get("ETag"), target)
+		}
+
+		w = get("/parse?address=10+Downing+St&second_pass=1", map[string]string{"Accept": MediaTypeMsgPack})
+		assert.NotEqual(t, etag, w.Header().Get("ETag"))
+	})
+
+	t.Run("Error Is Not Cached", func(t *testing.T) {
+		w := get("/parse?address=10+Downing+St&standard=iso", nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Empty(t, w.Header().Get("ETag"))
+		assert.Empty(t, w.Header().Get("Cache-Control"))
+
+		// "*" does not hide error behind 304
+		w = get("/parse?address=10+Downing+St&standard=iso", map[string]string{"If-None-Match": "*"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Other Routes Are Not Cached", func(t *testing.T) {
+		w := get("/postcode?postcode=sw1a2aa&country=gb", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("ETag"))
+	})
+}
+
+func TestHTTPCacheCompressedETag(t *testing.T) {
+	useConfig(t, &Config{CacheMaxAge: 60, CompressionLevel: CompressionLevelDefault, CompressionMinSize: 10})
+	router := gin.New()
+	router.Use(CompressionMiddleware())
+	router.GET("/expand", HTTPCacheMiddleware(expandCacheParams), func(c *gin.Context) {
+		respond(c, http.StatusOK, strings.Repeat("downing street ", 10))
+	})
+
+	get := func(headers map[string]string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/expand?address=10+Downing+St", nil)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	etag := get(nil).Header().Get("ETag")
+	w := get(map[string]string{"Accept-Encoding": "gzip"})
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	gzipETag := w.Header().Get("ETag")
+	assert.Equal(t, encodedETag(etag, EncodingGzip), gzipETag)
+
+	w = get(map[string]string{"Accept-Encoding": "gzip", "If-None-Match": gzipETag})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, gzipETag, w.Header().Get("ETag"))
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+}
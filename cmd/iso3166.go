@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	_ "embed"
+	"encoding/json"
+	"strings"
+	"unicode"
+)
+
+//go:embed data/iso3166.json
+var iso3166Data []byte
+
+// isoCountry is ISO 3166-1 country with its names. Translations are
+// names in other languages, they have lower priority on conflicts
+type isoCountry struct {
+	Alpha2       string   `json:"alpha2"`
+	Alpha3       string   `json:"alpha3"`
+	Names        []string `json:"names"`
+	Translations []string `json:"translations"`
+}
+
+// isoSubdivision is ISO 3166-2 subdivision (e.g. "US-NY"). Parent is set
+// for second level subdivisions (e.g. departments of french regions)
+type isoSubdivision struct {
+	Code         string   `json:"code"`
+	Parent       string   `json:"parent"`
+	Names        []string `json:"names"`
+	Translations []string `json:"translations"`
+}
+
+// isoNameIndex maps normalized names on codes. Name with lower level wins,
+// name with same level and different codes is ambiguous and never resolved
+type isoNameIndex struct {
+	codes  map[string]string
+	levels map[string]int
+}
+
+func newISONameIndex() *isoNameIndex {
+	return &isoNameIndex{
+		codes:  make(map[string]string),
+		levels: make(map[string]int),
+	}
+}
+
+func (idx *isoNameIndex) add(name, code string, level int) {
+	key := normalizeISOName(name)
+	if key == "" {
+		return
+	}
+	existingLevel, ok := idx.levels[key]
+	switch {
+	case !ok || level < existingLevel:
+		idx.codes[key] = code
+		idx.levels[key] = level
+	case level == existingLevel && idx.codes[key] != code:
+		idx.codes[key] = ""
+	}
+}
+
+func (idx *isoNameIndex) lookup(name string) string {
+	return idx.codes[normalizeISOName(name)]
+}
+
+// isoIndexes are indexes of embedded ISO 3166 dataset
+type isoIndexes struct {
+	countries *isoNameIndex
+	// subdivisions of every country by country code
+	subdivisions map[string]*isoNameIndex
+	// subdivisions of all countries, used if country is unknown
+	allSubdivisions *isoNameIndex
+}
+
+var iso3166 = mustLoadISOIndexes(iso3166Data)
+
+// name levels, lower level wins on conflicts
+const (
+	isoLevelName = iota
+	isoLevelAbbreviation
+	isoLevelChildName
+	isoLevelTranslation
+	isoLevelChildTranslation
+)
+
+func mustLoadISOIndexes(data []byte) isoIndexes {
+	var dataset struct {
+		Countries    []isoCountry     `json:"countries"`
+		Subdivisions []isoSubdivision `json:"subdivisions"`
+	}
+	if err := json.Unmarshal(data, &dataset); err != nil {
+		panic("invalid embedded ISO 3166 data: " + err.Error())
+	}
+
+	indexes := isoIndexes{
+		countries:       newISONameIndex(),
+		subdivisions:    make(map[string]*isoNameIndex),
+		allSubdivisions: newISONameIndex(),
+	}
+	for _, country := range dataset.Countries {
+		indexes.countries.add(country.Alpha2, country.Alpha2, isoLevelName)
+		indexes.countries.add(country.Alpha3, country.Alpha2, isoLevelName)
+		for _, name := range country.Names {
+			indexes.countries.add(name, country.Alpha2, isoLevelName)
+		}
+		for _, name := range country.Translations {
+			indexes.countries.add(name, country.Alpha2, isoLevelTranslation)
+		}
+	}
+	for _, subdivision := range dataset.Subdivisions {
+		countryCode, abbreviation, _ := strings.Cut(subdivision.Code, "-")
+		index, ok := indexes.subdivisions[countryCode]
+		if !ok {
+			index = newISONameIndex()
+			indexes.subdivisions[countryCode] = index
+		}
+		add := func(name string, level int) {
+			index.add(name, subdivision.Code, level)
+			indexes.allSubdivisions.add(name, subdivision.Code, level)
+		}
+
+		nameLevel, translationLevel := isoLevelName, isoLevelTranslation
+		if subdivision.Parent != "" {
+			nameLevel, translationLevel = isoLevelChildName, isoLevelChildTranslation
+		} else if isISOAbbreviation(abbreviation) {
+			// postal abbreviations are codes of first level subdivisions ("NY", "NSW")
+			add(abbreviation, isoLevelAbbreviation)
+		}
+		for _, name := range subdivision.Names {
+			add(name, nameLevel)
+		}
+		for _, name := range subdivision.Translations {
+			add(name, translationLevel)
+		}
+	}
+	return indexes
+}
+
+// isISOAbbreviation reports whether subdivision code is letters only
+// (numeric codes like "FR-75" are not used in addresses)
+func isISOAbbreviation(code string) bool {
+	if len(code) < 2 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeISOName lowercases name, strips accents and punctuation,
+// so "U.S.A.", "usa" and "USA" are the same
+func normalizeISOName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r == '.' || r == '\'' || r == '’':
+			return -1
+		case r == '-' || r == ',' || unicode.IsSpace(r):
+			return ' '
+		}
+		return unicode.ToLower(r)
+	}, stripAccents(name))
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// resolveCountryCode returns ISO 3166-1 alpha-2 code of country name,
+// code or abbreviation in any supported language, or empty string
+func resolveCountryCode(name string) string {
+	return iso3166.countries.lookup(name)
+}
+
+// resolveSubdivisionCode returns ISO 3166-2 code of subdivision (e.g. "US-NY")
+// within the country. Without country subdivision is resolved only if
+// name is not ambiguous between countries ("wa" is both US-WA and AU-WA)
+func resolveSubdivisionCode(name, country string) string {
+	if country == "" {
+		return iso3166.allSubdivisions.lookup(name)
+	}
+	if index, ok := iso3166.subdivisions[strings.ToUpper(country)]; ok {
+		return index.lookup(name)
+	}
+	return ""
+}
+
+// annotateISOCodes sets ISO codes of parsed country and state. State is
+// resolved within country (parser option) or parsed country.
+// Returns resolved country code
+func annotateISOCodes(components []ParsedAddressComponent, country string) string {
+	countryCode := resolveCountryCode(country)
+	for i := range components {
+		if components[i].Label != "country" {
+			continue
+		}
+		components[i].CountryCode = resolveCountryCode(components[i].Value)
+		if countryCode == "" {
+			countryCode = components[i].CountryCode
+		}
+	}
+	for i := range components {
+		if components[i].Label == "state" {
+			components[i].StateCode = resolveSubdivisionCode(components[i].Value, countryCode)
+		}
+	}
+	return countryCode
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeISOName(t *testing.T) {
+	assert.Equal(t, "usa", normalizeISOName("U.S.A."))
+	assert.Equal(t, "cote divoire", normalizeISOName("Côte d'Ivoire"))
+	assert.Equal(t, "baden wurttemberg", normalizeISOName(" Baden-Württemberg "))
+}
+
+func TestResolveCountryCode(t *testing.T) {
+	tests := map[string]string{
+		"usa":           "US",
+		"USA":           "US",
+		"us":            "US",
+		"United States": "US",
+		"états-unis":    "US",
+		"uk":            "GB",
+		"Great Britain": "GB",
+		"deutschland":   "DE",
+		"Российская Федерация": "RU",
+		"south korea":     "KR",
+		"bolivia":         "BO",
+		"the netherlands": "NL",
+		"atlantis":        "",
+		"":                "",
+	}
+	for name, code := range tests {
+		assert.Equal(t, code, resolveCountryCode(name), "country %q", name)
+	}
+}
+
+func TestResolveSubdivisionCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		country string
+		code    string
+	}{
+		{name: "ny", country: "us", code: "US-NY"},
+		{name: "New York", country: "US", code: "US-NY"},
+		{name: "new york", code: "US-NY"},
+		{name: "bavaria", country: "de", code: "DE-BY"},
+		{name: "Bayern", code: "DE-BY"},
+		{name: "on", country: "ca", code: "CA-ON"},
+		{name: "nsw", country: "au", code: "AU-NSW"},
+		{name: "wa", country: "au", code: "AU-WA"},
+		// ambiguous without country
+		{name: "wa", code: ""},
+		{name: "ny", country: "ca", code: ""},
+		{name: "ny", country: "zz", code: ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.code, resolveSubdivisionCode(tt.name, tt.country), "subdivision %q in %q", tt.name, tt.country)
+	}
+}
+
+func TestAnnotateISOCodes(t *testing.T) {
+	components := []ParsedAddressComponent{
+		{Label: "road", Value: "franklin ave"},
+		{Label: "state", Value: "ny"},
+		{Label: "country", Value: "usa"},
+	}
+	assert.Equal(t, "US", annotateISOCodes(components, ""))
+	assert.Equal(t, "", components[0].CountryCode)
+	assert.Equal(t, "US-NY", components[1].StateCode)
+	assert.Equal(t, "US", components[2].CountryCode)
+
+	// country option is used for state, if address has no country
+	components = []ParsedAddressComponent{{Label: "state", Value: "wa"}}
+	assert.Equal(t, "AU", annotateISOCodes(components, "au"))
+	assert.Equal(t, "AU-WA", components[0].StateCode)
+}
+
+func TestParseRouteISOCodes(t *testing.T) {
+	router := SetupRouter()
+
+	w := httptest.NewRecorder()
+	address := url.QueryEscape("781 Franklin Ave Crown Heights Brooklyn NY 11216 USA")
+	req, _ := http.NewRequest(http.MethodGet, "/parse?address="+address+"&second_pass=true", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response []ParsedAddressComponent
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+
+	assert.Nil(t, err)
+	components := make(map[string]ParsedAddressComponent)
+	for _, component := range response {
+		components[component.Label] = component
+	}
+	assert.Equal(t, "US", components["country"].CountryCode)
+	assert.Equal(t, "US-NY", components["state"].StateCode)
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	gopostalExpand "github.com/openvenues/gopostal/expand"
+	gopostalParser "github.com/openvenues/gopostal/parser"
+)
+
+// operations of async jobs
+const (
+	JobOperationParse     string = "parse"
+	JobOperationExpand    string = "expand"
+	JobOperationNormalize string = "normalize"
+	JobOperationDedupe    string = "dedupe"
+)
+
+var jobOperations = []string{JobOperationParse, JobOperationExpand, JobOperationNormalize, JobOperationDedupe}
+
+// JobResultRow is output row of parse, expand and normalize jobs. Result is
+// same as response of the endpoint for the address. Columns are other
+// columns of CSV input, which are passed through
+type JobResultRow struct {
+	Row     int               `json:"row"`
+	Address string            `json:"address"`
+	Columns map[string]string `json:"columns,omitempty"`
+	Result  any               `json:"result"`
+}
+
+// recordProcessor returns result of one input address
+type recordProcessor func(ctx context.Context, address string) any
+
+// newRecordProcessor returns processor of job operation. Options are same
+// as query parameters of /parse, /expand and /normalize endpoints
+func newRecordProcessor(operation string, options url.Values) (recordProcessor, error) {
+	standard := options.Get("standard")
+	if _, ok := addressFormatters[standard]; standard != "" && !ok {
+		return nil, fmt.Errorf("unsupported standard %q", standard)
+	}
+	parserOptions := gopostalParser.ParserOptions{
+		Language: options.Get("language"),
+		Country:  options.Get("country"),
+	}
+	expandOptions := mapQueryParamsOnExpandOptions(gopostalExpand.GetDefaultExpansionOptions(), options)
+
+	switch operation {
+	case JobOperationParse:
+		parseOptions := ParseOptions{
+			Parser:           parserOptions,
+			SecondPass:       stringToBool(options.Get("second_pass")),
+			ValidatePostcode: stringToBool(options.Get("validate_postcode")),
+		}
+		return func(ctx context.Context, address string) any {
+			parsed := parseAndResolve(ctx, address, parseOptions)
+			if standard != "" {
+				return addressFormatters[standard](parsed)
+			}
+			return parsed
+		}, nil
+	case JobOperationExpand:
+		maxExpansions, _ := strconv.Atoi(options.Get("max_expansions"))
+		return func(ctx context.Context, address string) any {
+			expansions := expandRanked(ctx, address, expandOptions)
+			return limitExpansions(expansions, maxExpansions, currentConfig().MaxExpansions)
+		}, nil
+	case JobOperationNormalize:
+		maxExpansions, _ := strconv.Atoi(options.Get("max_expansions"))
+		return func(ctx context.Context, address string) any {
+			normalized := normalizeAddress(ctx, address, parserOptions, expandOptions, maxExpansions)
+			if standard != "" {
+				return addressFormatters[standard](canonicalComponents(normalized, parserOptions.Country))
+			}
+			return normalized
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported operation %q, must be one of %s", operation, strings.Join(jobOperations, ", "))
+}
+
+// processRecord returns result row of the address. Every record waits for
+// libpostal slot, same as request
+func processRecord(ctx context.Context, process recordProcessor, row int, address string) (JobResultRow, error) {
+	release, err := acquireLibpostal(ctx)
+	if err != nil {
+		return JobResultRow{}, err
+	}
+	defer release()
+	return JobResultRow{Row: row, Address: address, Result: process(ctx, address)}, nil
+}
+
+// validateJobOptions checks options of the job before input is accepted.
+// Format is required, it is taken from content type of the upload
+func validateJobOptions(operation string, options url.Values) error {
+	if operation == JobOperationDedupe {
+		_, err := dedupeOptionsFromValues(options)
+		return err
+	}
+	if _, err := newRecordProcessor(operation, options); err != nil {
+		return err
+	}
+	if _, ok := recordContentTypes[options.Get("format")]; !ok {
+		return fmt.Errorf("unsupported format %q, must be one of %s, %s", options.Get("format"), RecordFormatCSV, RecordFormatNDJSON)
+	}
+	if _, ok := recordContentTypes[options.Get("output_format")]; !ok && options.Get("output_format") != "" {
+		return fmt.Errorf("unsupported output format %q, must be one of %s, %s", options.Get("output_format"), RecordFormatCSV, RecordFormatNDJSON)
+	}
+	return nil
+}
+
+// jobOutputFormat returns format of job result. Output of parse, expand and
+// normalize jobs is stored as NDJSON, it is converted to CSV on request
+func jobOutputFormat(operation string, options url.Values) string {
+	if operation == JobOperationDedupe {
+		if format := options.Get("output_format"); format != "" {
+			return format
+		}
+		return options.Get("format")
+	}
+	if format := options.Get("output_format"); format != "" {
+		return format
+	}
+	return RecordFormatNDJSON
+}
+
+// dedupeOptionsFromValues returns dedupe options of async job
+func dedupeOptionsFromValues(values url.Values) (DedupeOptions, error) {
+	options := DedupeOptions{
+		Format:       values.Get("format"),
+		OutputFormat: values.Get("output_format"),
+		Columns:      values["column"],
+		Parser: gopostalParser.ParserOptions{
+			Language: values.Get("language"),
+			Country:  values.Get("country"),
+		},
+		Expand: mapQueryParamsOnExpandOptions(gopostalExpand.GetDefaultExpansionOptions(), values),
+	}
+	if options.OutputFormat == "" {
+		options.OutputFormat = options.Format
+	}
+	for _, format := range []string{options.Format, options.OutputFormat} {
+		if _, ok := recordContentTypes[format]; !ok {
+			return options, fmt.Errorf("unsupported format %q, must be one of %s, %s", format, RecordFormatCSV, RecordFormatNDJSON)
+		}
+	}
+	if value := values.Get("threshold"); value != "" {
+		threshold, err := strconv.ParseFloat(value, 64)
+		if err != nil || threshold <= 0 || threshold > 1 {
+			return options, fmt.Errorf("threshold must be number from 0 to 1, got %q", value)
+		}
+		options.Threshold = threshold
+	}
+	if value := values.Get("max_block_size"); value != "" {
+		maxBlockSize, err := strconv.Atoi(value)
+		if err != nil || maxBlockSize <= 0 {
+			return options, fmt.Errorf("max_block_size must be positive integer, got %q", value)
+		}
+		options.MaxBlockSize = maxBlockSize
+	}
+	return options, nil
+}
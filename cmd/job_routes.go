@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// jobQueryParams are query parameters of job upload, which are not options
+// of the job
+var jobQueryParams = []string{"operation", "callback_url"}
+
+// negotiateResultFormat returns result format by Accept header, format of
+// the job is used if Accept header has no supported format
+func negotiateResultFormat(c *gin.Context, format string) string {
+	offered := []string{"application/x-ndjson", "text/csv"}
+	if format == RecordFormatCSV {
+		offered = []string{"text/csv", "application/x-ndjson"}
+	}
+	switch c.NegotiateFormat(offered...) {
+	case "text/csv":
+		return RecordFormatCSV
+	case "application/x-ndjson":
+		return RecordFormatNDJSON
+	}
+	return format
+}
+
+// clearConnectionDeadlines removes read and write deadlines of the request
+// connection. Compression writer is unwrapped by http.ResponseController
+func clearConnectionDeadlines(c *gin.Context) {
+	controller := http.NewResponseController(c.Writer)
+	if err := controller.SetReadDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		requestLogger(c).Warn().Err(err).Msg("Unable to extend read deadline")
+	}
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		requestLogger(c).Warn().Err(err).Msg("Unable to extend write deadline")
+	}
+}
+
+// registerJobRoutes adds async job endpoints. /dedupe endpoints are same as
+// /jobs endpoints for dedupe operation
+func registerJobRoutes(r gin.IRoutes, jobs func() (*jobManager, error)) {
+	registerJobEndpoints(r, "/jobs", "", jobs)
+	registerJobEndpoints(r, "/dedupe", JobOperationDedupe, jobs)
+}
+
+// registerJobEndpoints adds upload, status, result and delete endpoints
+// under the path. If operation is empty, it is taken from query
+func registerJobEndpoints(r gin.IRoutes, path, operation string, jobs func() (*jobManager, error)) {
+	// lookupJob returns job of the path or aborts request
+	lookupJob := func(c *gin.Context) (*jobManager, Job, bool) {
+		manager, err := jobs()
+		if err != nil {
+			requestLogger(c).Error().Err(err).Msg("Unable to open job store")
+			abortWithError(c, http.StatusServiceUnavailable, "jobs are not available")
+			return nil, Job{}, false
+		}
+		job, ok := manager.get(c.Param("id"))
+		if !ok || (operation != "" && job.Operation != operation) {
+			abortWithError(c, http.StatusNotFound, "job not found")
+			return nil, Job{}, false
+		}
+		return manager, job, true
+	}
+
+	// upload file and queue the job
+	r.POST(path, func(c *gin.Context) {
+		manager, err := jobs()
+		if err != nil {
+			requestLogger(c).Error().Err(err).Msg("Unable to open job store")
+			abortWithError(c, http.StatusServiceUnavailable, "jobs are not available")
+			return
+		}
+
+		jobOperation := operation
+		if jobOperation == "" {
+			jobOperation = c.Query("operation")
+		}
+		options := url.Values{}
+		for key, values := range c.Request.URL.Query() {
+			options[key] = values
+		}
+		for _, key := range jobQueryParams {
+			options.Del(key)
+		}
+		if options.Get("format") == "" {
+			format, ok := recordFormatFromContentType(c.ContentType())
+			if !ok {
+				abortWithError(c, http.StatusBadRequest, fmt.Sprintf("unsupported content type %q, use text/csv or application/x-ndjson", c.ContentType()))
+				return
+			}
+			options.Set("format", format)
+		}
+		if err := validateJobOptions(jobOperation, options); err != nil {
+			abortWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		callbackURL := c.Query("callback_url")
+		if callbackURL != "" {
+			if err := validateWebhookURL(callbackURL); err != nil {
+				abortWithError(c, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+
+		// upload of large file can take longer than read and write timeouts of the server
+		clearConnectionDeadlines(c)
+
+		job, replayed, err := manager.create(jobOperation, options, c.Request.Body, c.GetHeader("Idempotency-Key"), callbackURL)
+		switch {
+		case errors.Is(err, ErrIdempotencyKeyReused):
+			abortWithError(c, http.StatusConflict, err.Error())
+			return
+		case errors.As(err, new(*http.MaxBytesError)):
+			abortWithBodyError(c, err)
+			return
+		case err != nil:
+			requestLogger(c).Error().Err(err).Msg("Unable to create job")
+			abortWithError(c, http.StatusInternalServerError, "unable to store input file")
+			return
+		}
+		if replayed {
+			c.Header("Idempotent-Replayed", "true")
+		}
+		c.Header("Location", path+"/"+job.ID)
+		respond(c, http.StatusAccepted, job)
+	})
+
+	// status and progress of the job
+	r.GET(path+"/:id", func(c *gin.Context) {
+		if _, job, ok := lookupJob(c); ok {
+			respond(c, http.StatusOK, job)
+		}
+	})
+
+	// output of finished job
+	r.GET(path+"/:id/result", func(c *gin.Context) {
+		manager, job, ok := lookupJob(c)
+		if !ok {
+			return
+		}
+		switch job.Status {
+		case JobStatusDone:
+		case JobStatusFailed:
+			abortWithError(c, http.StatusConflict, "job failed: "+job.Error)
+			return
+		default:
+			abortWithError(c, http.StatusConflict, "job is not finished")
+			return
+		}
+		// download of large result can take longer than write timeout of the server
+		clearConnectionDeadlines(c)
+		format := jobOutputFormat(job.Operation, job.Options)
+		if job.Operation != JobOperationDedupe {
+			format = negotiateResultFormat(c, format)
+		}
+		if format == RecordFormatNDJSON || job.Operation == JobOperationDedupe {
+			c.Header("Content-Type", recordContentTypes[format])
+			c.File(manager.resultPath(job.ID))
+			return
+		}
+
+		// NDJSON output is converted into CSV with passthrough columns of the input
+		passthrough, err := inputPassthroughColumns(manager.inputPath(job.ID), job.Options)
+		if err != nil {
+			requestLogger(c).Error().Err(err).Msg("Unable to read input header")
+			abortWithError(c, http.StatusInternalServerError, "unable to read job input")
+			return
+		}
+		results, err := os.Open(manager.resultPath(job.ID))
+		if err != nil {
+			requestLogger(c).Error().Err(err).Msg("Unable to open job result")
+			abortWithError(c, http.StatusInternalServerError, "unable to read job result")
+			return
+		}
+		defer results.Close()
+		c.Header("Content-Type", recordContentTypes[RecordFormatCSV])
+		c.Status(http.StatusOK)
+		if err := writeResultsCSV(results, c.Writer, job.Operation, job.Options, passthrough); err != nil {
+			// response is already started, broken CSV is cut
+			requestLogger(c).Error().Err(err).Msg("Unable to convert job result into CSV")
+		}
+	})
+
+	// cancel queued or running job, remove finished one
+	r.DELETE(path+"/:id", func(c *gin.Context) {
+		manager, job, ok := lookupJob(c)
+		if !ok {
+			return
+		}
+		manager.remove(job.ID)
+		c.Status(http.StatusNoContent)
+	})
+}
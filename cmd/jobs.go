@@ -0,0 +1,678 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// statuses of async job
+const (
+	JobStatusQueued  string = "queued"
+	JobStatusRunning string = "running"
+	JobStatusDone    string = "done"
+	JobStatusFailed  string = "failed"
+)
+
+const (
+	// progress and output offset are saved every N records,
+	// job is resumed from last checkpoint after restart
+	jobCheckpointEvery int = 100
+	// finished jobs older than retention are removed with this interval
+	jobCleanupInterval time.Duration = time.Hour
+)
+
+// files of the job directory
+const (
+	jobFile           string = "job.json"
+	jobInputFile      string = "input"
+	jobOutputFile     string = "output"
+	jobCheckpointFile string = "checkpoint.json"
+)
+
+// ErrIdempotencyKeyReused is returned, if Idempotency-Key was used for job
+// with other operation, options or input
+var ErrIdempotencyKeyReused = errors.New("idempotency key is already used for other job")
+
+// Job is async batch job. Job state is stored in job directory,
+// so jobs survive restart of the server
+type Job struct {
+	ID             string      `json:"id"`
+	Operation      string      `json:"operation"`
+	Status         string      `json:"status"`
+	Error          string      `json:"error,omitempty"`
+	Options        url.Values  `json:"options"`
+	Progress       JobProgress `json:"progress"`
+	IdempotencyKey string      `json:"idempotency_key,omitempty"`
+	InputSHA256    string      `json:"input_sha256"`
+	Webhook        *JobWebhook `json:"webhook,omitempty"`
+	CreatedAt      time.Time   `json:"created_at"`
+	StartedAt      *time.Time  `json:"started_at,omitempty"`
+	FinishedAt     *time.Time  `json:"finished_at,omitempty"`
+}
+
+// JobProgress is number of processed input rows. Dedupe jobs report
+// progress of every phase too
+type JobProgress struct {
+	Rows   int             `json:"rows"`
+	Dedupe *DedupeProgress `json:"dedupe,omitempty"`
+}
+
+// jobCheckpoint is position of processed input and written output
+type jobCheckpoint struct {
+	Rows         int   `json:"rows"`
+	OutputOffset int64 `json:"output_offset"`
+}
+
+// finished reports whether job will not be processed anymore
+func (job *Job) finished() bool {
+	return job.Status == JobStatusDone || job.Status == JobStatusFailed
+}
+
+// snapshot returns copy of the job, which is safe to encode while
+// job is processed
+func (job *Job) snapshot() Job {
+	snapshot := *job
+	if job.Webhook != nil {
+		webhook := *job.Webhook
+		webhook.Deliveries = slices.Clone(webhook.Deliveries)
+		snapshot.Webhook = &webhook
+	}
+	return snapshot
+}
+
+// jobManager stores jobs on disk and processes them by pool of workers
+type jobManager struct {
+	dir       string
+	retention time.Duration
+
+	mu          sync.Mutex
+	jobs        map[string]*Job
+	idempotency map[string]string
+	queue       []string
+	cancels     map[string]context.CancelFunc
+	wake        chan struct{}
+
+	webhookClient  *http.Client
+	webhookBackoff time.Duration
+
+	ctx  context.Context
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+}
+
+// newJobManager loads jobs of the directory, queues unfinished ones
+// (they are resumed) and starts workers
+func newJobManager(dir string, workers int, retention time.Duration) (*jobManager, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	ctx, stop := context.WithCancel(log.Logger.WithContext(context.Background()))
+	m := &jobManager{
+		dir:         dir,
+		retention:   retention,
+		jobs:        make(map[string]*Job),
+		idempotency: make(map[string]string),
+		cancels:     make(map[string]context.CancelFunc),
+		wake:        make(chan struct{}, 1),
+		// timeout of every attempt is set by context
+		webhookClient:  newWebhookClient(),
+		webhookBackoff: webhookDefaultBackoff,
+		ctx:            ctx,
+		stop:           stop,
+	}
+	if err := m.load(); err != nil {
+		stop()
+		return nil, err
+	}
+
+	// notifications of jobs, which were finished before restart
+	for id, job := range m.jobs {
+		if job.finished() && job.Webhook != nil && job.Webhook.Status == WebhookStatusPending {
+			m.wg.Go(func() { m.deliverWebhook(id) })
+		}
+	}
+	for range max(workers, 1) {
+		m.wg.Go(m.work)
+	}
+	if retention > 0 {
+		m.wg.Go(m.cleanup)
+	}
+	return m, nil
+}
+
+// load reads jobs of the directory. Directories without valid job file
+// are leftovers of interrupted uploads and are removed
+func (m *jobManager) load() error {
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return err
+	}
+	var unfinished []*Job
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(m.dir, entry.Name(), jobFile))
+		var job Job
+		if err == nil {
+			err = json.Unmarshal(data, &job)
+		}
+		if err != nil || job.ID != entry.Name() {
+			os.RemoveAll(filepath.Join(m.dir, entry.Name()))
+			continue
+		}
+		m.jobs[job.ID] = &job
+		if job.IdempotencyKey != "" {
+			m.idempotency[job.IdempotencyKey] = job.ID
+		}
+		if !job.finished() {
+			unfinished = append(unfinished, &job)
+		}
+	}
+
+	slices.SortFunc(unfinished, func(a, b *Job) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	for _, job := range unfinished {
+		job.Status = JobStatusQueued
+		m.queue = append(m.queue, job.ID)
+	}
+	if len(unfinished) > 0 {
+		log.Info().Int("jobs", len(unfinished)).Msg("Resuming unfinished jobs")
+	}
+	return nil
+}
+
+// shutdown stops workers. Running jobs are interrupted and stay unfinished,
+// so they are resumed on next start
+func (m *jobManager) shutdown() {
+	m.stop()
+	m.wg.Wait()
+}
+
+// create stores input and queues new job. If job with same idempotency key
+// exists, it is returned instead (second value is true). Notification is
+// sent to callbackURL (if it is not empty), when job is finished
+func (m *jobManager) create(operation string, options url.Values, input io.Reader, idempotencyKey, callbackURL string) (Job, bool, error) {
+	if err := validateJobOptions(operation, options); err != nil {
+		return Job{}, false, err
+	}
+	if callbackURL != "" {
+		if err := validateWebhookURL(callbackURL); err != nil {
+			return Job{}, false, err
+		}
+	}
+
+	id := newRequestID()
+	dir := filepath.Join(m.dir, id)
+	if err := os.Mkdir(dir, 0o750); err != nil {
+		return Job{}, false, err
+	}
+	inputHash, err := storeJobInput(filepath.Join(dir, jobInputFile), input)
+	if err != nil {
+		os.RemoveAll(dir)
+		return Job{}, false, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if existingID, ok := m.idempotency[idempotencyKey]; ok && idempotencyKey != "" {
+		os.RemoveAll(dir)
+		existing := m.jobs[existingID]
+		existingCallbackURL := ""
+		if existing.Webhook != nil {
+			existingCallbackURL = existing.Webhook.URL
+		}
+		if existing.Operation != operation || existing.InputSHA256 != inputHash ||
+			existing.Options.Encode() != options.Encode() || existingCallbackURL != callbackURL {
+			return Job{}, false, ErrIdempotencyKeyReused
+		}
+		return existing.snapshot(), true, nil
+	}
+
+	job := &Job{
+		ID:             id,
+		Operation:      operation,
+		Status:         JobStatusQueued,
+		Options:        options,
+		IdempotencyKey: idempotencyKey,
+		InputSHA256:    inputHash,
+		CreatedAt:      time.Now().UTC(),
+	}
+	if callbackURL != "" {
+		job.Webhook = &JobWebhook{URL: callbackURL, Status: WebhookStatusPending}
+	}
+	if err := m.save(job); err != nil {
+		os.RemoveAll(dir)
+		return Job{}, false, err
+	}
+	m.jobs[id] = job
+	if idempotencyKey != "" {
+		m.idempotency[idempotencyKey] = id
+	}
+	m.queue = append(m.queue, id)
+	m.signal()
+	return job.snapshot(), false, nil
+}
+
+// storeJobInput writes input file and returns its SHA-256
+func storeJobInput(path string, input io.Reader) (string, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(file, hash), input)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return hex.EncodeToString(hash.Sum(nil)), err
+}
+
+// get returns copy of the job, which is safe to encode
+func (m *jobManager) get(id string) (Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return job.snapshot(), true
+}
+
+// inputPath returns path of uploaded job input
+func (m *jobManager) inputPath(id string) string {
+	return filepath.Join(m.dir, id, jobInputFile)
+}
+
+// resultPath returns path of job output
+func (m *jobManager) resultPath(id string) string {
+	return filepath.Join(m.dir, id, jobOutputFile)
+}
+
+// remove cancels the job (if it is queued or running) and removes its files.
+// Files of running job are removed by its worker, when it stops writing them
+func (m *jobManager) remove(id string) bool {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	running := false
+	if ok {
+		delete(m.jobs, id)
+		delete(m.idempotency, job.IdempotencyKey)
+		m.queue = slices.DeleteFunc(m.queue, func(queued string) bool { return queued == id })
+		if cancel, ok := m.cancels[id]; ok {
+			cancel()
+			running = true
+		}
+	}
+	m.mu.Unlock()
+
+	if ok && !running {
+		os.RemoveAll(filepath.Join(m.dir, id))
+	}
+	return ok
+}
+
+// save writes job file atomically. Must be called with locked mutex
+func (m *jobManager) save(job *Job) error {
+	return writeJSONFile(filepath.Join(m.dir, job.ID, jobFile), job)
+}
+
+// writeJSONFile writes file through temporary file and rename, so file
+// is never left half written
+func writeJSONFile(path string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o640); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// signal wakes up one waiting worker
+func (m *jobManager) signal() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// next waits for queued job. Returns false on shutdown
+func (m *jobManager) next() (string, bool) {
+	for {
+		m.mu.Lock()
+		if len(m.queue) > 0 {
+			id := m.queue[0]
+			m.queue = m.queue[1:]
+			if len(m.queue) > 0 {
+				m.signal()
+			}
+			m.mu.Unlock()
+			return id, true
+		}
+		m.mu.Unlock()
+
+		select {
+		case <-m.wake:
+		case <-m.ctx.Done():
+			return "", false
+		}
+	}
+}
+
+func (m *jobManager) work() {
+	for {
+		id, ok := m.next()
+		if !ok {
+			return
+		}
+		m.run(id)
+	}
+}
+
+// run processes the job and stores its final status
+func (m *jobManager) run(id string) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(m.ctx)
+	defer cancel()
+	m.cancels[id] = cancel
+	startedAt := time.Now().UTC()
+	job.Status = JobStatusRunning
+	job.StartedAt = &startedAt
+	m.save(job)
+	operation, options := job.Operation, job.Options
+	m.mu.Unlock()
+
+	logger := zerolog.Ctx(ctx).With().Str("job_id", id).Str("operation", operation).Logger()
+	ctx = logger.WithContext(ctx)
+	var err error
+	if operation == JobOperationDedupe {
+		err = m.runDedupe(ctx, id, options)
+	} else {
+		err = m.runRecords(ctx, id, operation, options)
+	}
+
+	m.mu.Lock()
+	delete(m.cancels, id)
+	if _, ok := m.jobs[id]; !ok {
+		// removed while running, files are not written anymore
+		m.mu.Unlock()
+		os.RemoveAll(filepath.Join(m.dir, id))
+		return
+	}
+	defer m.mu.Unlock()
+	if m.ctx.Err() != nil {
+		// interrupted by shutdown, job is resumed on next start
+		logger.Info().Msg("Job interrupted")
+		return
+	}
+
+	finishedAt := time.Now().UTC()
+	job.FinishedAt = &finishedAt
+	if err != nil {
+		job.Status = JobStatusFailed
+		job.Error = err.Error()
+		logger.Error().Err(err).Msg("Job failed")
+	} else {
+		job.Status = JobStatusDone
+		logger.Info().Int("rows", job.Progress.Rows).Msg("Job finished")
+	}
+	if err := m.save(job); err != nil {
+		logger.Error().Err(err).Msg("Unable to save job")
+	}
+	if job.Webhook != nil {
+		m.wg.Go(func() { m.deliverWebhook(id) })
+	}
+}
+
+// updateProgress sets progress of the job and saves it, if persist is true
+func (m *jobManager) updateProgress(id string, progress JobProgress, persist bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if job, ok := m.jobs[id]; ok {
+		job.Progress = progress
+		if persist {
+			m.save(job)
+		}
+	}
+}
+
+// runRecords processes every input record with parse, expand or normalize
+// and writes NDJSON output. Processing starts from the last checkpoint
+func (m *jobManager) runRecords(ctx context.Context, id, operation string, options url.Values) error {
+	process, err := newRecordProcessor(operation, options)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(m.dir, id)
+
+	var checkpoint jobCheckpoint
+	if data, err := os.ReadFile(filepath.Join(dir, jobCheckpointFile)); err == nil {
+		if err := json.Unmarshal(data, &checkpoint); err != nil {
+			return fmt.Errorf("invalid checkpoint: %w", err)
+		}
+	}
+
+	input, err := os.Open(filepath.Join(dir, jobInputFile))
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	reader, err := newRecordReader(input, options.Get("format"), options["column"])
+	if err != nil {
+		return err
+	}
+
+	output, err := os.OpenFile(filepath.Join(dir, jobOutputFile), os.O_CREATE|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+	// records after checkpoint could be written partially before restart
+	if err := output.Truncate(checkpoint.OutputOffset); err != nil {
+		return err
+	}
+	if _, err := output.Seek(checkpoint.OutputOffset, io.SeekStart); err != nil {
+		return err
+	}
+	buffered := bufio.NewWriter(output)
+	counter := &countingWriter{w: buffered}
+	encoder := json.NewEncoder(counter)
+
+	saveCheckpoint := func(rows int) error {
+		if err := buffered.Flush(); err != nil {
+			return err
+		}
+		if err := output.Sync(); err != nil {
+			return err
+		}
+		checkpoint = jobCheckpoint{Rows: rows, OutputOffset: checkpoint.OutputOffset + counter.n}
+		counter.n = 0
+		if err := writeJSONFile(filepath.Join(dir, jobCheckpointFile), checkpoint); err != nil {
+			return err
+		}
+		m.updateProgress(id, JobProgress{Rows: rows}, true)
+		return nil
+	}
+
+	rows := 0
+	for {
+		address, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("row %d: %w", rows+1, err)
+		}
+		rows++
+		if rows <= checkpoint.Rows {
+			continue
+		}
+
+		result, err := processRecord(ctx, process, rows, address)
+		if err != nil {
+			return err
+		}
+		result.Columns = reader.Passthrough()
+		if err := encoder.Encode(result); err != nil {
+			return err
+		}
+		if rows%jobCheckpointEvery == 0 {
+			if err := saveCheckpoint(rows); err != nil {
+				return err
+			}
+		} else {
+			m.updateProgress(id, JobProgress{Rows: rows}, false)
+		}
+	}
+	return saveCheckpoint(rows)
+}
+
+// runDedupe deduplicates input. Dedupe has no checkpoints, interrupted
+// job starts from the beginning
+func (m *jobManager) runDedupe(ctx context.Context, id string, values url.Values) error {
+	options, err := dedupeOptionsFromValues(values)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(m.dir, id)
+	options.TempDir = dir
+	options.Progress = func(progress DedupeProgress) {
+		m.updateProgress(id, JobProgress{Rows: progress.Rows, Dedupe: &progress}, false)
+	}
+
+	input, err := os.Open(filepath.Join(dir, jobInputFile))
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	output, err := os.Create(filepath.Join(dir, jobOutputFile))
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+
+	progress, err := dedupeRecords(ctx, input, output, options)
+	if err != nil {
+		return err
+	}
+	m.updateProgress(id, JobProgress{Rows: progress.Rows, Dedupe: &progress}, true)
+	return output.Sync()
+}
+
+// cleanup removes finished jobs, which are older than retention
+func (m *jobManager) cleanup() {
+	ticker := time.NewTicker(jobCleanupInterval)
+	defer ticker.Stop()
+	for {
+		m.removeExpired(time.Now())
+		select {
+		case <-ticker.C:
+		case <-m.ctx.Done():
+			return
+		}
+	}
+}
+
+func (m *jobManager) removeExpired(now time.Time) {
+	m.mu.Lock()
+	var expired []string
+	for id, job := range m.jobs {
+		if job.finished() && job.FinishedAt != nil && now.Sub(*job.FinishedAt) > m.retention {
+			expired = append(expired, id)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, id := range expired {
+		m.remove(id)
+	}
+}
+
+// countingWriter counts written bytes
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// default job manager of the server, it is created on first use
+var (
+	defaultJobsMu sync.Mutex
+	defaultJobs   *jobManager
+)
+
+// defaultJobsDir is relative to working directory. System temporary
+// directory is not used, it can be cleaned on reboot (or be tmpfs), and
+// interrupted jobs would be lost
+const defaultJobsDir string = "postal_server_jobs"
+
+// jobsDir returns directory of jobs from config
+func jobsDir(cfg *Config) string {
+	if cfg.JobsDir != "" {
+		return cfg.JobsDir
+	}
+	return defaultJobsDir
+}
+
+// defaultJobManager returns job manager of the server (jobs_dir, jobs_workers
+// and jobs_retention_hours settings are applied on first use only)
+func defaultJobManager() (*jobManager, error) {
+	defaultJobsMu.Lock()
+	defer defaultJobsMu.Unlock()
+	if defaultJobs != nil {
+		return defaultJobs, nil
+	}
+
+	cfg := currentConfig()
+	manager, err := newJobManager(jobsDir(cfg), cfg.JobsWorkers, time.Duration(cfg.JobsRetentionHours)*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	defaultJobs = manager
+	return manager, nil
+}
+
+// shutdownDefaultJobManager stops default job manager, if it was started
+func shutdownDefaultJobManager() {
+	defaultJobsMu.Lock()
+	defer defaultJobsMu.Unlock()
+	if defaultJobs != nil {
+		defaultJobs.shutdown()
+		defaultJobs = nil
+	}
+}
@@ -0,0 +1,447 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// useTestJobManager replaces job manager of the server with one, which
+// stores jobs in temporary directory of the test
+func useTestJobManager(t *testing.T) *jobManager {
+	manager, err := newJobManager(t.TempDir(), 1, time.Hour)
+	assert.NoError(t, err)
+
+	defaultJobsMu.Lock()
+	previous := defaultJobs
+	defaultJobs = manager
+	defaultJobsMu.Unlock()
+	t.Cleanup(func() {
+		manager.shutdown()
+		defaultJobsMu.Lock()
+		defaultJobs = previous
+		defaultJobsMu.Unlock()
+	})
+	return manager
+}
+
+// waitForFinishedJob waits until job is done or failed
+func waitForFinishedJob(t *testing.T, manager *jobManager, id string) Job {
+	t.Helper()
+	var job Job
+	assert.Eventually(t, func() bool {
+		job, _ = manager.get(id)
+		return job.finished()
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+// readJobResult returns rows of job output
+func readJobResult(t *testing.T, manager *jobManager, id string) []JobResultRow {
+	t.Helper()
+	data, err := os.ReadFile(manager.resultPath(id))
+	assert.NoError(t, err)
+
+	var rows []JobResultRow
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var row JobResultRow
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &row))
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func TestJobManager(t *testing.T) {
+	ndjson := url.Values{"format": {RecordFormatNDJSON}}
+
+	t.Run("Process Job", func(t *testing.T) {
+		manager := useTestJobManager(t)
+
+		input := "\"781 Franklin Ave Crown Heights Brooklyn NY 11216\"\n{\"address\": \"10 Downing St London\"}\n"
+		job, replayed, err := manager.create(JobOperationExpand, ndjson, strings.NewReader(input), "", "")
+		assert.NoError(t, err)
+		assert.False(t, replayed)
+		assert.Equal(t, JobStatusQueued, job.Status)
+
+		job = waitForFinishedJob(t, manager, job.ID)
+		assert.Equal(t, JobStatusDone, job.Status)
+		assert.Equal(t, 2, job.Progress.Rows)
+		assert.NotNil(t, job.StartedAt)
+		assert.NotNil(t, job.FinishedAt)
+
+		rows := readJobResult(t, manager, job.ID)
+		if !assert.Len(t, rows, 2) {
+			return
+		}
+		assert.Equal(t, 1, rows[0].Row)
+		assert.Equal(t, "781 Franklin Ave Crown Heights Brooklyn NY 11216", rows[0].Address)
+		assert.Equal(t, 2, rows[1].Row)
+		assert.Equal(t, "10 Downing St London", rows[1].Address)
+	})
+
+	t.Run("Failed Job", func(t *testing.T) {
+		manager := useTestJobManager(t)
+
+		options := url.Values{"format": {RecordFormatCSV}, "column": {"full_address"}}
+		job, _, err := manager.create(JobOperationParse, options, strings.NewReader("address\n781 Franklin Ave\n"), "", "")
+		assert.NoError(t, err)
+
+		job = waitForFinishedJob(t, manager, job.ID)
+		assert.Equal(t, JobStatusFailed, job.Status)
+		assert.Contains(t, job.Error, `column "full_address" is not found`)
+	})
+
+	t.Run("Invalid Options", func(t *testing.T) {
+		manager := useTestJobManager(t)
+
+		_, _, err := manager.create("geocode", ndjson, strings.NewReader(""), "", "")
+		assert.ErrorContains(t, err, `unsupported operation "geocode"`)
+
+		_, _, err = manager.create(JobOperationParse, url.Values{"format": {"xml"}}, strings.NewReader(""), "", "")
+		assert.ErrorContains(t, err, `unsupported format "xml"`)
+
+		options := url.Values{"format": {RecordFormatCSV}, "output_format": {"xml"}}
+		_, _, err = manager.create(JobOperationParse, options, strings.NewReader(""), "", "")
+		assert.ErrorContains(t, err, `unsupported output format "xml"`)
+	})
+
+	t.Run("Idempotency Key", func(t *testing.T) {
+		manager := useTestJobManager(t)
+
+		job, replayed, err := manager.create(JobOperationParse, ndjson, strings.NewReader("\"10 Downing St\"\n"), "upload-1", "")
+		assert.NoError(t, err)
+		assert.False(t, replayed)
+
+		replay, replayed, err := manager.create(JobOperationParse, ndjson, strings.NewReader("\"10 Downing St\"\n"), "upload-1", "")
+		assert.NoError(t, err)
+		assert.True(t, replayed)
+		assert.Equal(t, job.ID, replay.ID)
+
+		_, _, err = manager.create(JobOperationParse, ndjson, strings.NewReader("\"11 Downing St\"\n"), "upload-1", "")
+		assert.ErrorIs(t, err, ErrIdempotencyKeyReused)
+
+		_, _, err = manager.create(JobOperationExpand, ndjson, strings.NewReader("\"10 Downing St\"\n"), "upload-1", "")
+		assert.ErrorIs(t, err, ErrIdempotencyKeyReused)
+
+		// only one job directory is left
+		entries, err := os.ReadDir(manager.dir)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("Resume From Checkpoint", func(t *testing.T) {
+		dir := t.TempDir()
+		jobDir := filepath.Join(dir, "interrupted")
+		assert.NoError(t, os.Mkdir(jobDir, 0o750))
+
+		// two rows were processed before restart, third one was written partially
+		processed := `{"row":1,"address":"first","result":"done before restart"}` + "\n" +
+			`{"row":2,"address":"second","result":"done before restart"}` + "\n"
+		assert.NoError(t, os.WriteFile(filepath.Join(jobDir, jobInputFile), []byte("\"first\"\n\"second\"\n\"third\"\n"), 0o640))
+		assert.NoError(t, os.WriteFile(filepath.Join(jobDir, jobOutputFile), []byte(processed+`{"row":3,"addr`), 0o640))
+		assert.NoError(t, writeJSONFile(filepath.Join(jobDir, jobCheckpointFile), jobCheckpoint{Rows: 2, OutputOffset: int64(len(processed))}))
+		assert.NoError(t, writeJSONFile(filepath.Join(jobDir, jobFile), Job{
+			ID:        "interrupted",
+			Operation: JobOperationExpand,
+			Status:    JobStatusRunning,
+			Options:   ndjson,
+			CreatedAt: time.Now().UTC(),
+		}))
+		// upload, which was interrupted before job was saved
+		assert.NoError(t, os.Mkdir(filepath.Join(dir, "partial"), 0o750))
+
+		manager, err := newJobManager(dir, 1, 0)
+		assert.NoError(t, err)
+		defer manager.shutdown()
+
+		job := waitForFinishedJob(t, manager, "interrupted")
+		assert.Equal(t, JobStatusDone, job.Status)
+		assert.Equal(t, 3, job.Progress.Rows)
+
+		rows := readJobResult(t, manager, job.ID)
+		if !assert.Len(t, rows, 3) {
+			return
+		}
+		assert.Equal(t, "done before restart", rows[1].Result)
+		assert.Equal(t, 3, rows[2].Row)
+		assert.Equal(t, "third", rows[2].Address)
+
+		assert.NoDirExists(t, filepath.Join(dir, "partial"))
+	})
+
+	t.Run("Jobs Survive Restart", func(t *testing.T) {
+		dir := t.TempDir()
+		manager, err := newJobManager(dir, 1, 0)
+		assert.NoError(t, err)
+
+		job, _, err := manager.create(JobOperationParse, ndjson, strings.NewReader("\"10 Downing St\"\n"), "upload-1", "")
+		assert.NoError(t, err)
+		waitForFinishedJob(t, manager, job.ID)
+		manager.shutdown()
+
+		manager, err = newJobManager(dir, 1, 0)
+		assert.NoError(t, err)
+		defer manager.shutdown()
+
+		restored, ok := manager.get(job.ID)
+		assert.True(t, ok)
+		assert.Equal(t, JobStatusDone, restored.Status)
+
+		_, replayed, err := manager.create(JobOperationParse, ndjson, strings.NewReader("\"10 Downing St\"\n"), "upload-1", "")
+		assert.NoError(t, err)
+		assert.True(t, replayed)
+	})
+
+	t.Run("Remove Expired", func(t *testing.T) {
+		manager := useTestJobManager(t)
+
+		job, _, err := manager.create(JobOperationParse, ndjson, strings.NewReader("\"10 Downing St\"\n"), "", "")
+		assert.NoError(t, err)
+		waitForFinishedJob(t, manager, job.ID)
+
+		manager.removeExpired(time.Now())
+		_, ok := manager.get(job.ID)
+		assert.True(t, ok)
+
+		manager.removeExpired(time.Now().Add(2 * time.Hour))
+		_, ok = manager.get(job.ID)
+		assert.False(t, ok)
+		assert.NoDirExists(t, filepath.Join(manager.dir, job.ID))
+	})
+
+	t.Run("Remove Running Job", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.MaxConcurrency = 1
+		useConfig(t, cfg)
+		manager := useTestJobManager(t)
+
+		// job waits for libpostal slot, which is held by the test
+		release, err := acquireLibpostal(t.Context())
+		assert.NoError(t, err)
+		defer release()
+
+		job, _, err := manager.create(JobOperationParse, ndjson, strings.NewReader("\"10 Downing St\"\n"), "", "")
+		assert.NoError(t, err)
+		assert.Eventually(t, func() bool {
+			job, _ := manager.get(job.ID)
+			return job.Status == JobStatusRunning
+		}, 5*time.Second, 10*time.Millisecond)
+
+		assert.True(t, manager.remove(job.ID))
+		_, ok := manager.get(job.ID)
+		assert.False(t, ok)
+		// worker removes files after it is stopped
+		assert.Eventually(t, func() bool {
+			_, err := os.Stat(filepath.Join(manager.dir, job.ID))
+			return os.IsNotExist(err)
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestJobRoutes(t *testing.T) {
+	useTestJobManager(t)
+	router := SetupRouter()
+
+	upload := func(target, contentType, body, idempotencyKey string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		if idempotencyKey != "" {
+			req.Header.Set("Idempotency-Key", idempotencyKey)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	waitForJob := func(t *testing.T, id string) Job {
+		t.Helper()
+		var job Job
+		assert.Eventually(t, func() bool {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/jobs/"+id, nil)
+			router.ServeHTTP(w, req)
+			json.Unmarshal(w.Body.Bytes(), &job)
+			return job.finished()
+		}, 5*time.Second, 10*time.Millisecond)
+		return job
+	}
+
+	t.Run("Parse File", func(t *testing.T) {
+		w := upload("/jobs?operation=parse&language=en", "text/csv", "id,address\n1,10 Downing St London\n", "")
+		assert.Equal(t, http.StatusAccepted, w.Code)
+
+		var job Job
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+		assert.Equal(t, "/jobs/"+job.ID, w.Header().Get("Location"))
+		assert.Equal(t, JobOperationParse, job.Operation)
+		assert.Equal(t, url.Values{"format": {"csv"}, "language": {"en"}}, job.Options)
+
+		job = waitForJob(t, job.ID)
+		assert.Equal(t, JobStatusDone, job.Status)
+		assert.Equal(t, 1, job.Progress.Rows)
+
+		w = httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/jobs/"+job.ID+"/result", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), `"address":"10 Downing St London"`)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(http.MethodDelete, "/jobs/"+job.ID, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(http.MethodGet, "/jobs/"+job.ID, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("CSV Result", func(t *testing.T) {
+		input := "id,street,city,segment\n1,10 Downing St,London,gov\n"
+		w := upload("/jobs?operation=expand&column=street&column=city", "text/csv", input, "")
+		assert.Equal(t, http.StatusAccepted, w.Code)
+
+		var job Job
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+		job = waitForJob(t, job.ID)
+		assert.Equal(t, JobStatusDone, job.Status)
+
+		// NDJSON by default, passthrough columns are kept
+		w = httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/jobs/"+job.ID+"/result", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), `"address":"10 Downing St, London","columns":{"id":"1","segment":"gov"}`)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(http.MethodGet, "/jobs/"+job.ID+"/result", nil)
+		req.Header.Set("Accept", "text/csv")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.True(t, strings.HasPrefix(w.Body.String(), "id,segment,address,expansion_1"), w.Body.String())
+		assert.Contains(t, w.Body.String(), "\n1,gov,\"10 Downing St, London\",")
+	})
+
+	t.Run("CSV Output Format", func(t *testing.T) {
+		w := upload("/jobs?operation=expand&output_format=csv&result_prefix=expanded_", "application/x-ndjson", "\"10 Downing St\"\n", "")
+		assert.Equal(t, http.StatusAccepted, w.Code)
+
+		var job Job
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+		waitForJob(t, job.ID)
+
+		w = httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/jobs/"+job.ID+"/result", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.True(t, strings.HasPrefix(w.Body.String(), "address,expanded_expansion_1"), w.Body.String())
+	})
+
+	t.Run("Idempotency Key", func(t *testing.T) {
+		w := upload("/jobs?operation=normalize", "application/x-ndjson", "\"10 Downing St\"\n", "normalize-1")
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Empty(t, w.Header().Get("Idempotent-Replayed"))
+
+		var job Job
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+
+		w = upload("/jobs?operation=normalize", "application/x-ndjson", "\"10 Downing St\"\n", "normalize-1")
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+		assert.Equal(t, "/jobs/"+job.ID, w.Header().Get("Location"))
+
+		w = upload("/jobs?operation=normalize", "application/x-ndjson", "\"11 Downing St\"\n", "normalize-1")
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Dedupe Job Is Not Found Under Other Path", func(t *testing.T) {
+		w := upload("/jobs?operation=parse", "application/x-ndjson", "\"10 Downing St\"\n", "")
+		var job Job
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+
+		w = httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/dedupe/"+job.ID, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Invalid Upload", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			target      string
+			contentType string
+			message     string
+		}{
+			{name: "Unknown Operation", target: "/jobs?operation=geocode", contentType: "text/csv", message: `unsupported operation \"geocode\"`},
+			{name: "Missing Operation", target: "/jobs", contentType: "text/csv", message: `unsupported operation \"\"`},
+			{name: "Unsupported Content Type", target: "/jobs?operation=parse", contentType: "application/json", message: "unsupported content type"},
+			{name: "Unsupported Standard", target: "/jobs?operation=parse&standard=iso", contentType: "text/csv", message: `unsupported standard \"iso\"`},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := upload(tt.target, tt.contentType, "address\n", "")
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				assert.Contains(t, w.Body.String(), tt.message)
+			})
+		}
+	})
+
+	t.Run("Result Of Unknown Job", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/jobs/unknown/result", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestClearConnectionDeadlines(t *testing.T) {
+	useConfig(t, &Config{CompressionLevel: CompressionLevelDefault, CompressionMinSize: 1, MaxDecompressedBodyMB: 1})
+	router := gin.New()
+	router.Use(CompressionMiddleware())
+	router.GET("/slow", func(c *gin.Context) {
+		clearConnectionDeadlines(c)
+		time.Sleep(200 * time.Millisecond)
+		c.String(http.StatusOK, "done")
+	})
+
+	server := httptest.NewUnstartedServer(router)
+	server.Config.WriteTimeout = 50 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	// write deadline is cleared through compression writer too
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/slow", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultClient.Do(req)
+	if assert.NoError(t, err) {
+		defer resp.Body.Close()
+		_, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+	}
+}
+
+func TestJobsDir(t *testing.T) {
+	// default directory must not be in system temporary directory, which is not persistent
+	assert.Equal(t, "postal_server_jobs", jobsDir(&Config{}))
+	assert.False(t, strings.HasPrefix(jobsDir(&Config{}), os.TempDir()))
+	assert.Equal(t, "/var/lib/postal_server/jobs", jobsDir(&Config{JobsDir: "/var/lib/postal_server/jobs"}))
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/le0pard/postal_server/libpostal"
+)
+
+// autoLanguageMinProbability is min probability of detected language,
+// which is used in auto_language mode
+const autoLanguageMinProbability float64 = 0.1
+
+// detectedLanguages returns codes of languages with probability at least
+// autoLanguageMinProbability. The most probable language is always returned,
+// so mixed or short addresses still get a language
+func detectedLanguages(languages []libpostal.Language) []string {
+	codes := make([]string, 0, len(languages))
+	for i, language := range languages {
+		if i == 0 || language.Probability >= autoLanguageMinProbability {
+			codes = append(codes, language.Language)
+		}
+	}
+	return codes
+}
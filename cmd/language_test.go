@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/le0pard/postal_server/libpostal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectedLanguages(t *testing.T) {
+	assert.Equal(t, []string{"fr", "en"}, detectedLanguages([]libpostal.Language{
+		{Language: "fr", Probability: 0.7},
+		{Language: "en", Probability: 0.25},
+		{Language: "de", Probability: 0.05},
+	}))
+	// most probable language is used, even if it has low probability
+	assert.Equal(t, []string{"es"}, detectedLanguages([]libpostal.Language{
+		{Language: "es", Probability: 0.08},
+		{Language: "pt", Probability: 0.07},
+	}))
+	assert.Empty(t, detectedLanguages(nil))
+}
+
+func TestLanguagesRoute(t *testing.T) {
+	router := SetupRouter()
+
+	t.Run("Classify Language", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		address := url.QueryEscape("781 Franklin Ave Crown Heights Brooklyn NY 11216 USA")
+		req, _ := http.NewRequest(http.MethodGet, "/languages?address="+address, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response []libpostal.Language
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+
+		assert.Nil(t, err)
+		if assert.NotEmpty(t, response) {
+			assert.Equal(t, "en", response[0].Language)
+		}
+	})
+
+	t.Run("Auto Language", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		address := url.QueryEscape("781 Franklin Ave Crown Heights Brooklyn NY 11216 USA")
+		req, _ := http.NewRequest(http.MethodGet, "/expand?address="+address+"&auto_language=true", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response []string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+
+		assert.Nil(t, err)
+		assert.Contains(t, response, "781 franklin avenue crown heights brooklyn new york 11216 usa")
+	})
+}
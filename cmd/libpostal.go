@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/le0pard/postal_server/libpostal"
+	gopostalExpand "github.com/openvenues/gopostal/expand"
+	gopostalParser "github.com/openvenues/gopostal/parser"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// expandAddress calls libpostal expansion inside tracing span and records
+// the call in request stats
+func expandAddress(ctx context.Context, address string, options gopostalExpand.ExpandOptions) []string {
+	ctx, span := tracer().Start(ctx, "libpostal.expand", trace.WithAttributes(expandOptionsAttributes(options)...))
+	defer span.End()
+
+	startedAt := time.Now()
+	var expansions []string
+	libpostal.WithLock(func() {
+		expansions = gopostalExpand.ExpandAddressOptions(address, options)
+	})
+	requestStatsFrom(ctx).recordCall(startedAt, address, len(expansions))
+
+	span.SetAttributes(attribute.Int("libpostal.result_count", len(expansions)))
+	zerolog.Ctx(ctx).Debug().
+		Str("address", redactAddress(address)).
+		Int("result_count", len(expansions)).
+		Msg("Address expanded")
+	return expansions
+}
+
+// parseAddress calls libpostal parser inside tracing span and records
+// the call in request stats
+func parseAddress(ctx context.Context, address string, options gopostalParser.ParserOptions) []gopostalParser.ParsedComponent {
+	ctx, span := tracer().Start(ctx, "libpostal.parse", trace.WithAttributes(
+		attribute.String("libpostal.language", options.Language),
+		attribute.String("libpostal.country", options.Country),
+	))
+	defer span.End()
+
+	startedAt := time.Now()
+	var parsed []gopostalParser.ParsedComponent
+	libpostal.WithLock(func() {
+		parsed = gopostalParser.ParseAddressOptions(address, options)
+	})
+	requestStatsFrom(ctx).recordCall(startedAt, address, len(parsed))
+
+	span.SetAttributes(attribute.Int("libpostal.result_count", len(parsed)))
+	zerolog.Ctx(ctx).Debug().
+		Str("address", redactAddress(address)).
+		Int("result_count", len(parsed)).
+		Msg("Address parsed")
+	return parsed
+}
+
+// classifyLanguage calls libpostal language classifier inside tracing span
+// and records the call in request stats
+func classifyLanguage(ctx context.Context, address string) []libpostal.Language {
+	ctx, span := tracer().Start(ctx, "libpostal.classify_language")
+	defer span.End()
+
+	startedAt := time.Now()
+	languages := libpostal.ClassifyLanguage(address)
+	requestStatsFrom(ctx).recordCall(startedAt, address, len(languages))
+
+	span.SetAttributes(attribute.Int("libpostal.result_count", len(languages)))
+	zerolog.Ctx(ctx).Debug().
+		Str("address", redactAddress(address)).
+		Int("result_count", len(languages)).
+		Msg("Address language classified")
+	return languages
+}
+
+// tokenizeAddress calls libpostal tokenizer inside tracing span and records
+// the call in request stats
+func tokenizeAddress(ctx context.Context, address string, whitespace bool) []libpostal.Token {
+	ctx, span := tracer().Start(ctx, "libpostal.tokenize", trace.WithAttributes(
+		attribute.Bool("libpostal.whitespace", whitespace),
+	))
+	defer span.End()
+
+	startedAt := time.Now()
+	tokens := libpostal.Tokenize(address, whitespace)
+	requestStatsFrom(ctx).recordCall(startedAt, address, len(tokens))
+
+	span.SetAttributes(attribute.Int("libpostal.result_count", len(tokens)))
+	zerolog.Ctx(ctx).Debug().
+		Str("address", redactAddress(address)).
+		Int("result_count", len(tokens)).
+		Msg("Address tokenized")
+	return tokens
+}
+
+// normalizeString calls libpostal string normalization inside tracing span
+// and records the call in request stats
+func normalizeString(ctx context.Context, address string, options uint64) string {
+	ctx, span := tracer().Start(ctx, "libpostal.normalize_string", trace.WithAttributes(
+		attribute.Int64("libpostal.string_options", int64(options)),
+	))
+	defer span.End()
+
+	startedAt := time.Now()
+	normalized := libpostal.NormalizeString(address, options)
+	requestStatsFrom(ctx).recordCall(startedAt, address, 1)
+
+	zerolog.Ctx(ctx).Debug().
+		Str("address", redactAddress(address)).
+		Msg("Address string normalized")
+	return normalized
+}
+
+// normalizeTokens calls libpostal token normalization inside tracing span
+// and records the call in request stats
+func normalizeTokens(ctx context.Context, address string, stringOptions, tokenOptions uint64, whitespace bool) []libpostal.NormalizedToken {
+	ctx, span := tracer().Start(ctx, "libpostal.normalized_tokens", trace.WithAttributes(
+		attribute.Int64("libpostal.string_options", int64(stringOptions)),
+		attribute.Int64("libpostal.token_options", int64(tokenOptions)),
+		attribute.Bool("libpostal.whitespace", whitespace),
+	))
+	defer span.End()
+
+	startedAt := time.Now()
+	tokens := libpostal.NormalizedTokens(address, stringOptions, tokenOptions, whitespace)
+	requestStatsFrom(ctx).recordCall(startedAt, address, len(tokens))
+
+	span.SetAttributes(attribute.Int("libpostal.result_count", len(tokens)))
+	zerolog.Ctx(ctx).Debug().
+		Str("address", redactAddress(address)).
+		Int("result_count", len(tokens)).
+		Msg("Address tokens normalized")
+	return tokens
+}
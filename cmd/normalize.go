@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+
+	gopostalExpand "github.com/openvenues/gopostal/expand"
+	gopostalParser "github.com/openvenues/gopostal/parser"
+)
+
+// parserLabelToAddressComponent maps libpostal parser labels on expansion
+// component masks, so each parsed component is expanded only with
+// dictionaries of its own kind (e.g. "st" is "street" for road and "saint" for name)
+var parserLabelToAddressComponent = map[string]uint16{
+	"house":          gopostalExpand.AddressName,
+	"category":       gopostalExpand.AddressCategory,
+	"near":           gopostalExpand.AddressNear,
+	"house_number":   gopostalExpand.AddressHouseNumber,
+	"road":           gopostalExpand.AddressStreet,
+	"unit":           gopostalExpand.AddressUnit,
+	"level":          gopostalExpand.AddressLevel,
+	"staircase":      gopostalExpand.AddressStaircase,
+	"entrance":       gopostalExpand.AddressEntrance,
+	"po_box":         gopostalExpand.AddressPoBox,
+	"postcode":       gopostalExpand.AddressPostalCode,
+	"suburb":         gopostalExpand.AddressToponym,
+	"city_district":  gopostalExpand.AddressToponym,
+	"city":           gopostalExpand.AddressToponym,
+	"island":         gopostalExpand.AddressToponym,
+	"state_district": gopostalExpand.AddressToponym,
+	"state":          gopostalExpand.AddressToponym,
+	"country_region": gopostalExpand.AddressToponym,
+	"country":        gopostalExpand.AddressToponym,
+	"world_region":   gopostalExpand.AddressToponym,
+}
+
+// NormalizedComponent is parsed component with its alternative expansions.
+// ParsedAs and Valid are set only by validation of structured input
+type NormalizedComponent struct {
+	Label      string   `json:"label"`
+	Value      string   `json:"value"`
+	Canonical  string   `json:"canonical"`
+	Expansions []string `json:"expansions"`
+	ParsedAs   []string `json:"parsed_as,omitempty"`
+	Valid      *bool    `json:"valid,omitempty"`
+}
+
+// NormalizedAddress is canonical structured record of the address
+type NormalizedAddress struct {
+	Canonical  map[string]string     `json:"canonical"`
+	Components []NormalizedComponent `json:"components"`
+}
+
+// addressComponentForLabel returns expansion mask for parser label
+func addressComponentForLabel(label string) uint16 {
+	if component, ok := parserLabelToAddressComponent[label]; ok {
+		return component
+	}
+	return gopostalExpand.AddressAny
+}
+
+// normalizeAddress parses address and expands every parsed component with
+// matching component mask. Languages of expandOptions are set from parser
+// language, if they are not provided explicitly. maxExpansions is request
+// limit of expansions per component (0 - only server limit)
+func normalizeAddress(
+	ctx context.Context,
+	address string,
+	parserOptions gopostalParser.ParserOptions,
+	expandOptions gopostalExpand.ExpandOptions,
+	maxExpansions int,
+) NormalizedAddress {
+	if len(expandOptions.Languages) == 0 && parserOptions.Language != "" {
+		expandOptions.Languages = []string{parserOptions.Language}
+	}
+
+	parsed := parseAddress(ctx, address, parserOptions)
+	return normalizeParsedComponents(ctx, parsed, expandOptions, maxExpansions)
+}
+
+// normalizeParsedComponents expands every parsed component with matching
+// component mask. Expansions are ordered by ranking rule, so limit of
+// expansions (max_expansions) keeps best ones
+func normalizeParsedComponents(
+	ctx context.Context,
+	parsed []gopostalParser.ParsedComponent,
+	expandOptions gopostalExpand.ExpandOptions,
+	maxExpansions int,
+) NormalizedAddress {
+	normalized := NormalizedAddress{
+		Canonical:  make(map[string]string, len(parsed)),
+		Components: make([]NormalizedComponent, 0, len(parsed)),
+	}
+
+	for _, component := range parsed {
+		options := expandOptions
+		options.AddressComponents = addressComponentForLabel(component.Label)
+
+		// ranked first, so limit keeps canonical and best expansions
+		expansions := rankExpansions(expandAddress(ctx, component.Value, options), nil)
+		expansions = limitExpansions(expansions, maxExpansions, currentConfig().MaxExpansions)
+
+		canonical := pickCanonical(expansions)
+		if canonical == "" {
+			canonical = component.Value
+		}
+
+		normalized.Components = append(normalized.Components, NormalizedComponent{
+			Label:      component.Label,
+			Value:      component.Value,
+			Canonical:  canonical,
+			Expansions: expansions,
+		})
+
+		// parser can return same label several times (e.g. two house names)
+		if existing, ok := normalized.Canonical[component.Label]; ok {
+			canonical = existing + " " + canonical
+		}
+		normalized.Canonical[component.Label] = canonical
+	}
+
+	return normalized
+}
+
+// canonicalComponents returns parsed components with canonical values
+// and resolved ISO codes
+func canonicalComponents(normalized NormalizedAddress, country string) []ParsedAddressComponent {
+	components := make([]ParsedAddressComponent, 0, len(normalized.Components))
+	for _, component := range normalized.Components {
+		components = append(components, ParsedAddressComponent{
+			Label: component.Label,
+			Value: component.Canonical,
+		})
+	}
+	annotateISOCodes(components, country)
+	return components
+}
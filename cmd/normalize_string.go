@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"net/url"
+
+	"github.com/le0pard/postal_server/libpostal"
+)
+
+// normalizeStringOptions are query params of libpostal string
+// normalization flags. Names are same as expand options, where they exist
+var normalizeStringOptions = map[string]uint64{
+	"latin_ascii":        libpostal.NormalizeStringLatinASCII,
+	"transliterate":      libpostal.NormalizeStringTransliterate,
+	"strip_accents":      libpostal.NormalizeStringStripAccents,
+	"decompose":          libpostal.NormalizeStringDecompose,
+	"lowercase":          libpostal.NormalizeStringLowercase,
+	"trim_string":        libpostal.NormalizeStringTrim,
+	"replace_hyphens":    libpostal.NormalizeStringReplaceHyphens,
+	"compose":            libpostal.NormalizeStringCompose,
+	"simple_latin_ascii": libpostal.NormalizeStringSimpleLatinASCII,
+	"replace_numex":      libpostal.NormalizeStringReplaceNumex,
+}
+
+// normalizeTokenOptions are query params of libpostal token normalization flags
+var normalizeTokenOptions = map[string]uint64{
+	"replace_word_hyphens":          libpostal.NormalizeTokenReplaceHyphens,
+	"delete_word_hyphens":           libpostal.NormalizeTokenDeleteHyphens,
+	"delete_final_periods":          libpostal.NormalizeTokenDeleteFinalPeriod,
+	"delete_acronym_periods":        libpostal.NormalizeTokenDeleteAcronymPeriods,
+	"drop_english_possessives":      libpostal.NormalizeTokenDropEnglishPossessives,
+	"delete_apostrophes":            libpostal.NormalizeTokenDeleteOtherApostrophe,
+	"split_alpha_from_numeric":      libpostal.NormalizeTokenSplitAlphaFromNumeric,
+	"replace_digits":                libpostal.NormalizeTokenReplaceDigits,
+	"replace_numeric_token_letters": libpostal.NormalizeTokenReplaceNumericTokenLetters,
+	"replace_numeric_hyphens":       libpostal.NormalizeTokenReplaceNumericHyphens,
+}
+
+// NormalizedString is result of /normalize_string. Tokens are returned
+// only if tokens are requested
+type NormalizedString struct {
+	Normalized string                      `json:"normalized"`
+	Tokens     []libpostal.NormalizedToken `json:"tokens,omitempty"`
+}
+
+// mapQueryParamsOnNormalizeOptions sets or clears flags of options, which
+// are present in query params (same as mapQueryParamsOnExpandOptions)
+func mapQueryParamsOnNormalizeOptions(options uint64, flags map[string]uint64, queryParams url.Values) uint64 {
+	for name, flag := range flags {
+		val, ok := queryParams[name]
+		if !ok || len(val) == 0 {
+			continue
+		}
+		if stringToBool(val[0]) {
+			options |= flag
+		} else {
+			options &^= flag
+		}
+	}
+	return options
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/le0pard/postal_server/libpostal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapQueryParamsOnNormalizeOptions(t *testing.T) {
+	options := mapQueryParamsOnNormalizeOptions(
+		libpostal.NormalizeDefaultStringOptions,
+		normalizeStringOptions,
+		url.Values{"lowercase": {"false"}, "replace_numex": {"true"}, "unknown": {"true"}},
+	)
+	assert.Zero(t, options&libpostal.NormalizeStringLowercase)
+	assert.NotZero(t, options&libpostal.NormalizeStringReplaceNumex)
+	assert.Equal(t,
+		libpostal.NormalizeDefaultStringOptions&libpostal.NormalizeStringTrim,
+		options&libpostal.NormalizeStringTrim,
+	)
+
+	// options without query params are defaults
+	assert.Equal(t,
+		libpostal.NormalizeDefaultTokenOptions,
+		mapQueryParamsOnNormalizeOptions(libpostal.NormalizeDefaultTokenOptions, normalizeTokenOptions, url.Values{}),
+	)
+}
+
+func TestTokenizeRoute(t *testing.T) {
+	router := SetupRouter()
+
+	t.Run("Tokenize", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		address := url.QueryEscape("781 Franklin Ave")
+		req, _ := http.NewRequest(http.MethodGet, "/tokenize?address="+address, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response []libpostal.Token
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+
+		assert.Nil(t, err)
+		if assert.Len(t, response, 3) {
+			assert.Equal(t, libpostal.Token{Token: "781", Type: "numeric", Offset: 0, Length: 3}, response[0])
+			assert.Equal(t, libpostal.Token{Token: "Ave", Type: "word", Offset: 13, Length: 3}, response[2])
+		}
+	})
+
+	t.Run("Empty Address", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/tokenize", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[]`, w.Body.String())
+	})
+}
+
+func TestNormalizeStringRoute(t *testing.T) {
+	router := SetupRouter()
+
+	t.Run("Normalize String", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		address := url.QueryEscape("781 Franklin Ave")
+		req, _ := http.NewRequest(http.MethodGet, "/normalize_string?address="+address, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"normalized":"781 franklin ave"}`, w.Body.String())
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		address := url.QueryEscape("781 Franklin Ave")
+		req, _ := http.NewRequest(http.MethodGet, "/normalize_string?lowercase=false&address="+address, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"normalized":"781 Franklin Ave"}`, w.Body.String())
+	})
+
+	t.Run("Tokens", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		address := url.QueryEscape("781 Franklin Ave")
+		req, _ := http.NewRequest(http.MethodGet, "/normalize_string?tokens=true&address="+address, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response NormalizedString
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+
+		assert.Nil(t, err)
+		if assert.Len(t, response.Tokens, 3) {
+			assert.Equal(t, "franklin", response.Tokens[1].Normalized)
+			assert.Equal(t, 4, response.Tokens[1].Offset)
+			assert.Equal(t, 8, response.Tokens[1].Length)
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+
+	gopostalExpand "github.com/openvenues/gopostal/expand"
+	gopostalParser "github.com/openvenues/gopostal/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddressComponentForLabel(t *testing.T) {
+	assert.Equal(t, uint16(gopostalExpand.AddressStreet), addressComponentForLabel("road"))
+	assert.Equal(t, uint16(gopostalExpand.AddressHouseNumber), addressComponentForLabel("house_number"))
+	assert.Equal(t, uint16(gopostalExpand.AddressPostalCode), addressComponentForLabel("postcode"))
+	assert.Equal(t, uint16(gopostalExpand.AddressToponym), addressComponentForLabel("city"))
+	// unknown labels are expanded with every dictionary
+	assert.Equal(t, uint16(gopostalExpand.AddressAny), addressComponentForLabel("unknown_label"))
+}
+
+func TestNormalizeRoute(t *testing.T) {
+	router := SetupRouter()
+
+	t.Run("Basic Normalization", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		address := url.QueryEscape("781 Franklin Ave Crown Heights Brooklyn NY 11216 USA")
+		req, _ := http.NewRequest(http.MethodGet, "/normalize?address="+address+"&language=en", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response NormalizedAddress
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "781", response.Canonical["house_number"])
+		assert.Equal(t, "11216", response.Canonical["postcode"])
+
+		components := make(map[string]NormalizedComponent)
+		for _, component := range response.Components {
+			components[component.Label] = component
+		}
+		assert.Equal(t, "franklin ave", components["road"].Value)
+		// road is expanded with street dictionaries
+		assert.Contains(t, components["road"].Expansions, "franklin avenue")
+	})
+
+	t.Run("Empty Address", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/normalize?address=", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response NormalizedAddress
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+
+		assert.Nil(t, err)
+		assert.Empty(t, response.Canonical)
+		assert.Empty(t, response.Components)
+	})
+}
+
+func TestNormalizeParsedComponentsMaxExpansions(t *testing.T) {
+	parsed := []gopostalParser.ParsedComponent{
+		{Label: "road", Value: "Saint Marks St"},
+		{Label: "city", Value: "St Louis"},
+	}
+	assertLimited := func(t *testing.T, normalized NormalizedAddress) {
+		if assert.Len(t, normalized.Components, 2) {
+			for _, component := range normalized.Components {
+				assert.LessOrEqual(t, len(component.Expansions), 1, component.Label)
+				// canonical value is kept by the limit
+				if assert.NotEmpty(t, component.Expansions, component.Label) {
+					assert.Equal(t, component.Expansions[0], component.Canonical)
+				}
+			}
+		}
+	}
+
+	t.Run("Request Limit", func(t *testing.T) {
+		useConfig(t, validConfig())
+		assertLimited(t, normalizeParsedComponents(context.Background(), parsed, gopostalExpand.GetDefaultExpansionOptions(), 1))
+	})
+
+	t.Run("Server Limit", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.MaxExpansions = 1
+		useConfig(t, cfg)
+		assertLimited(t, normalizeParsedComponents(context.Background(), parsed, gopostalExpand.GetDefaultExpansionOptions(), 0))
+	})
+
+	t.Run("Ranked Expansions", func(t *testing.T) {
+		useConfig(t, validConfig())
+		normalized := normalizeParsedComponents(context.Background(), parsed, gopostalExpand.GetDefaultExpansionOptions(), 0)
+		for _, component := range normalized.Components {
+			assert.True(t, slices.IsSortedFunc(component.Expansions, compareExpansions), component.Label)
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+
+	gopostalParser "github.com/openvenues/gopostal/parser"
+)
+
+// ParsedAddressComponent is libpostal parsed component with resolved
+// ISO codes (for country and state) and optional validation results.
+// ParsedAs is set only by validation of structured input
+type ParsedAddressComponent struct {
+	Label       string   `json:"label"`
+	Value       string   `json:"value"`
+	CountryCode string   `json:"country_code,omitempty"`
+	StateCode   string   `json:"state_code,omitempty"`
+	Canonical   string   `json:"canonical,omitempty"`
+	Valid       *bool    `json:"valid,omitempty"`
+	ParsedAs    []string `json:"parsed_as,omitempty"`
+}
+
+// ParseOptions are /parse options on top of libpostal parser options
+type ParseOptions struct {
+	Parser gopostalParser.ParserOptions
+	// parse again with resolved country, if country is not provided
+	SecondPass       bool
+	ValidatePostcode bool
+}
+
+// parseAndResolve parses address and resolves ISO codes of parsed components
+func parseAndResolve(ctx context.Context, address string, options ParseOptions) []ParsedAddressComponent {
+	parsed := toParsedAddressComponents(parseAddress(ctx, address, options.Parser))
+	countryCode := annotateISOCodes(parsed, options.Parser.Country)
+
+	if options.SecondPass && options.Parser.Country == "" && countryCode != "" {
+		// libpostal parser is more accurate with known country
+		parserOptions := options.Parser
+		parserOptions.Country = strings.ToLower(countryCode)
+		parsed = toParsedAddressComponents(parseAddress(ctx, address, parserOptions))
+		annotateISOCodes(parsed, parserOptions.Country)
+	}
+
+	if options.ValidatePostcode {
+		annotatePostcodes(parsed, countryCode)
+	}
+	return parsed
+}
+
+// toParsedAddressComponents converts libpostal components
+func toParsedAddressComponents(parsed []gopostalParser.ParsedComponent) []ParsedAddressComponent {
+	components := make([]ParsedAddressComponent, 0, len(parsed))
+	for _, component := range parsed {
+		components = append(components, ParsedAddressComponent{
+			Label: component.Label,
+			Value: component.Value,
+		})
+	}
+	return components
+}
+
+// componentValues returns parsed values by label. Values of repeated
+// labels are joined with space, same as in normalized canonical record
+func componentValues(components []ParsedAddressComponent) map[string]string {
+	values := make(map[string]string, len(components))
+	for _, component := range components {
+		if existing, ok := values[component.Label]; ok {
+			values[component.Label] = existing + " " + component.Value
+		} else {
+			values[component.Label] = component.Value
+		}
+	}
+	return values
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	_ "embed"
+	"encoding/json"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+//go:embed data/postcodes.json
+var postcodesData []byte
+
+// postcodeFormat is one valid postal code form of the country. Pattern is
+// matched against compacted postal code (uppercase, without spaces and hyphens),
+// format is regexp template of canonical form
+type postcodeFormat struct {
+	Pattern string `json:"pattern"`
+	Format  string `json:"format"`
+	regexp  *regexp.Regexp
+}
+
+// postcodeCountry is set of postal code formats of the country
+type postcodeCountry struct {
+	Formats  []postcodeFormat `json:"formats"`
+	Examples []string         `json:"examples"`
+}
+
+// postcodeCountries are embedded postal code formats by ISO 3166-1 alpha-2 code
+var postcodeCountries = mustLoadPostcodeCountries(postcodesData)
+
+// PostcodeResult is validation result of postal code
+type PostcodeResult struct {
+	Postcode  string   `json:"postcode"`
+	Country   string   `json:"country,omitempty"`
+	Supported bool     `json:"supported"`
+	Valid     bool     `json:"valid"`
+	Canonical string   `json:"canonical,omitempty"`
+	Countries []string `json:"countries,omitempty"`
+}
+
+func mustLoadPostcodeCountries(data []byte) map[string]postcodeCountry {
+	var countries map[string]postcodeCountry
+	if err := json.Unmarshal(data, &countries); err != nil {
+		panic("invalid embedded postal codes data: " + err.Error())
+	}
+	for code, country := range countries {
+		for i := range country.Formats {
+			country.Formats[i].regexp = regexp.MustCompile(country.Formats[i].Pattern)
+		}
+		countries[code] = country
+	}
+	return countries
+}
+
+// compactPostcode uppercases postal code and removes spaces and hyphens,
+// so "sw1a-1aa", "SW1A 1AA" and "sw1a1aa" are the same
+func compactPostcode(postcode string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '-' {
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, postcode)
+}
+
+// canonicalPostcode returns canonical form of postal code for the country.
+// Second value is false, if postal code is not valid for the country
+// (or country is not supported)
+func canonicalPostcode(postcode, country string) (string, bool) {
+	compact := compactPostcode(postcode)
+	for _, format := range postcodeCountries[strings.ToUpper(country)].Formats {
+		if match := format.regexp.FindStringSubmatchIndex(compact); match != nil {
+			return string(format.regexp.ExpandString(nil, format.Format, compact, match)), true
+		}
+	}
+	return "", false
+}
+
+// validatePostcode validates postal code for the country. Without country
+// postal code is checked against every supported country, and canonical
+// form is returned only if it is same for all matching countries
+func validatePostcode(postcode, country string) PostcodeResult {
+	result := PostcodeResult{Postcode: postcode}
+	if country != "" {
+		result.Country = strings.ToUpper(country)
+		_, result.Supported = postcodeCountries[result.Country]
+		result.Canonical, result.Valid = canonicalPostcode(postcode, result.Country)
+		return result
+	}
+
+	result.Supported = true
+	for _, code := range slices.Sorted(maps.Keys(postcodeCountries)) {
+		canonical, ok := canonicalPostcode(postcode, code)
+		if !ok {
+			continue
+		}
+		if len(result.Countries) == 0 {
+			result.Canonical = canonical
+		} else if result.Canonical != canonical {
+			// ambiguous, e.g. "12345" is "12345" in DE, but "123 45" in SE
+			result.Canonical = ""
+		}
+		result.Countries = append(result.Countries, code)
+	}
+	result.Valid = len(result.Countries) > 0
+	return result
+}
+
+// annotatePostcodes sets canonical form and validity of parsed postal codes.
+// Postal codes of not supported countries are left without flags
+func annotatePostcodes(components []ParsedAddressComponent, country string) {
+	for i := range components {
+		if components[i].Label != "postcode" {
+			continue
+		}
+		result := validatePostcode(components[i].Value, country)
+		if !result.Supported {
+			continue
+		}
+		valid := result.Valid
+		components[i].Valid = &valid
+		components[i].Canonical = result.Canonical
+	}
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostcodeExamples(t *testing.T) {
+	for code, country := range postcodeCountries {
+		assert.NotEmpty(t, country.Examples, "country %s must have examples", code)
+		for _, example := range country.Examples {
+			canonical, ok := canonicalPostcode(example, code)
+			assert.True(t, ok, "%s example %q must be valid", code, example)
+			// examples are written in canonical form
+			assert.Equal(t, example, canonical, "%s example %q", code, example)
+		}
+	}
+}
+
+func TestCanonicalPostcode(t *testing.T) {
+	tests := []struct {
+		name      string
+		postcode  string
+		country   string
+		canonical string
+		valid     bool
+	}{
+		{name: "UK Without Space", postcode: "sw1a1aa", country: "gb", canonical: "SW1A 1AA", valid: true},
+		{name: "UK Extra Spaces", postcode: " ec1a  1bb ", country: "GB", canonical: "EC1A 1BB", valid: true},
+		{name: "UK Short Outward", postcode: "m1 1ae", country: "gb", canonical: "M1 1AE", valid: true},
+		{name: "UK Invalid Inward", postcode: "sw1a 1ac", country: "gb", valid: false},
+		{name: "US ZIP", postcode: "11216", country: "us", canonical: "11216", valid: true},
+		{name: "US ZIP+4", postcode: "11216 1234", country: "us", canonical: "11216-1234", valid: true},
+		{name: "US Too Short", postcode: "1121", country: "us", valid: false},
+		{name: "Canada", postcode: "k1a0b1", country: "ca", canonical: "K1A 0B1", valid: true},
+		{name: "Canada Invalid Letter", postcode: "d1a 0b1", country: "ca", valid: false},
+		{name: "Netherlands", postcode: "1012ab", country: "nl", canonical: "1012 AB", valid: true},
+		{name: "Netherlands Reserved Letters", postcode: "1012 ss", country: "nl", valid: false},
+		{name: "Poland", postcode: "00950", country: "pl", canonical: "00-950", valid: true},
+		{name: "Not Supported Country", postcode: "12345", country: "zz", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			canonical, valid := canonicalPostcode(tt.postcode, tt.country)
+			assert.Equal(t, tt.valid, valid)
+			assert.Equal(t, tt.canonical, canonical)
+		})
+	}
+}
+
+func TestValidatePostcode(t *testing.T) {
+	t.Run("With Country", func(t *testing.T) {
+		result := validatePostcode("sw1a1aa", "gb")
+		assert.Equal(t, PostcodeResult{
+			Postcode:  "sw1a1aa",
+			Country:   "GB",
+			Supported: true,
+			Valid:     true,
+			Canonical: "SW1A 1AA",
+		}, result)
+	})
+
+	t.Run("Not Supported Country", func(t *testing.T) {
+		result := validatePostcode("12345", "zz")
+		assert.False(t, result.Supported)
+		assert.False(t, result.Valid)
+	})
+
+	t.Run("Without Country", func(t *testing.T) {
+		result := validatePostcode("k1a 0b1", "")
+		assert.True(t, result.Valid)
+		assert.Equal(t, []string{"CA"}, result.Countries)
+		assert.Equal(t, "K1A 0B1", result.Canonical)
+	})
+
+	t.Run("Without Country Ambiguous", func(t *testing.T) {
+		result := validatePostcode("11452", "")
+		assert.True(t, result.Valid)
+		assert.Contains(t, result.Countries, "DE")
+		assert.Contains(t, result.Countries, "SE")
+		assert.Empty(t, result.Canonical)
+	})
+}
+
+func TestAnnotatePostcodes(t *testing.T) {
+	components := []ParsedAddressComponent{
+		{Label: "road", Value: "franklin ave"},
+		{Label: "postcode", Value: "1121"},
+	}
+	annotatePostcodes(components, "us")
+
+	assert.Nil(t, components[0].Valid)
+	if assert.NotNil(t, components[1].Valid) {
+		assert.False(t, *components[1].Valid)
+	}
+
+	components = []ParsedAddressComponent{{Label: "postcode", Value: "12345"}}
+	annotatePostcodes(components, "zz")
+	// validity is unknown for not supported countries
+	assert.Nil(t, components[0].Valid)
+}
+
+func TestPostcodeRoute(t *testing.T) {
+	router := SetupRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/postcode?postcode=sw1a1aa&country=gb", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response PostcodeResult
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+
+	assert.Nil(t, err)
+	assert.True(t, response.Valid)
+	assert.Equal(t, "SW1A 1AA", response.Canonical)
+}
+
+func TestParseRouteValidatePostcode(t *testing.T) {
+	router := SetupRouter()
+
+	w := httptest.NewRecorder()
+	address := url.QueryEscape("781 Franklin Ave Crown Heights Brooklyn NY 11216 USA")
+	req, _ := http.NewRequest(http.MethodGet, "/parse?address="+address+"&country=us&validate_postcode=true", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response []ParsedAddressComponent
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+
+	assert.Nil(t, err)
+	for _, component := range response {
+		if component.Label == "postcode" {
+			assert.Equal(t, "11216", component.Canonical)
+			if assert.NotNil(t, component.Valid) {
+				assert.True(t, *component.Valid)
+			}
+		} else {
+			assert.Nil(t, component.Valid)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// formats of batch input and output files
+const (
+	RecordFormatCSV    string = "csv"
+	RecordFormatNDJSON string = "ndjson"
+)
+
+// defaultRecordColumn is CSV column (or NDJSON field) with the address
+const defaultRecordColumn string = "address"
+
+// recordAddressSeparator joins values of address columns, if address is
+// split into several columns (e.g. street, city and zip)
+const recordAddressSeparator string = ", "
+
+// recordReader reads addresses of batch input one by one
+type recordReader interface {
+	// Read returns address of the next record or io.EOF at the end of input
+	Read() (string, error)
+	// PassthroughColumns returns names of CSV columns, which are not address
+	// columns, in order of the header
+	PassthroughColumns() []string
+	// Passthrough returns values of passthrough columns of the last record
+	Passthrough() map[string]string
+}
+
+// newRecordReader returns reader of CSV (with header) or NDJSON input.
+// Address is taken from the columns of CSV or from the fields of NDJSON
+// object (non-empty values of several columns are joined), NDJSON line
+// can be JSON string with the address too
+func newRecordReader(r io.Reader, format string, columns []string) (recordReader, error) {
+	columns = slices.DeleteFunc(slices.Clone(columns), func(column string) bool { return column == "" })
+	if len(columns) == 0 {
+		columns = []string{defaultRecordColumn}
+	}
+	switch format {
+	case RecordFormatCSV:
+		return newCSVRecordReader(r, columns)
+	case RecordFormatNDJSON:
+		return &ndjsonRecordReader{decoder: json.NewDecoder(r), columns: columns}, nil
+	}
+	return nil, fmt.Errorf("unsupported format %q, must be one of %s, %s", format, RecordFormatCSV, RecordFormatNDJSON)
+}
+
+// recordFormatFromPath returns format by file extension, CSV by default
+func recordFormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return RecordFormatNDJSON
+	}
+	return RecordFormatCSV
+}
+
+// recordFormatFromContentType returns format by request content type
+func recordFormatFromContentType(contentType string) (string, bool) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return RecordFormatCSV, true
+	case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/jsonlines":
+		return RecordFormatNDJSON, true
+	}
+	return "", false
+}
+
+// recordContentTypes are content types of output formats
+var recordContentTypes = map[string]string{
+	RecordFormatCSV:    "text/csv; charset=utf-8",
+	RecordFormatNDJSON: "application/x-ndjson",
+}
+
+// joinAddressValues joins non-empty values of address columns
+func joinAddressValues(values []string) string {
+	if len(values) == 1 {
+		return values[0]
+	}
+	parts := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, recordAddressSeparator)
+}
+
+type csvRecordReader struct {
+	reader *csv.Reader
+	// indexes of address columns in order of the columns option
+	indexes []int
+	// names and indexes of other columns
+	passthrough        []string
+	passthroughIndexes []int
+	record             []string
+}
+
+func newCSVRecordReader(r io.Reader, columns []string) (*csvRecordReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("CSV input has no header")
+	}
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(header))
+	for i, name := range header {
+		// BOM of files exported from spreadsheets
+		names[i] = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+	}
+
+	records := &csvRecordReader{reader: reader}
+	for _, column := range columns {
+		index := slices.Index(names, column)
+		if index < 0 {
+			return nil, fmt.Errorf("column %q is not found in CSV header", column)
+		}
+		records.indexes = append(records.indexes, index)
+	}
+	for i, name := range names {
+		if !slices.Contains(records.indexes, i) {
+			records.passthrough = append(records.passthrough, name)
+			records.passthroughIndexes = append(records.passthroughIndexes, i)
+		}
+	}
+	return records, nil
+}
+
+func (r *csvRecordReader) Read() (string, error) {
+	record, err := r.reader.Read()
+	if err != nil {
+		r.record = nil
+		return "", err
+	}
+	r.record = record
+	values := make([]string, len(r.indexes))
+	for i, index := range r.indexes {
+		values[i] = r.field(index)
+	}
+	return joinAddressValues(values), nil
+}
+
+// field returns value of the column, short rows have empty values
+func (r *csvRecordReader) field(index int) string {
+	if index >= len(r.record) {
+		return ""
+	}
+	return r.record[index]
+}
+
+func (r *csvRecordReader) PassthroughColumns() []string {
+	return r.passthrough
+}
+
+func (r *csvRecordReader) Passthrough() map[string]string {
+	if len(r.passthrough) == 0 || r.record == nil {
+		return nil
+	}
+	values := make(map[string]string, len(r.passthrough))
+	for i, name := range r.passthrough {
+		values[name] = r.field(r.passthroughIndexes[i])
+	}
+	return values
+}
+
+type ndjsonRecordReader struct {
+	decoder *json.Decoder
+	columns []string
+	line    int
+}
+
+// PassthroughColumns is empty for NDJSON, results have only address
+func (r *ndjsonRecordReader) PassthroughColumns() []string {
+	return nil
+}
+
+func (r *ndjsonRecordReader) Passthrough() map[string]string {
+	return nil
+}
+
+func (r *ndjsonRecordReader) Read() (string, error) {
+	var raw json.RawMessage
+	if err := r.decoder.Decode(&raw); err != nil {
+		if errors.Is(err, io.EOF) {
+			return "", io.EOF
+		}
+		return "", fmt.Errorf("record %d: %w", r.line+1, err)
+	}
+	r.line++
+
+	var address string
+	if err := json.Unmarshal(raw, &address); err == nil {
+		return address, nil
+	}
+	var record map[string]any
+	if err := json.Unmarshal(raw, &record); err != nil {
+		return "", fmt.Errorf("record %d: must be JSON object or string", r.line)
+	}
+	values := make([]string, len(r.columns))
+	for i, column := range r.columns {
+		switch value := record[column].(type) {
+		case nil:
+		case string:
+			values[i] = value
+		default:
+			return "", fmt.Errorf("record %d: field %q must be string", r.line, column)
+		}
+	}
+	return joinAddressValues(values), nil
+}
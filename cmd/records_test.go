@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readAllRecords(t *testing.T, reader recordReader) ([]string, error) {
+	t.Helper()
+	var addresses []string
+	for {
+		address, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return addresses, nil
+		}
+		if err != nil {
+			return addresses, err
+		}
+		addresses = append(addresses, address)
+	}
+}
+
+func TestCSVRecordReader(t *testing.T) {
+	t.Run("Address Column", func(t *testing.T) {
+		input := "\ufeffid,address\n1,\"781 Franklin Ave, Brooklyn\"\n2\n3,10 Downing St\n"
+		reader, err := newRecordReader(strings.NewReader(input), RecordFormatCSV, nil)
+		assert.NoError(t, err)
+
+		addresses, err := readAllRecords(t, reader)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"781 Franklin Ave, Brooklyn", "", "10 Downing St"}, addresses)
+	})
+
+	t.Run("Several Address Columns", func(t *testing.T) {
+		input := "id,street,city,zip,segment\n1,781 Franklin Ave,Brooklyn,11216,retail\n2, ,London,,\n3\n"
+		reader, err := newRecordReader(strings.NewReader(input), RecordFormatCSV, []string{"street", "city", "zip"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"id", "segment"}, reader.PassthroughColumns())
+
+		address, err := reader.Read()
+		assert.NoError(t, err)
+		assert.Equal(t, "781 Franklin Ave, Brooklyn, 11216", address)
+		assert.Equal(t, map[string]string{"id": "1", "segment": "retail"}, reader.Passthrough())
+
+		address, err = reader.Read()
+		assert.NoError(t, err)
+		assert.Equal(t, "London", address)
+
+		// short row has empty values
+		address, err = reader.Read()
+		assert.NoError(t, err)
+		assert.Equal(t, "", address)
+		assert.Equal(t, map[string]string{"id": "3", "segment": ""}, reader.Passthrough())
+	})
+
+	t.Run("Missing Column", func(t *testing.T) {
+		_, err := newRecordReader(strings.NewReader("id,street\n1,Franklin Ave\n"), RecordFormatCSV, []string{"address"})
+		assert.ErrorContains(t, err, `column "address" is not found`)
+	})
+
+	t.Run("Empty Input", func(t *testing.T) {
+		_, err := newRecordReader(strings.NewReader(""), RecordFormatCSV, []string{"address"})
+		assert.ErrorContains(t, err, "no header")
+	})
+}
+
+func TestNDJSONRecordReader(t *testing.T) {
+	input := `{"id": 1, "full_address": "781 Franklin Ave"}
+"10 Downing St"
+{"id": 3}
+`
+	reader, err := newRecordReader(strings.NewReader(input), RecordFormatNDJSON, []string{"full_address"})
+	assert.NoError(t, err)
+
+	addresses, err := readAllRecords(t, reader)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"781 Franklin Ave", "10 Downing St", ""}, addresses)
+
+	reader, err = newRecordReader(strings.NewReader(`{"street": "781 Franklin Ave", "city": "Brooklyn"}`), RecordFormatNDJSON, []string{"street", "city"})
+	assert.NoError(t, err)
+	address, err := reader.Read()
+	assert.NoError(t, err)
+	assert.Equal(t, "781 Franklin Ave, Brooklyn", address)
+	assert.Nil(t, reader.PassthroughColumns())
+
+	reader, _ = newRecordReader(strings.NewReader(`{"address": 10}`), RecordFormatNDJSON, nil)
+	_, err = reader.Read()
+	assert.ErrorContains(t, err, `record 1: field "address" must be string`)
+
+	_, err = newRecordReader(strings.NewReader(""), "xml", nil)
+	assert.ErrorContains(t, err, `unsupported format "xml"`)
+}
+
+func TestRecordFormats(t *testing.T) {
+	assert.Equal(t, RecordFormatNDJSON, recordFormatFromPath("addresses.JSONL"))
+	assert.Equal(t, RecordFormatNDJSON, recordFormatFromPath("addresses.ndjson"))
+	assert.Equal(t, RecordFormatCSV, recordFormatFromPath("addresses.csv"))
+	assert.Equal(t, RecordFormatCSV, recordFormatFromPath("-"))
+
+	format, ok := recordFormatFromContentType("text/csv; charset=utf-8")
+	assert.True(t, ok)
+	assert.Equal(t, RecordFormatCSV, format)
+	format, ok = recordFormatFromContentType("application/x-ndjson")
+	assert.True(t, ok)
+	assert.Equal(t, RecordFormatNDJSON, format)
+	_, ok = recordFormatFromContentType("application/json")
+	assert.False(t, ok)
+}
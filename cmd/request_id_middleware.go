@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// RequestIDHeader is accepted from client (or proxy) and echoed in response
+	RequestIDHeader string = "X-Request-ID"
+	// key of the request ID in gin context
+	requestIDKey string = "request_id"
+)
+
+// incoming IDs are logged and echoed back, so only safe characters are allowed
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:\-]{1,128}$`)
+
+// RequestIDMiddleware accepts X-Request-ID from request or generates new one,
+// echoes it in response and attaches request scoped logger to request context
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+
+		c.Set(requestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		logger := log.With().Str("request_id", requestID).Logger()
+		c.Request = c.Request.WithContext(logger.WithContext(c.Request.Context()))
+
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	// crypto/rand.Read never returns an error
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// requestLogger returns logger with request ID, falling back to global one
+func requestLogger(c *gin.Context) *zerolog.Logger {
+	if logger := zerolog.Ctx(c.Request.Context()); logger.GetLevel() != zerolog.Disabled {
+		return logger
+	}
+	return &log.Logger
+}
+
+// ErrorResponse is body of failed request
+type ErrorResponse struct {
+	Error     string `json:"error"`
+	RequestID string `json:"request_id"`
+}
+
+// abortWithError stops the request with error body (in encoding from Accept
+// header), which includes request ID for correlation with logs
+func abortWithError(c *gin.Context, status int, message string) {
+	c.Abort()
+	respond(c, status, ErrorResponse{
+		Error:     message,
+		RequestID: c.GetString(requestIDKey),
+	})
+}
+
+// recoveryHandler logs panic with request scoped logger and responds with
+// JSON error body instead of empty 500
+func recoveryHandler(c *gin.Context, err any) {
+	requestLogger(c).Error().Interface("panic", err).Msg("Recovered from panic")
+	abortWithError(c, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(RequestIDMiddleware())
+	router.Use(gin.CustomRecoveryWithWriter(io.Discard, recoveryHandler))
+	router.GET("/id", func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString(requestIDKey))
+	})
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	tests := []struct {
+		name       string
+		incomingID string
+		keepID     bool
+	}{
+		{name: "Accepts Incoming ID", incomingID: "trace-123:abc", keepID: true},
+		{name: "Generates Missing ID", incomingID: "", keepID: false},
+		{name: "Replaces Unsafe ID", incomingID: "bad id\nwith newline", keepID: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/id", nil)
+			if tt.incomingID != "" {
+				req.Header.Set(RequestIDHeader, tt.incomingID)
+			}
+			router.ServeHTTP(w, req)
+
+			requestID := w.Header().Get(RequestIDHeader)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, requestID, w.Body.String())
+			if tt.keepID {
+				assert.Equal(t, tt.incomingID, requestID)
+			} else {
+				assert.Len(t, requestID, 32)
+			}
+		})
+	}
+
+	t.Run("Error Body Contains ID", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/panic", nil)
+		req.Header.Set(RequestIDHeader, "panic-request")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+		var response map[string]string
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "panic-request", response["request_id"])
+		assert.Equal(t, "Internal Server Error", response["error"])
+	})
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// resultRow is output row of parse, expand and normalize with raw result
+type resultRow struct {
+	Row     int               `json:"row"`
+	Address string            `json:"address"`
+	Columns map[string]string `json:"columns"`
+	Result  json.RawMessage   `json:"result"`
+}
+
+// resultCSVFormatter returns CSV columns of result and values of every row
+type resultCSVFormatter struct {
+	columns []string
+	values  func(result json.RawMessage) ([]string, error)
+}
+
+// newResultCSVFormatter returns formatter of operation result. Parse and
+// normalize results have column per libpostal label, expand results have
+// expansion_1...expansion_N columns, result with output standard is kept
+// as JSON in result column
+func newResultCSVFormatter(results io.ReadSeeker, operation string, options url.Values) (resultCSVFormatter, error) {
+	switch {
+	case operation == JobOperationExpand:
+		count, err := maxResultExpansions(results)
+		if err != nil {
+			return resultCSVFormatter{}, err
+		}
+		columns := make([]string, count)
+		for i := range columns {
+			columns[i] = fmt.Sprintf("expansion_%d", i+1)
+		}
+		return resultCSVFormatter{columns: columns, values: func(result json.RawMessage) ([]string, error) {
+			var expansions []string
+			if err := json.Unmarshal(result, &expansions); err != nil {
+				return nil, err
+			}
+			values := make([]string, count)
+			copy(values, expansions)
+			return values, nil
+		}}, nil
+	case options.Get("standard") != "":
+		return resultCSVFormatter{columns: []string{"result"}, values: func(result json.RawMessage) ([]string, error) {
+			return []string{string(result)}, nil
+		}}, nil
+	case operation == JobOperationParse:
+		return resultCSVFormatter{columns: parserLabels, values: func(result json.RawMessage) ([]string, error) {
+			var components []ParsedAddressComponent
+			if err := json.Unmarshal(result, &components); err != nil {
+				return nil, err
+			}
+			// repeated label (e.g. two house numbers) keeps every value
+			byLabel := make(map[string][]string)
+			for _, component := range components {
+				byLabel[component.Label] = append(byLabel[component.Label], component.Value)
+			}
+			values := make([]string, len(parserLabels))
+			for i, label := range parserLabels {
+				values[i] = strings.Join(byLabel[label], " ")
+			}
+			return values, nil
+		}}, nil
+	case operation == JobOperationNormalize:
+		return resultCSVFormatter{columns: parserLabels, values: func(result json.RawMessage) ([]string, error) {
+			var normalized NormalizedAddress
+			if err := json.Unmarshal(result, &normalized); err != nil {
+				return nil, err
+			}
+			values := make([]string, len(parserLabels))
+			for i, label := range parserLabels {
+				values[i] = normalized.Canonical[label]
+			}
+			return values, nil
+		}}, nil
+	}
+	return resultCSVFormatter{}, fmt.Errorf("CSV output is not supported for %s", operation)
+}
+
+// maxResultExpansions returns max number of expansions of result rows and
+// rewinds results
+func maxResultExpansions(results io.ReadSeeker) (int, error) {
+	count := 0
+	decoder := json.NewDecoder(results)
+	for {
+		var row struct {
+			Result []string `json:"result"`
+		}
+		if err := decoder.Decode(&row); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return 0, err
+		}
+		count = max(count, len(row.Result))
+	}
+	_, err := results.Seek(0, io.SeekStart)
+	return count, err
+}
+
+// writeResultsCSV converts NDJSON results of parse, expand or normalize into
+// CSV. Header is passthrough columns of CSV input, address and result columns
+// (with result_prefix option, e.g. "parsed_", if input has same columns)
+func writeResultsCSV(results io.ReadSeeker, w io.Writer, operation string, options url.Values, passthrough []string) error {
+	formatter, err := newResultCSVFormatter(results, operation, options)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	header := make([]string, 0, len(passthrough)+1+len(formatter.columns))
+	header = append(header, passthrough...)
+	header = append(header, "address")
+	for _, column := range formatter.columns {
+		header = append(header, options.Get("result_prefix")+column)
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(results)
+	record := make([]string, 0, len(header))
+	for {
+		var row resultRow
+		if err := decoder.Decode(&row); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+		values, err := formatter.values(row.Result)
+		if err != nil {
+			return fmt.Errorf("row %d: %w", row.Row, err)
+		}
+
+		record = record[:0]
+		for _, column := range passthrough {
+			record = append(record, row.Columns[column])
+		}
+		record = append(record, row.Address)
+		record = append(record, values...)
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// inputPassthroughColumns returns passthrough columns of the input file
+func inputPassthroughColumns(path string, options url.Values) ([]string, error) {
+	input, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer input.Close()
+	reader, err := newRecordReader(input, options.Get("format"), options["column"])
+	if err != nil {
+		return nil, err
+	}
+	return reader.PassthroughColumns(), nil
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteResultsCSV(t *testing.T) {
+	readCSV := func(t *testing.T, data []byte) [][]string {
+		t.Helper()
+		records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+		assert.NoError(t, err)
+		return records
+	}
+
+	t.Run("Parse", func(t *testing.T) {
+		results := `{"row":1,"address":"10 Downing St London","columns":{"id":"1"},"result":[{"label":"house_number","value":"10"},{"label":"road","value":"downing st"},{"label":"city","value":"london"}]}
+`
+		var output bytes.Buffer
+		err := writeResultsCSV(strings.NewReader(results), &output, JobOperationParse, url.Values{"result_prefix": {"parsed_"}}, []string{"id"})
+		assert.NoError(t, err)
+
+		records := readCSV(t, output.Bytes())
+		if !assert.Len(t, records, 2) {
+			return
+		}
+		assert.Equal(t, []string{"id", "address", "parsed_house"}, records[0][:3])
+		assert.Len(t, records[0], 2+len(parserLabels))
+		row := make(map[string]string)
+		for i, column := range records[0] {
+			row[column] = records[1][i]
+		}
+		assert.Equal(t, "1", row["id"])
+		assert.Equal(t, "10 Downing St London", row["address"])
+		assert.Equal(t, "10", row["parsed_house_number"])
+		assert.Equal(t, "downing st", row["parsed_road"])
+		assert.Equal(t, "london", row["parsed_city"])
+		assert.Equal(t, "", row["parsed_country"])
+	})
+
+	t.Run("Expand", func(t *testing.T) {
+		results := `{"row":1,"address":"10 Downing St","result":["10 downing street","10 downing saint"]}
+{"row":2,"address":"Main St","result":["main street"]}
+`
+		var output bytes.Buffer
+		assert.NoError(t, writeResultsCSV(strings.NewReader(results), &output, JobOperationExpand, url.Values{}, nil))
+		assert.Equal(t, [][]string{
+			{"address", "expansion_1", "expansion_2"},
+			{"10 Downing St", "10 downing street", "10 downing saint"},
+			{"Main St", "main street", ""},
+		}, readCSV(t, output.Bytes()))
+	})
+
+	t.Run("Normalize", func(t *testing.T) {
+		results := `{"row":1,"address":"10 Downing St","result":{"canonical":{"house_number":"10","road":"downing street"}}}
+`
+		var output bytes.Buffer
+		assert.NoError(t, writeResultsCSV(strings.NewReader(results), &output, JobOperationNormalize, url.Values{}, nil))
+		records := readCSV(t, output.Bytes())
+		if assert.Len(t, records, 2) {
+			row := make(map[string]string)
+			for i, column := range records[0] {
+				row[column] = records[1][i]
+			}
+			assert.Equal(t, "10", row["house_number"])
+			assert.Equal(t, "downing street", row["road"])
+		}
+	})
+
+	t.Run("Output Standard", func(t *testing.T) {
+		results := `{"row":1,"address":"10 Downing St","result":{"streetAddress":"10 Downing St"}}
+`
+		var output bytes.Buffer
+		assert.NoError(t, writeResultsCSV(strings.NewReader(results), &output, JobOperationParse, url.Values{"standard": {"schema_org"}}, nil))
+		assert.Equal(t, [][]string{
+			{"address", "result"},
+			{"10 Downing St", `{"streetAddress":"10 Downing St"}`},
+		}, readCSV(t, output.Bytes()))
+	})
+}
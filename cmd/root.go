@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"os"
@@ -11,19 +13,21 @@ import (
 	"strings"
 	"syscall"
 	"time"
+	"unicode/utf8"
 
 	gopostalExpand "github.com/openvenues/gopostal/expand"
 	gopostalParser "github.com/openvenues/gopostal/parser"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
 
-	ginzerolog "github.com/dn365/gin-zerolog"
 	"github.com/gin-gonic/gin"
+	"github.com/le0pard/postal_server/libpostal"
 	"github.com/le0pard/postal_server/version"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 // EnvPrefix for environment variables
@@ -132,70 +136,363 @@ func stringToBool(s string) bool {
 	return false
 }
 
+// queryStandard returns output standard of /parse and /normalize.
+// Request is aborted, if standard is not supported
+func queryStandard(c *gin.Context) (string, bool) {
+	standard := c.Query("standard")
+	if _, ok := addressFormatters[standard]; standard != "" && !ok {
+		abortWithError(c, http.StatusBadRequest, fmt.Sprintf("unsupported standard %q", standard))
+		return "", false
+	}
+	return standard, true
+}
+
+// HealthResponse is response of /health
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+// VersionResponse is response of root endpoint
+type VersionResponse struct {
+	Version string `json:"version"`
+}
+
 func SetupRouter() *gin.Engine {
 	r := gin.New()
 
-	r.Use(gin.Recovery())
-	r.Use(ginzerolog.Logger("postal_server"))
+	r.Use(RequestIDMiddleware())
+	if cfg := currentConfig(); tracingEnabled(cfg) {
+		// server span per route, parent is taken from W3C traceparent header
+		r.Use(otelgin.Middleware(cfg.TracingServiceName))
+	}
+	r.Use(AccessLogMiddleware())
+	// outside of recovery, so error of panic is compressed too
+	r.Use(CompressionMiddleware())
+	r.Use(gin.CustomRecoveryWithWriter(io.Discard, recoveryHandler))
 	if viper.IsSet("trusted_proxies") {
 		r.SetTrustedProxies(viper.GetStringSlice("trusted_proxies"))
 	}
 
 	// healthcheck endpoint
 	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status": "ok",
-		})
+		respond(c, http.StatusOK, HealthResponse{Status: "ok"})
 	})
 
-	// basic auth
-	if viper.IsSet("basic_auth_username") && viper.IsSet("basic_auth_password") {
-		r.Use(gin.BasicAuth(gin.Accounts{
-			viper.GetString("basic_auth_username"): viper.GetString("basic_auth_password"),
-		}))
-	}
+	// basic auth (credentials are looked up on each request to pick up reloaded secrets)
+	r.Use(BasicAuthMiddleware(func() (string, string) {
+		cfg := currentConfig()
+		return cfg.BasicAuthUsername, cfg.BasicAuthPassword
+	}))
 	// bearer token auth
-	if viper.IsSet("bearer_auth_token") {
-		r.Use(MiddlewareWithStaticToken(viper.GetString("bearer_auth_token")))
-	}
+	r.Use(MiddlewareWithTokenSource(func() string {
+		return currentConfig().BearerAuthToken
+	}))
+	// libpostal routes share slots with batch jobs (max_concurrency)
+	limited := ConcurrencyLimitMiddleware()
 
 	// expand libpostal
-	r.GET("/expand", func(c *gin.Context) {
+	r.GET("/expand", HTTPCacheMiddleware(expandCacheParams), limited, func(c *gin.Context) {
 		queryParams := c.Request.URL.Query()
 		address := c.DefaultQuery("address", "")
 
-		options := gopostalExpand.GetDefaultExpansionOptions()
-		expansions := gopostalExpand.ExpandAddressOptions(
+		options := mapQueryParamsOnExpandOptions(
+			gopostalExpand.GetDefaultExpansionOptions(),
+			queryParams,
+		)
+		if len(options.Languages) == 0 && stringToBool(c.Query("auto_language")) {
+			options.Languages = detectedLanguages(classifyLanguage(c.Request.Context(), address))
+		}
+		// deterministic order (same as canonical ranking), so limit keeps best expansions
+		expansions := expandRanked(
+			c.Request.Context(),
 			address,
+			options,
+		)
+
+		maxExpansions, _ := strconv.Atoi(c.Query("max_expansions"))
+		limit := func(expansions []string) []string {
+			return limitExpansions(expansions, maxExpansions, currentConfig().MaxExpansions)
+		}
+
+		// single deterministic form instead of all expansions
+		if stringToBool(c.Query("canonical")) {
+			canonical := canonicalExpansion(expansions, stringToBool(c.Query("canonical_hash")))
+			canonical.Expansions = limit(canonical.Expansions)
+			respond(c, http.StatusOK, canonical)
+			return
+		}
+
+		expansions = limit(expansions)
+		if stringToBool(c.Query("explain")) {
+			respond(c, http.StatusOK, explainExpansions(address, expansions))
+			return
+		}
+		respond(c, http.StatusOK, expansions)
+	})
+
+	// expand each component of structured address (JSON object of components)
+	r.POST("/expand", limited, func(c *gin.Context) {
+		var request StructuredAddressRequest
+		if err := bindRequestBody(c, &request); err != nil {
+			abortWithBodyError(c, err)
+			return
+		}
+
+		maxExpansions, _ := strconv.Atoi(c.Query("max_expansions"))
+		components, err := expandStructuredAddress(
+			c.Request.Context(),
+			request,
 			mapQueryParamsOnExpandOptions(
-				options,
-				queryParams,
+				gopostalExpand.GetDefaultExpansionOptions(),
+				c.Request.URL.Query(),
+			),
+			maxExpansions,
+		)
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		respond(c, http.StatusOK, components)
+	})
+
+	// detect languages of the address
+	r.GET("/languages", limited, func(c *gin.Context) {
+		languages := classifyLanguage(c.Request.Context(), c.DefaultQuery("address", ""))
+		if languages == nil {
+			languages = []libpostal.Language{}
+		}
+		respond(c, http.StatusOK, languages)
+	})
+
+	// extract addresses from free-form text
+	r.POST("/extract", limited, func(c *gin.Context) {
+		var request ExtractRequest
+		if err := bindRequestBody(c, &request); err != nil {
+			abortWithBodyError(c, err)
+			return
+		}
+		// every candidate span is parsed, so long text holds libpostal slot for long time
+		if maxLength := currentConfig().MaxExtractTextLength; maxLength > 0 && utf8.RuneCountInString(request.Text) > maxLength {
+			abortWithError(c, http.StatusBadRequest, fmt.Sprintf("text is longer than %d characters", maxLength))
+			return
+		}
+		respond(c, http.StatusOK, extractAddresses(c.Request.Context(), request))
+	})
+
+	// similarity of two addresses (or batch of pairs)
+	r.POST("/compare", limited, func(c *gin.Context) {
+		var request CompareRequest
+		if err := bindRequestBody(c, &request); err != nil {
+			abortWithBodyError(c, err)
+			return
+		}
+
+		maxExpansions, _ := strconv.Atoi(c.Query("max_expansions"))
+		similarities, err := compareAddresses(
+			c.Request.Context(),
+			request,
+			mapQueryParamsOnExpandOptions(
+				gopostalExpand.GetDefaultExpansionOptions(),
+				c.Request.URL.Query(),
 			),
+			maxExpansions,
+		)
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if len(request.Pairs) == 0 {
+			respond(c, http.StatusOK, similarities[0])
+			return
+		}
+		respond(c, http.StatusOK, similarities)
+	})
+
+	// async batch jobs and deduplication of uploaded files
+	registerJobRoutes(r, defaultJobManager)
+
+	// tokenize libpostal
+	r.GET("/tokenize", limited, func(c *gin.Context) {
+		tokens := tokenizeAddress(
+			c.Request.Context(),
+			c.DefaultQuery("address", ""),
+			stringToBool(c.Query("whitespace")),
+		)
+		if tokens == nil {
+			tokens = []libpostal.Token{}
+		}
+		respond(c, http.StatusOK, tokens)
+	})
+
+	// normalize string libpostal (without expansion)
+	r.GET("/normalize_string", limited, func(c *gin.Context) {
+		address := c.DefaultQuery("address", "")
+		queryParams := c.Request.URL.Query()
+		stringOptions := mapQueryParamsOnNormalizeOptions(
+			libpostal.NormalizeDefaultStringOptions,
+			normalizeStringOptions,
+			queryParams,
 		)
-		c.JSON(http.StatusOK, expansions)
+
+		result := NormalizedString{
+			Normalized: normalizeString(c.Request.Context(), address, stringOptions),
+		}
+		if stringToBool(c.Query("tokens")) {
+			tokenOptions := mapQueryParamsOnNormalizeOptions(
+				libpostal.NormalizeDefaultTokenOptions,
+				normalizeTokenOptions,
+				queryParams,
+			)
+			result.Tokens = normalizeTokens(
+				c.Request.Context(),
+				address,
+				stringOptions,
+				tokenOptions,
+				stringToBool(c.Query("whitespace")),
+			)
+		}
+		respond(c, http.StatusOK, result)
 	})
 
 	// parse libpostal
-	r.GET("/parse", func(c *gin.Context) {
+	r.GET("/parse", HTTPCacheMiddleware(parseCacheParams), limited, func(c *gin.Context) {
 		address := c.DefaultQuery("address", "")
 		language := c.DefaultQuery("language", "")
 		country := c.DefaultQuery("country", "")
+		standard, ok := queryStandard(c)
+		if !ok {
+			return
+		}
+		if language == "" && stringToBool(c.Query("auto_language")) {
+			if languages := classifyLanguage(c.Request.Context(), address); len(languages) > 0 {
+				language = languages[0].Language
+			}
+		}
+
+		parsed := parseAndResolve(
+			c.Request.Context(),
+			address,
+			ParseOptions{
+				Parser: gopostalParser.ParserOptions{
+					Language: language,
+					Country:  country,
+				},
+				SecondPass:       stringToBool(c.Query("second_pass")),
+				ValidatePostcode: stringToBool(c.Query("validate_postcode")),
+			},
+		)
+		if standard != "" {
+			respond(c, http.StatusOK, addressFormatters[standard](parsed))
+			return
+		}
+		respond(c, http.StatusOK, parsed)
+	})
+
+	// components of structured address (JSON object of components)
+	r.POST("/parse", limited, func(c *gin.Context) {
+		var request StructuredAddressRequest
+		if err := bindRequestBody(c, &request); err != nil {
+			abortWithBodyError(c, err)
+			return
+		}
+		standard, ok := queryStandard(c)
+		if !ok {
+			return
+		}
+
+		parsed, err := parseStructuredAddress(c.Request.Context(), request, stringToBool(c.Query("validate_postcode")))
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if standard != "" {
+			respond(c, http.StatusOK, addressFormatters[standard](parsed))
+			return
+		}
+		respond(c, http.StatusOK, parsed)
+	})
+
+	// validate and canonicalize postal code
+	r.GET("/postcode", func(c *gin.Context) {
+		respond(c, http.StatusOK, validatePostcode(
+			c.DefaultQuery("postcode", ""),
+			c.DefaultQuery("country", ""),
+		))
+	})
+
+	// parse libpostal, then expand each parsed component
+	r.GET("/normalize", limited, func(c *gin.Context) {
+		queryParams := c.Request.URL.Query()
+		address := c.DefaultQuery("address", "")
+		language := c.DefaultQuery("language", "")
+		country := c.DefaultQuery("country", "")
+		standard, ok := queryStandard(c)
+		if !ok {
+			return
+		}
 
-		parsed := gopostalParser.ParseAddressOptions(
+		maxExpansions, _ := strconv.Atoi(c.Query("max_expansions"))
+		normalized := normalizeAddress(
+			c.Request.Context(),
 			address,
 			gopostalParser.ParserOptions{
 				Language: language,
 				Country:  country,
 			},
+			mapQueryParamsOnExpandOptions(
+				gopostalExpand.GetDefaultExpansionOptions(),
+				queryParams,
+			),
+			maxExpansions,
 		)
-		c.JSON(http.StatusOK, parsed)
+		if standard != "" {
+			// canonical values are already expanded, so suffixes and units are not ambiguous
+			respond(c, http.StatusOK, addressFormatters[standard](canonicalComponents(normalized, country)))
+			return
+		}
+		respond(c, http.StatusOK, normalized)
+	})
+
+	// expand each component of structured address (JSON object of components)
+	r.POST("/normalize", limited, func(c *gin.Context) {
+		var request StructuredAddressRequest
+		if err := bindRequestBody(c, &request); err != nil {
+			abortWithBodyError(c, err)
+			return
+		}
+		standard, ok := queryStandard(c)
+		if !ok {
+			return
+		}
+
+		maxExpansions, _ := strconv.Atoi(c.Query("max_expansions"))
+		normalized, err := normalizeStructuredAddress(
+			c.Request.Context(),
+			request,
+			mapQueryParamsOnExpandOptions(
+				gopostalExpand.GetDefaultExpansionOptions(),
+				c.Request.URL.Query(),
+			),
+			maxExpansions,
+		)
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if standard != "" {
+			respond(c, http.StatusOK, addressFormatters[standard](canonicalComponents(normalized, request.Country)))
+			return
+		}
+		respond(c, http.StatusOK, normalized)
 	})
 
 	// root
 	r.GET("/", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"version": Version,
-		})
+		respond(c, http.StatusOK, VersionResponse{Version: Version})
+	})
+
+	r.NoRoute(func(c *gin.Context) {
+		abortWithError(c, http.StatusNotFound, http.StatusText(http.StatusNotFound))
 	})
 
 	return r
@@ -212,6 +509,32 @@ var rootCmd = &cobra.Command{
 	TraverseChildren:      true,
 	Long:                  `Postal web server that grants access to the libpostal library, enabling the parsing and normalization of street addresses globally`,
 	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := LoadConfig(viper.GetViper())
+		if err != nil {
+			var cfgErr *ConfigError
+			if errors.As(err, &cfgErr) {
+				log.Fatal().Strs("problems", cfgErr.Problems).Msg("Invalid configuration")
+			}
+			log.Fatal().Err(err).Msg("Invalid configuration")
+		}
+		activeConfig.Store(cfg)
+		log.Debug().Object("config", cfg).Msg("Effective configuration")
+
+		shutdownTracing, err := setupTracing(context.Background(), cfg)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Unable to setup tracing")
+		}
+		if tracingEnabled(cfg) {
+			log.Info().Str("exporter", cfg.TracingExporter).Msg("OpenTelemetry tracing enabled")
+		}
+
+		log.Info().Str("version", libpostalDataVersion()).Msg("Libpostal data version")
+
+		// unfinished jobs of previous run are resumed on start
+		if _, err := defaultJobManager(); err != nil {
+			log.Fatal().Err(err).Msg("Unable to open job store")
+		}
+
 		r := SetupRouter()
 
 		var handler http.Handler = r
@@ -245,6 +568,16 @@ var rootCmd = &cobra.Command{
 			}
 		}()
 
+		// SIGHUP re-reads config file and secret files
+		reload := make(chan os.Signal, 1)
+		signal.Notify(reload, syscall.SIGHUP)
+		go func() {
+			for range reload {
+				log.Info().Msg("Reloading configuration...")
+				reloadConfig()
+			}
+		}()
+
 		quit := make(chan os.Signal, 1)
 		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 		// Block until we receive our signal
@@ -258,6 +591,10 @@ var rootCmd = &cobra.Command{
 		if err := srv.Shutdown(ctx); err != nil {
 			log.Fatal().Err(err).Msg("Server forced to shutdown")
 		}
+		shutdownDefaultJobManager()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Error().Err(err).Msg("Unable to flush traces")
+		}
 
 		log.Info().Msg("Server exiting")
 	},
@@ -321,7 +658,8 @@ func initConfig() {
 	viper.AutomaticEnv()
 
 	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+		// stderr keeps stdout clean for the config subcommands output
+		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
 
 	initLogging()
@@ -344,7 +682,7 @@ func init() {
 	viper.BindPFlag("host", rootCmd.PersistentFlags().Lookup("host"))
 	rootCmd.PersistentFlags().IntP("port", "p", 8000, "server port")
 	viper.BindPFlag("port", rootCmd.PersistentFlags().Lookup("port"))
-	viper.BindEnv("port", "PORT")
+	viper.BindEnv(append([]string{"port"}, configEnvNames("port")...)...)
 	rootCmd.PersistentFlags().StringSliceP("trusted_proxies", "t", []string{}, "trusted proxies IP addresses (separated by commas)")
 	viper.BindPFlag("trusted_proxies", rootCmd.PersistentFlags().Lookup("trusted_proxies"))
 
@@ -352,13 +690,71 @@ func init() {
 	viper.BindPFlag("log_format", rootCmd.PersistentFlags().Lookup("log_format"))
 	rootCmd.PersistentFlags().StringP("log_level", "l", "info", "logger level")
 	viper.BindPFlag("log_level", rootCmd.PersistentFlags().Lookup("log_level"))
-
-	rootCmd.Flags().String("basic_auth_username", "", "basic auth username (required if basic auth password is set)")
+	rootCmd.PersistentFlags().String("log_address_policy", AddressLogPolicyAuto, "how addresses are logged: auto, full, hashed, redacted or length_only")
+	viper.BindPFlag("log_address_policy", rootCmd.PersistentFlags().Lookup("log_address_policy"))
+	rootCmd.PersistentFlags().String("log_address_hash_key", "", "secret key for hashed address log policy")
+	viper.BindPFlag("log_address_hash_key", rootCmd.PersistentFlags().Lookup("log_address_hash_key"))
+	rootCmd.PersistentFlags().String("log_address_hash_key_file", "", "file with secret key for hashed address log policy (re-read on SIGHUP)")
+	viper.BindPFlag("log_address_hash_key_file", rootCmd.PersistentFlags().Lookup("log_address_hash_key_file"))
+
+	rootCmd.PersistentFlags().Int("max_expansions", 0, "max number of expansions in response (0 - unlimited)")
+	viper.BindPFlag("max_expansions", rootCmd.PersistentFlags().Lookup("max_expansions"))
+	rootCmd.PersistentFlags().Int("max_extract_text_length", 10000, "max length of /extract text in characters (0 - unlimited)")
+	viper.BindPFlag("max_extract_text_length", rootCmd.PersistentFlags().Lookup("max_extract_text_length"))
+	rootCmd.PersistentFlags().Int("max_compare_pairs", 100, "max number of pairs in one /compare request (0 - unlimited)")
+	viper.BindPFlag("max_compare_pairs", rootCmd.PersistentFlags().Lookup("max_compare_pairs"))
+	rootCmd.PersistentFlags().Int("max_concurrency", 0, "max libpostal requests and job records processed at once (0 - unlimited)")
+	viper.BindPFlag("max_concurrency", rootCmd.PersistentFlags().Lookup("max_concurrency"))
+	rootCmd.PersistentFlags().String("compression_level", CompressionLevelDefault, "response compression level of gzip, br and zstd: none, fastest, default or best")
+	viper.BindPFlag("compression_level", rootCmd.PersistentFlags().Lookup("compression_level"))
+	rootCmd.PersistentFlags().Int("compression_min_size", 1024, "responses smaller than this size in bytes are not compressed")
+	viper.BindPFlag("compression_min_size", rootCmd.PersistentFlags().Lookup("compression_min_size"))
+	rootCmd.PersistentFlags().Int("max_decompressed_body_mb", 256, "max size of request body with Content-Encoding after decompression, in megabytes")
+	viper.BindPFlag("max_decompressed_body_mb", rootCmd.PersistentFlags().Lookup("max_decompressed_body_mb"))
+
+	rootCmd.PersistentFlags().Int("cache_max_age_seconds", 3600, "max-age of Cache-Control of /parse and /expand responses (0 - revalidate every time)")
+	viper.BindPFlag("cache_max_age_seconds", rootCmd.PersistentFlags().Lookup("cache_max_age_seconds"))
+	rootCmd.PersistentFlags().String("libpostal_data_dir", "", "libpostal data directory, version of data is part of ETag (default /usr/share/libpostal/libpostal or /usr/local/share/libpostal)")
+	viper.BindPFlag("libpostal_data_dir", rootCmd.PersistentFlags().Lookup("libpostal_data_dir"))
+
+	rootCmd.PersistentFlags().String("jobs_dir", "", "directory of async jobs, it must be persistent to resume jobs after restart (default postal_server_jobs in working directory)")
+	viper.BindPFlag("jobs_dir", rootCmd.PersistentFlags().Lookup("jobs_dir"))
+	rootCmd.PersistentFlags().Int("jobs_workers", 1, "async jobs processed at once")
+	viper.BindPFlag("jobs_workers", rootCmd.PersistentFlags().Lookup("jobs_workers"))
+	rootCmd.PersistentFlags().Int("jobs_retention_hours", 24, "finished async jobs are removed after this time (0 - never)")
+	viper.BindPFlag("jobs_retention_hours", rootCmd.PersistentFlags().Lookup("jobs_retention_hours"))
+	rootCmd.PersistentFlags().String("webhook_secret", "", "secret key of HMAC-SHA256 signature of job webhooks (required for callback_url)")
+	viper.BindPFlag("webhook_secret", rootCmd.PersistentFlags().Lookup("webhook_secret"))
+	rootCmd.PersistentFlags().String("webhook_secret_file", "", "file with secret key of job webhooks signature (re-read on SIGHUP)")
+	viper.BindPFlag("webhook_secret_file", rootCmd.PersistentFlags().Lookup("webhook_secret_file"))
+	rootCmd.PersistentFlags().Int("webhook_max_attempts", 8, "max delivery attempts of job webhook")
+	viper.BindPFlag("webhook_max_attempts", rootCmd.PersistentFlags().Lookup("webhook_max_attempts"))
+	rootCmd.PersistentFlags().Int("webhook_timeout_seconds", 10, "timeout of one job webhook delivery attempt")
+	viper.BindPFlag("webhook_timeout_seconds", rootCmd.PersistentFlags().Lookup("webhook_timeout_seconds"))
+	rootCmd.PersistentFlags().Bool("webhook_allow_private_networks", false, "allow job webhooks to loopback, private and link-local addresses")
+	viper.BindPFlag("webhook_allow_private_networks", rootCmd.PersistentFlags().Lookup("webhook_allow_private_networks"))
+
+	rootCmd.PersistentFlags().String("tracing_exporter", TracingExporterNone, "OpenTelemetry traces exporter: none, otlp, stdout or file")
+	viper.BindPFlag("tracing_exporter", rootCmd.PersistentFlags().Lookup("tracing_exporter"))
+	rootCmd.PersistentFlags().String("tracing_endpoint", "", "OTLP HTTP endpoint URL (default from OTEL_EXPORTER_OTLP_* env variables)")
+	viper.BindPFlag("tracing_endpoint", rootCmd.PersistentFlags().Lookup("tracing_endpoint"))
+	rootCmd.PersistentFlags().String("tracing_file", "", "file for traces (required for file exporter)")
+	viper.BindPFlag("tracing_file", rootCmd.PersistentFlags().Lookup("tracing_file"))
+	rootCmd.PersistentFlags().Float64("tracing_sample_ratio", 1, "ratio of sampled traces (used if request has no parent trace)")
+	viper.BindPFlag("tracing_sample_ratio", rootCmd.PersistentFlags().Lookup("tracing_sample_ratio"))
+	rootCmd.PersistentFlags().String("tracing_service_name", "postal_server", "service name in traces")
+	viper.BindPFlag("tracing_service_name", rootCmd.PersistentFlags().Lookup("tracing_service_name"))
+
+	rootCmd.PersistentFlags().String("basic_auth_username", "", "basic auth username (required if basic auth password is set)")
 	viper.BindPFlag("basic_auth_username", rootCmd.PersistentFlags().Lookup("basic_auth_username"))
-	rootCmd.Flags().String("basic_auth_password", "", "basic auth password (required if basic auth username is set)")
+	rootCmd.PersistentFlags().String("basic_auth_password", "", "basic auth password (required if basic auth username is set)")
 	viper.BindPFlag("basic_auth_password", rootCmd.PersistentFlags().Lookup("basic_auth_password"))
-	rootCmd.MarkFlagsRequiredTogether("basic_auth_username", "basic_auth_password")
 
-	rootCmd.Flags().String("bearer_auth_token", "", "bearer authentication token")
+	rootCmd.PersistentFlags().String("bearer_auth_token", "", "bearer authentication token")
 	viper.BindPFlag("bearer_auth_token", rootCmd.PersistentFlags().Lookup("bearer_auth_token"))
+
+	rootCmd.PersistentFlags().String("basic_auth_password_file", "", "file with basic auth password (re-read on SIGHUP)")
+	viper.BindPFlag("basic_auth_password_file", rootCmd.PersistentFlags().Lookup("basic_auth_password_file"))
+	rootCmd.PersistentFlags().String("bearer_auth_token_file", "", "file with bearer authentication token (re-read on SIGHUP)")
+	viper.BindPFlag("bearer_auth_token_file", rootCmd.PersistentFlags().Lookup("bearer_auth_token_file"))
 }
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	gopostalExpand "github.com/openvenues/gopostal/expand"
+	gopostalParser "github.com/openvenues/gopostal/parser"
+)
+
+// parserLabels are libpostal parser labels in order of the structured record
+var parserLabels = []string{
+	"house",
+	"category",
+	"near",
+	"house_number",
+	"road",
+	"unit",
+	"level",
+	"staircase",
+	"entrance",
+	"po_box",
+	"postcode",
+	"suburb",
+	"city_district",
+	"city",
+	"island",
+	"state_district",
+	"state",
+	"country_region",
+	"country",
+	"world_region",
+}
+
+// StructuredAddressRequest is address, which is already split into
+// components (label -> value), e.g. from street/city/postcode columns
+type StructuredAddressRequest struct {
+	Components map[string]string `json:"components" binding:"required"`
+	Language   string            `json:"language"`
+	Country    string            `json:"country"`
+	// parse every value alone and flag values, which libpostal parses
+	// with other label (e.g. city, which looks like road)
+	Validate bool `json:"validate"`
+}
+
+// structuredComponents returns non empty components in parserLabels order.
+// Unknown labels are error, so misspelled column is not ignored silently
+func structuredComponents(components map[string]string) ([]gopostalParser.ParsedComponent, error) {
+	for label := range components {
+		if _, ok := parserLabelToAddressComponent[label]; !ok {
+			return nil, fmt.Errorf("unknown component label %q, must be one of %s", label, strings.Join(parserLabels, ", "))
+		}
+	}
+
+	parsed := make([]gopostalParser.ParsedComponent, 0, len(components))
+	for _, label := range parserLabels {
+		if value := strings.TrimSpace(components[label]); value != "" {
+			parsed = append(parsed, gopostalParser.ParsedComponent{Label: label, Value: value})
+		}
+	}
+	return parsed, nil
+}
+
+// compatibleLabels reports whether parsed label is acceptable for column
+// label. Labels are compared exactly: every toponym (city, state, country,
+// etc) is expanded with same dictionaries, but city in state column is
+// still wrong column
+func compatibleLabels(label, parsedLabel string) bool {
+	return label == parsedLabel
+}
+
+// normalizeStructuredAddress expands every component of structured address
+// with matching component mask, same as normalizeAddress does for parsed components
+func normalizeStructuredAddress(
+	ctx context.Context,
+	request StructuredAddressRequest,
+	expandOptions gopostalExpand.ExpandOptions,
+	maxExpansions int,
+) (NormalizedAddress, error) {
+	parsed, err := structuredComponents(request.Components)
+	if err != nil {
+		return NormalizedAddress{}, err
+	}
+
+	if len(expandOptions.Languages) == 0 && request.Language != "" {
+		expandOptions.Languages = []string{request.Language}
+	}
+	normalized := normalizeParsedComponents(ctx, parsed, expandOptions, maxExpansions)
+
+	if request.Validate {
+		for i := range normalized.Components {
+			component := &normalized.Components[i]
+			parsedAs, valid := validateStructuredValue(ctx, component.Label, component.Value, request.parserOptions())
+			component.ParsedAs, component.Valid = parsedAs, &valid
+		}
+	}
+	return normalized, nil
+}
+
+// expandStructuredAddress returns expansions of every component of structured
+// address (POST /expand), same as components of normalizeStructuredAddress
+func expandStructuredAddress(
+	ctx context.Context,
+	request StructuredAddressRequest,
+	expandOptions gopostalExpand.ExpandOptions,
+	maxExpansions int,
+) ([]NormalizedComponent, error) {
+	normalized, err := normalizeStructuredAddress(ctx, request, expandOptions, maxExpansions)
+	if err != nil {
+		return nil, err
+	}
+	return normalized.Components, nil
+}
+
+// parseStructuredAddress returns components of structured address (POST
+// /parse) with resolved ISO codes, same as parseAndResolve does for parsed
+// address. Validation of column marks component as not valid, same as
+// invalid postal code
+func parseStructuredAddress(
+	ctx context.Context,
+	request StructuredAddressRequest,
+	validatePostcode bool,
+) ([]ParsedAddressComponent, error) {
+	parsed, err := structuredComponents(request.Components)
+	if err != nil {
+		return nil, err
+	}
+
+	components := toParsedAddressComponents(parsed)
+	countryCode := annotateISOCodes(components, request.Country)
+	if validatePostcode {
+		annotatePostcodes(components, countryCode)
+	}
+	if request.Validate {
+		for i := range components {
+			component := &components[i]
+			parsedAs, valid := validateStructuredValue(ctx, component.Label, component.Value, request.parserOptions())
+			if component.Valid != nil {
+				valid = valid && *component.Valid
+			}
+			component.ParsedAs, component.Valid = parsedAs, &valid
+		}
+	}
+	return components, nil
+}
+
+// parserOptions returns parser options of the request
+func (r StructuredAddressRequest) parserOptions() gopostalParser.ParserOptions {
+	return gopostalParser.ParserOptions{
+		Language: r.Language,
+		Country:  r.Country,
+	}
+}
+
+// validateStructuredValue parses component value alone and returns parsed
+// labels. Value is not valid, if libpostal parses it (or its part) with
+// not compatible label
+func validateStructuredValue(
+	ctx context.Context,
+	label, value string,
+	parserOptions gopostalParser.ParserOptions,
+) ([]string, bool) {
+	valid := true
+	parsedAs := []string{}
+	for _, parsed := range parseAddress(ctx, value, parserOptions) {
+		parsedAs = append(parsedAs, parsed.Label)
+		if !compatibleLabels(label, parsed.Label) {
+			valid = false
+		}
+	}
+	return parsedAs, valid
+}
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gopostalParser "github.com/openvenues/gopostal/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParserLabels(t *testing.T) {
+	// every label has component mask
+	assert.Len(t, parserLabels, len(parserLabelToAddressComponent))
+	for _, label := range parserLabels {
+		assert.Contains(t, parserLabelToAddressComponent, label)
+	}
+}
+
+func TestStructuredComponents(t *testing.T) {
+	t.Run("Ordered Components", func(t *testing.T) {
+		parsed, err := structuredComponents(map[string]string{
+			"city":         "Brooklyn",
+			"postcode":     " 11216 ",
+			"road":         "Franklin Ave",
+			"house_number": "781",
+			"unit":         "",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []gopostalParser.ParsedComponent{
+			{Label: "house_number", Value: "781"},
+			{Label: "road", Value: "Franklin Ave"},
+			{Label: "postcode", Value: "11216"},
+			{Label: "city", Value: "Brooklyn"},
+		}, parsed)
+	})
+
+	t.Run("Unknown Label", func(t *testing.T) {
+		_, err := structuredComponents(map[string]string{"street": "Franklin Ave"})
+		assert.ErrorContains(t, err, `unknown component label "street"`)
+	})
+}
+
+func TestCompatibleLabels(t *testing.T) {
+	assert.True(t, compatibleLabels("road", "road"))
+	assert.False(t, compatibleLabels("city", "road"))
+	assert.False(t, compatibleLabels("road", "house_number"))
+	// toponyms are expanded with same dictionaries, but they are different columns
+	assert.False(t, compatibleLabels("city", "state"))
+	assert.False(t, compatibleLabels("state", "country"))
+	assert.False(t, compatibleLabels("city", "city_district"))
+}
+
+func TestStructuredNormalizeRoute(t *testing.T) {
+	router := SetupRouter()
+
+	t.Run("Structured Input", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		body := `{"components": {"house_number": "781", "road": "Franklin Ave", "city": "Franklin Ave"}, "language": "en", "validate": true}`
+		req, _ := http.NewRequest(http.MethodPost, "/normalize", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response NormalizedAddress
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+
+		assert.Nil(t, err)
+		components := make(map[string]NormalizedComponent)
+		for _, component := range response.Components {
+			components[component.Label] = component
+		}
+		assert.Contains(t, components["road"].Expansions, "franklin avenue")
+		if assert.NotNil(t, components["road"].Valid) {
+			assert.True(t, *components["road"].Valid)
+		}
+		// city, which libpostal parses as road
+		assert.Equal(t, []string{"road"}, components["city"].ParsedAs)
+		if assert.NotNil(t, components["city"].Valid) {
+			assert.False(t, *components["city"].Valid)
+		}
+	})
+
+	t.Run("Unknown Label", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/normalize", strings.NewReader(`{"components": {"street": "Franklin Ave"}}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `unknown component label`)
+	})
+
+	t.Run("Invalid Body", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/normalize", strings.NewReader(`not json`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestStructuredParseRoute(t *testing.T) {
+	router := SetupRouter()
+
+	t.Run("Structured Input", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		body := `{"components": {"road": "Franklin Ave", "city": "Franklin Ave", "country": "United States"}, "validate": true}`
+		req, _ := http.NewRequest(http.MethodPost, "/parse", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response []ParsedAddressComponent
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+
+		assert.Nil(t, err)
+		if assert.Len(t, response, 3) {
+			assert.Equal(t, "road", response[0].Label)
+			assert.Equal(t, "Franklin Ave", response[0].Value)
+			assert.Equal(t, []string{"road"}, response[0].ParsedAs)
+			if assert.NotNil(t, response[0].Valid) {
+				assert.True(t, *response[0].Valid)
+			}
+			// city, which libpostal parses as road
+			assert.Equal(t, "city", response[1].Label)
+			assert.Equal(t, []string{"road"}, response[1].ParsedAs)
+			if assert.NotNil(t, response[1].Valid) {
+				assert.False(t, *response[1].Valid)
+			}
+			assert.Equal(t, "country", response[2].Label)
+			assert.Equal(t, "US", response[2].CountryCode)
+		}
+	})
+
+	t.Run("Without Validation", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/parse", strings.NewReader(`{"components": {"road": "Franklin Ave"}}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[{"label": "road", "value": "Franklin Ave"}]`, w.Body.String())
+	})
+
+	t.Run("Unknown Label", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/parse", strings.NewReader(`{"components": {"street": "Franklin Ave"}}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `unknown component label`)
+	})
+}
+
+func TestStructuredExpandRoute(t *testing.T) {
+	router := SetupRouter()
+
+	t.Run("Structured Input", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		body := `{"components": {"house_number": "781", "road": "Franklin Ave", "city": "Franklin Ave"}, "validate": true}`
+		req, _ := http.NewRequest(http.MethodPost, "/expand", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response []NormalizedComponent
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+
+		assert.Nil(t, err)
+		if assert.Len(t, response, 3) {
+			assert.Equal(t, []string{"house_number", "road", "city"}, []string{response[0].Label, response[1].Label, response[2].Label})
+			for _, component := range response {
+				assert.NotEmpty(t, component.Expansions, component.Label)
+			}
+			assert.Equal(t, []string{"road"}, response[2].ParsedAs)
+			if assert.NotNil(t, response[2].Valid) {
+				assert.False(t, *response[2].Valid)
+			}
+		}
+	})
+
+	t.Run("Invalid Body", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/expand", strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
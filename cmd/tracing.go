@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/le0pard/postal_server/version"
+	gopostalExpand "github.com/openvenues/gopostal/expand"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracing exporters
+const (
+	TracingExporterNone   string = "none"
+	TracingExporterOTLP   string = "otlp"
+	TracingExporterStdout string = "stdout"
+	TracingExporterFile   string = "file"
+)
+
+// tracer for libpostal spans. Until tracing is set up, global provider is no-op
+func tracer() trace.Tracer {
+	return otel.Tracer("github.com/le0pard/postal_server/cmd")
+}
+
+func tracingEnabled(cfg *Config) bool {
+	exporter := strings.ToLower(cfg.TracingExporter)
+	return exporter != "" && exporter != TracingExporterNone
+}
+
+// setupTracing registers global tracer provider and W3C propagators.
+// Returned function flushes and stops exporter
+func setupTracing(ctx context.Context, cfg *Config) (func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+	if !tracingEnabled(cfg) {
+		return noop, nil
+	}
+
+	var (
+		exporter sdktrace.SpanExporter
+		closer   io.Closer
+		err      error
+	)
+	switch strings.ToLower(cfg.TracingExporter) {
+	case TracingExporterOTLP:
+		var opts []otlptracehttp.Option
+		// without endpoint standard OTEL_EXPORTER_OTLP_* env variables are used
+		if cfg.TracingEndpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.TracingEndpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	case TracingExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+	case TracingExporterFile:
+		var file *os.File
+		file, err = os.OpenFile(cfg.TracingFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err == nil {
+			closer = file
+			exporter, err = stdouttrace.New(stdouttrace.WithWriter(file))
+		}
+	default:
+		err = fmt.Errorf("unknown tracing exporter %q", cfg.TracingExporter)
+	}
+	if err != nil {
+		return noop, fmt.Errorf("unable to create tracing exporter: %w", err)
+	}
+
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(cfg.TracingServiceName),
+			semconv.ServiceVersion(version.Version),
+		),
+	)
+	if err != nil {
+		return noop, fmt.Errorf("unable to create tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		// keep sampling decision of upstream service, if there is one
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.TracingSampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			err = errors.Join(err, closer.Close())
+		}
+		return err
+	}, nil
+}
+
+// expandOptionsAttributes describes options profile of expansion span.
+// The address itself is never added to spans
+func expandOptionsAttributes(options gopostalExpand.ExpandOptions) []attribute.KeyValue {
+	flags := map[string]bool{
+		"latin_ascii":              options.LatinAscii,
+		"transliterate":            options.Transliterate,
+		"strip_accents":            options.StripAccents,
+		"decompose":                options.Decompose,
+		"lowercase":                options.Lowercase,
+		"trim_string":              options.TrimString,
+		"replace_word_hyphens":     options.ReplaceWordHyphens,
+		"delete_word_hyphens":      options.DeleteWordHyphens,
+		"replace_numeric_hyphens":  options.ReplaceNumericHyphens,
+		"delete_numeric_hyphens":   options.DeleteNumericHyphens,
+		"split_alpha_from_numeric": options.SplitAlphaFromNumeric,
+		"delete_final_periods":     options.DeleteFinalPeriods,
+		"delete_acronym_periods":   options.DeleteAcronymPeriods,
+		"drop_english_possessives": options.DropEnglishPossessives,
+		"delete_apostrophes":       options.DeleteApostrophes,
+		"expand_numex":             options.ExpandNumex,
+		"roman_numerals":           options.RomanNumerals,
+	}
+	enabled := make([]string, 0, len(flags))
+	for name, value := range flags {
+		if value {
+			enabled = append(enabled, name)
+		}
+	}
+	slices.Sort(enabled)
+
+	return []attribute.KeyValue{
+		attribute.StringSlice("libpostal.languages", options.Languages),
+		attribute.Int("libpostal.address_components", int(options.AddressComponents)),
+		attribute.StringSlice("libpostal.options", enabled),
+	}
+}
+
+// traceIDFrom returns trace ID of the span in context, if it is recorded
+func traceIDFrom(ctx context.Context) (string, bool) {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return "", false
+	}
+	return spanContext.TraceID().String(), true
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	gopostalExpand "github.com/openvenues/gopostal/expand"
+	gopostalParser "github.com/openvenues/gopostal/parser"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// useSpanRecorder installs tracer provider, which keeps finished spans in memory
+func useSpanRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return recorder
+}
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attributes := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attributes[kv.Key] = kv.Value
+	}
+	return attributes
+}
+
+func TestSetupTracing(t *testing.T) {
+	t.Run("Disabled", func(t *testing.T) {
+		shutdown, err := setupTracing(context.Background(), &Config{TracingExporter: TracingExporterNone})
+		assert.NoError(t, err)
+		assert.NoError(t, shutdown(context.Background()))
+	})
+
+	t.Run("File Exporter", func(t *testing.T) {
+		previousProvider := otel.GetTracerProvider()
+		t.Cleanup(func() { otel.SetTracerProvider(previousProvider) })
+
+		file := filepath.Join(t.TempDir(), "traces.json")
+		shutdown, err := setupTracing(context.Background(), &Config{
+			TracingExporter:    TracingExporterFile,
+			TracingFile:        file,
+			TracingSampleRatio: 1,
+			TracingServiceName: "postal_server_test",
+		})
+		assert.NoError(t, err)
+
+		_, span := otel.Tracer("test").Start(context.Background(), "test-span")
+		span.End()
+		assert.NoError(t, shutdown(context.Background()))
+
+		content, err := os.ReadFile(file)
+		assert.NoError(t, err)
+		assert.Contains(t, string(content), "test-span")
+		assert.Contains(t, string(content), "postal_server_test")
+	})
+}
+
+func TestServerSpanUsesTraceparent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := useSpanRecorder(t)
+
+	router := gin.New()
+	router.Use(otelgin.Middleware("postal_server"))
+	router.GET("/parse", func(c *gin.Context) {
+		_, span := tracer().Start(c.Request.Context(), "libpostal.parse")
+		span.End()
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/parse?address=test", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(w, req)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+
+	child, server := spans[0], spans[1]
+	assert.Equal(t, "GET /parse", server.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+	assert.Equal(t, server.SpanContext().SpanID(), child.Parent().SpanID())
+}
+
+func TestLibpostalSpans(t *testing.T) {
+	recorder := useSpanRecorder(t)
+	address := "781 Franklin Ave Crown Heights Brooklyn NY 11216 USA"
+
+	parsed := parseAddress(context.Background(), address, gopostalParser.ParserOptions{Language: "en", Country: "us"})
+
+	options := gopostalExpand.GetDefaultExpansionOptions()
+	options.Languages = []string{"en"}
+	expansions := expandAddress(context.Background(), address, options)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+
+	parseSpan := spanAttributes(spans[0])
+	assert.Equal(t, "libpostal.parse", spans[0].Name())
+	assert.Equal(t, "en", parseSpan["libpostal.language"].AsString())
+	assert.Equal(t, "us", parseSpan["libpostal.country"].AsString())
+	assert.Equal(t, int64(len(parsed)), parseSpan["libpostal.result_count"].AsInt64())
+
+	expandSpan := spanAttributes(spans[1])
+	assert.Equal(t, "libpostal.expand", spans[1].Name())
+	assert.Equal(t, []string{"en"}, expandSpan["libpostal.languages"].AsStringSlice())
+	assert.Contains(t, expandSpan["libpostal.options"].AsStringSlice(), "lowercase")
+	assert.Equal(t, int64(len(expansions)), expandSpan["libpostal.result_count"].AsInt64())
+
+	// raw address must never be part of the span
+	for _, span := range spans {
+		for _, kv := range span.Attributes() {
+			assert.NotContains(t, kv.Value.Emit(), "Franklin")
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	_ "embed"
+	"encoding/json"
+	"strings"
+	"unicode"
+)
+
+// StandardUSPS is USPS Publication 28 output mode of /parse and /normalize
+const StandardUSPS string = "usps"
+
+// uspsSecondaryUnknown is designator of secondary number without known designator
+const uspsSecondaryUnknown string = "#"
+
+//go:embed data/usps.json
+var uspsData []byte
+
+// uspsTables are Publication 28 tables (street suffixes from Appendix C1,
+// secondary unit designators from Appendix C2 and directionals), which map
+// any known spelling on standard abbreviation
+type uspsTables struct {
+	suffixes     map[string]string
+	units        map[string]string
+	directionals map[string]string
+}
+
+var usps = mustLoadUSPSTables(uspsData)
+
+// USPSAddress is address in USPS Publication 28 standard form
+type USPSAddress struct {
+	DeliveryLine        string `json:"delivery_line"`
+	LastLine            string `json:"last_line"`
+	PrimaryNumber       string `json:"primary_number,omitempty"`
+	Predirectional      string `json:"predirectional,omitempty"`
+	StreetName          string `json:"street_name,omitempty"`
+	Suffix              string `json:"suffix,omitempty"`
+	Postdirectional     string `json:"postdirectional,omitempty"`
+	SecondaryDesignator string `json:"secondary_designator,omitempty"`
+	SecondaryNumber     string `json:"secondary_number,omitempty"`
+	POBox               string `json:"po_box,omitempty"`
+	City                string `json:"city,omitempty"`
+	State               string `json:"state,omitempty"`
+	ZIPCode             string `json:"zip_code,omitempty"`
+}
+
+func mustLoadUSPSTables(data []byte) uspsTables {
+	var dataset struct {
+		Suffixes     map[string][]string `json:"suffixes"`
+		Units        map[string][]string `json:"units"`
+		Directionals map[string][]string `json:"directionals"`
+	}
+	if err := json.Unmarshal(data, &dataset); err != nil {
+		panic("invalid embedded USPS data: " + err.Error())
+	}
+
+	return uspsTables{
+		suffixes:     uspsAbbreviations(dataset.Suffixes),
+		units:        uspsAbbreviations(dataset.Units),
+		directionals: uspsAbbreviations(dataset.Directionals),
+	}
+}
+
+// uspsAbbreviations reverses table of standard abbreviation -> spellings
+func uspsAbbreviations(table map[string][]string) map[string]string {
+	abbreviations := make(map[string]string)
+	for standard, names := range table {
+		for _, name := range names {
+			abbreviations[name] = standard
+		}
+	}
+	return abbreviations
+}
+
+// uspsTokens uppercases value and removes punctuation, except hyphens,
+// slashes and "#" (Publication 28 keeps them in numbers, e.g. "1/2")
+func uspsTokens(value string) []string {
+	return strings.Fields(strings.Map(func(r rune) rune {
+		switch {
+		case r == '-' || r == '/' || r == '#':
+			return r
+		case unicode.IsPunct(r):
+			if r == '.' || r == '\'' || r == '’' {
+				return -1
+			}
+			return ' '
+		}
+		return unicode.ToUpper(r)
+	}, value))
+}
+
+// formatUSPS maps parsed components (label -> value) into USPS delivery
+// line and last line
+func formatUSPS(components map[string]string) USPSAddress {
+	var address USPSAddress
+
+	address.PrimaryNumber = strings.Join(uspsTokens(components["house_number"]), " ")
+	address.standardizeStreet(uspsTokens(components["road"]))
+	unit := components["unit"]
+	if unit == "" {
+		unit = components["level"]
+	}
+	address.SecondaryDesignator, address.SecondaryNumber = standardizeUSPSUnit(uspsTokens(unit))
+	if poBox := uspsTokens(components["po_box"]); len(poBox) > 0 {
+		address.POBox = poBox[len(poBox)-1]
+	}
+
+	if address.StreetName != "" || address.PrimaryNumber != "" || address.POBox == "" {
+		address.DeliveryLine = joinNonEmpty(
+			address.PrimaryNumber,
+			address.Predirectional,
+			address.StreetName,
+			address.Suffix,
+			address.Postdirectional,
+			address.SecondaryDesignator,
+			address.SecondaryNumber,
+		)
+	} else {
+		address.DeliveryLine = "PO BOX " + address.POBox
+	}
+
+	// USPS city name of NYC boroughs or neighborhoods is parsed as city_district or suburb
+	for _, label := range []string{"city", "city_district", "suburb"} {
+		if city := uspsTokens(components[label]); len(city) > 0 {
+			address.City = strings.Join(city, " ")
+			break
+		}
+	}
+	if code := resolveSubdivisionCode(components["state"], "US"); code != "" {
+		address.State = strings.TrimPrefix(code, "US-")
+	} else {
+		address.State = strings.Join(uspsTokens(components["state"]), " ")
+	}
+	if zip, ok := canonicalPostcode(components["postcode"], "US"); ok {
+		address.ZIPCode = zip
+	} else {
+		address.ZIPCode = strings.Join(uspsTokens(components["postcode"]), " ")
+	}
+	address.LastLine = joinNonEmpty(address.City, address.State, address.ZIPCode)
+
+	return address
+}
+
+// standardizeStreet splits street into directionals, name and suffix.
+// Directional or suffix is abbreviated only if street name is left,
+// so "NORTH ST" and "AVENUE N" are kept as is
+func (address *USPSAddress) standardizeStreet(tokens []string) {
+	if len(tokens) > 1 {
+		if directional, ok := usps.directionals[tokens[len(tokens)-1]]; ok {
+			address.Postdirectional = directional
+			tokens = tokens[:len(tokens)-1]
+		}
+	}
+	if len(tokens) > 1 {
+		if suffix, ok := usps.suffixes[tokens[len(tokens)-1]]; ok {
+			address.Suffix = suffix
+			tokens = tokens[:len(tokens)-1]
+		}
+	}
+	if len(tokens) > 1 {
+		if directional, ok := usps.directionals[tokens[0]]; ok {
+			address.Predirectional = directional
+			tokens = tokens[1:]
+		}
+	}
+	address.StreetName = strings.Join(tokens, " ")
+}
+
+// standardizeUSPSUnit returns secondary unit designator and number
+// ("suite 202" -> "STE", "202"; "3rd floor" -> "FL", "3RD"; "#5" -> "#", "5")
+func standardizeUSPSUnit(tokens []string) (string, string) {
+	if len(tokens) == 0 {
+		return "", ""
+	}
+	if number, ok := strings.CutPrefix(tokens[0], uspsSecondaryUnknown); ok {
+		if number != "" {
+			tokens = append([]string{number}, tokens[1:]...)
+		} else {
+			tokens = tokens[1:]
+		}
+		return uspsSecondaryUnknown, strings.Join(tokens, " ")
+	}
+	if designator, ok := usps.units[tokens[0]]; ok {
+		return designator, strings.Join(tokens[1:], " ")
+	}
+	if len(tokens) == 2 {
+		if designator, ok := usps.units[tokens[1]]; ok {
+			return designator, tokens[0]
+		}
+	}
+	return uspsSecondaryUnknown, strings.Join(tokens, " ")
+}
+
+// joinNonEmpty joins non empty values with space
+func joinNonEmpty(values ...string) string {
+	parts := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, " ")
+}
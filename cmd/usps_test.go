@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatUSPS(t *testing.T) {
+	tests := []struct {
+		name         string
+		components   map[string]string
+		deliveryLine string
+		lastLine     string
+	}{
+		// examples of secondary unit designators from Publication 28
+		{
+			name:         "Apartment",
+			components:   map[string]string{"house_number": "102", "road": "main street", "unit": "apartment 101"},
+			deliveryLine: "102 MAIN ST APT 101",
+		},
+		{
+			name:         "Suite",
+			components:   map[string]string{"house_number": "1356", "road": "executive drive", "unit": "suite 202"},
+			deliveryLine: "1356 EXECUTIVE DR STE 202",
+		},
+		{
+			name:         "Building",
+			components:   map[string]string{"house_number": "1600", "road": "central place", "unit": "building 14"},
+			deliveryLine: "1600 CENTRAL PL BLDG 14",
+		},
+		{
+			name:         "Room",
+			components:   map[string]string{"house_number": "55", "road": "sylvan boulevard", "unit": "room 18"},
+			deliveryLine: "55 SYLVAN BLVD RM 18",
+		},
+		{
+			name:         "Directionals",
+			components:   map[string]string{"house_number": "1200", "road": "north main street southwest"},
+			deliveryLine: "1200 N MAIN ST SW",
+		},
+		{
+			name:         "Directional Is Street Name",
+			components:   map[string]string{"house_number": "28", "road": "north street"},
+			deliveryLine: "28 NORTH ST",
+		},
+		{
+			name:         "Unknown Designator",
+			components:   map[string]string{"house_number": "7", "road": "elm st.", "unit": "#5"},
+			deliveryLine: "7 ELM ST # 5",
+		},
+		{
+			name:         "Floor",
+			components:   map[string]string{"house_number": "350", "road": "fifth avenue", "level": "3rd floor"},
+			deliveryLine: "350 FIFTH AVE FL 3RD",
+		},
+		{
+			name:         "PO Box",
+			components:   map[string]string{"po_box": "p.o. box 1234", "city": "anytown", "state": "virginia", "postcode": "22030"},
+			deliveryLine: "PO BOX 1234",
+			lastLine:     "ANYTOWN VA 22030",
+		},
+		{
+			name: "Last Line",
+			components: map[string]string{
+				"house_number":  "781",
+				"road":          "franklin ave",
+				"suburb":        "crown heights",
+				"city_district": "brooklyn",
+				"state":         "ny",
+				"postcode":      "11216 1234",
+			},
+			deliveryLine: "781 FRANKLIN AVE",
+			lastLine:     "BROOKLYN NY 11216-1234",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address := formatUSPS(tt.components)
+			assert.Equal(t, tt.deliveryLine, address.DeliveryLine)
+			assert.Equal(t, tt.lastLine, address.LastLine)
+		})
+	}
+}
+
+func TestFormatUSPSComponents(t *testing.T) {
+	address := formatUSPS(map[string]string{
+		"house_number": "1356",
+		"road":         "e executive dr",
+		"unit":         "ste 202",
+		"city":         "new york",
+		"state":        "new york",
+		"postcode":     "10001",
+	})
+	assert.Equal(t, USPSAddress{
+		DeliveryLine:        "1356 E EXECUTIVE DR STE 202",
+		LastLine:            "NEW YORK NY 10001",
+		PrimaryNumber:       "1356",
+		Predirectional:      "E",
+		StreetName:          "EXECUTIVE",
+		Suffix:              "DR",
+		SecondaryDesignator: "STE",
+		SecondaryNumber:     "202",
+		City:                "NEW YORK",
+		State:               "NY",
+		ZIPCode:             "10001",
+	}, address)
+}
+
+func TestParseRouteUSPS(t *testing.T) {
+	router := SetupRouter()
+
+	t.Run("USPS Standard", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		address := url.QueryEscape("781 Franklin Ave Crown Heights Brooklyn NY 11216 USA")
+		req, _ := http.NewRequest(http.MethodGet, "/parse?address="+address+"&standard=usps", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response USPSAddress
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "781 FRANKLIN AVE", response.DeliveryLine)
+		assert.Equal(t, "BROOKLYN NY 11216", response.LastLine)
+	})
+
+	t.Run("Unsupported Standard", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/parse?address=test&standard=royal_mail", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `unsupported standard \"royal_mail\"`)
+	})
+}
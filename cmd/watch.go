@@ -0,0 +1,383 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog"
+)
+
+// default wait time after last change of the file, before it is processed
+const watchDefaultSettle time.Duration = 2 * time.Second
+
+// formats of files, which are processed by watcher. Other files
+// (e.g. partial uploads with ".part" or ".tmp" suffix) are ignored
+var watchFileFormats = map[string]string{
+	".csv":    RecordFormatCSV,
+	".ndjson": RecordFormatNDJSON,
+	".jsonl":  RecordFormatNDJSON,
+}
+
+// WatchOptions are options of directory watcher
+type WatchOptions struct {
+	InputDir  string
+	OutputDir string
+	DoneDir   string
+	FailedDir string
+	Operation string
+	// options of the operation, columns with address and output format,
+	// same as for async jobs
+	Options url.Values
+	// file is processed, if it was not changed during this time
+	Settle time.Duration
+}
+
+// WatchErrorReport is written next to file, which failed
+type WatchErrorReport struct {
+	File      string    `json:"file"`
+	Operation string    `json:"operation"`
+	Error     string    `json:"error"`
+	Rows      int       `json:"rows"`
+	FailedAt  time.Time `json:"failed_at"`
+}
+
+// withDefaults returns options with default directories inside input directory
+func (o WatchOptions) withDefaults() WatchOptions {
+	if o.OutputDir == "" {
+		o.OutputDir = filepath.Join(o.InputDir, "output")
+	}
+	if o.DoneDir == "" {
+		o.DoneDir = filepath.Join(o.InputDir, "done")
+	}
+	if o.FailedDir == "" {
+		o.FailedDir = filepath.Join(o.InputDir, "failed")
+	}
+	if o.Settle <= 0 {
+		o.Settle = watchDefaultSettle
+	}
+	if o.Options == nil {
+		o.Options = url.Values{}
+	}
+	return o
+}
+
+// watchFileFormat returns format of the file, if file is processed by watcher
+func watchFileFormat(path string) (string, bool) {
+	name := filepath.Base(path)
+	if strings.HasPrefix(name, ".") {
+		return "", false
+	}
+	format, ok := watchFileFormats[strings.ToLower(filepath.Ext(name))]
+	return format, ok
+}
+
+// watchDirectory processes files, which are already in input directory and
+// every new one, until ctx is canceled
+func watchDirectory(ctx context.Context, options WatchOptions) error {
+	options = options.withDefaults()
+	// operation options are checked before any file is moved to failed directory
+	if _, err := newRecordProcessor(options.Operation, options.Options); err != nil {
+		return err
+	}
+	if format := options.Options.Get("output_format"); format != "" && format != RecordFormatCSV && format != RecordFormatNDJSON {
+		return fmt.Errorf("unsupported output format %q, must be one of %s, %s", format, RecordFormatCSV, RecordFormatNDJSON)
+	}
+	input, err := os.Stat(options.InputDir)
+	if err != nil {
+		return err
+	}
+	dirs := []struct{ name, path string }{
+		{"output_dir", options.OutputDir},
+		{"done_dir", options.DoneDir},
+		{"failed_dir", options.FailedDir},
+	}
+	for _, dir := range dirs {
+		if err := os.MkdirAll(dir.path, 0o750); err != nil {
+			return err
+		}
+		// files moved into input directory would be processed again and again
+		if info, err := os.Stat(dir.path); err == nil && os.SameFile(input, info) {
+			return fmt.Errorf("%s must not be same as input directory, got %q", dir.name, dir.path)
+		}
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	if err := watcher.Add(options.InputDir); err != nil {
+		return err
+	}
+
+	logger := zerolog.Ctx(ctx)
+	logger.Info().
+		Str("input_dir", options.InputDir).
+		Str("output_dir", options.OutputDir).
+		Str("operation", options.Operation).
+		Msg("Watching directory")
+
+	// last change of files, which are not processed yet
+	pending := make(map[string]time.Time)
+	// files dropped while watcher was not running
+	if err := scanWatchedDir(options.InputDir, pending, time.Time{}); err != nil {
+		return err
+	}
+
+	// files are processed by separate goroutine, so events are read while
+	// large file is processed and fsnotify buffer does not overflow
+	files := make(chan string)
+	results := make(chan error, 1)
+	processing := ""
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		for path := range files {
+			results <- processWatchedFile(ctx, options, path)
+		}
+	})
+	defer func() {
+		close(files)
+		wg.Wait()
+	}()
+
+	// processNext starts processing of the first settled file in order of names
+	processNext := func(now time.Time) {
+		if processing != "" {
+			return
+		}
+		for _, path := range slices.Sorted(maps.Keys(pending)) {
+			if now.Sub(pending[path]) < options.Settle {
+				continue
+			}
+			delete(pending, path)
+			processing = path
+			files <- path
+			return
+		}
+	}
+
+	ticker := time.NewTicker(max(options.Settle/4, 10*time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-results:
+			processing = ""
+			if err != nil {
+				return err
+			}
+			processNext(time.Now())
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if _, supported := watchFileFormat(event.Name); !supported {
+				continue
+			}
+			switch {
+			case event.Has(fsnotify.Create), event.Has(fsnotify.Write):
+				pending[event.Name] = time.Now()
+			case event.Has(fsnotify.Remove), event.Has(fsnotify.Rename):
+				delete(pending, event.Name)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			logger.Warn().Err(err).Msg("Directory watch error")
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				// events are lost, files are found by same scan as on start.
+				// File, which is processed now, is not in directory after processing
+				if err := scanWatchedDir(options.InputDir, pending, time.Now()); err != nil {
+					return err
+				}
+				delete(pending, processing)
+			}
+		case now := <-ticker.C:
+			processNext(now)
+		}
+	}
+}
+
+// scanWatchedDir adds supported files of the directory into pending files
+// with changedAt time
+func scanWatchedDir(dir string, pending map[string]time.Time, changedAt time.Time) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if _, ok := watchFileFormat(path); ok && entry.Type().IsRegular() {
+			pending[path] = changedAt
+		}
+	}
+	return nil
+}
+
+// processWatchedFile writes result of the file into output directory and moves
+// file into done directory. File, which can not be processed, is moved into
+// failed directory with error report. Returned error stops the watcher
+func processWatchedFile(ctx context.Context, options WatchOptions, path string) error {
+	logger := zerolog.Ctx(ctx).With().Str("file", path).Logger()
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && !info.Mode().IsRegular()) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(path)
+	outputName := strings.TrimSuffix(name, filepath.Ext(name)) + "." + jobOutputFormat(options.Operation, options.Options)
+	rows, processErr := processWatchedRecords(ctx, options, path, filepath.Join(options.OutputDir, outputName))
+	if ctx.Err() != nil {
+		// interrupted by shutdown, file is processed again on next start
+		return nil
+	}
+
+	if processErr == nil {
+		donePath, err := moveIntoDir(path, options.DoneDir)
+		if err != nil {
+			return err
+		}
+		logger.Info().Int("rows", rows).Str("output", filepath.Join(options.OutputDir, outputName)).Str("done", donePath).Msg("File processed")
+		return nil
+	}
+
+	failedPath, err := moveIntoDir(path, options.FailedDir)
+	if err != nil {
+		return err
+	}
+	report := WatchErrorReport{
+		File:      filepath.Base(failedPath),
+		Operation: options.Operation,
+		Error:     processErr.Error(),
+		Rows:      rows,
+		FailedAt:  time.Now().UTC(),
+	}
+	if err := writeJSONFile(failedPath+".error.json", report); err != nil {
+		return err
+	}
+	logger.Error().Err(processErr).Int("rows", rows).Str("failed", failedPath).Msg("File failed")
+	return nil
+}
+
+// processWatchedRecords writes NDJSON (or CSV) result of every input row. Output
+// file appears only when every row is processed. Returns number of processed rows
+func processWatchedRecords(ctx context.Context, options WatchOptions, inputPath, outputPath string) (int, error) {
+	values := maps.Clone(options.Options)
+	if values.Get("format") == "" {
+		format, _ := watchFileFormat(inputPath)
+		values.Set("format", format)
+	}
+	process, err := newRecordProcessor(options.Operation, values)
+	if err != nil {
+		return 0, err
+	}
+
+	input, err := os.Open(inputPath)
+	if err != nil {
+		return 0, err
+	}
+	defer input.Close()
+	reader, err := newRecordReader(input, values.Get("format"), values["column"])
+	if err != nil {
+		return 0, err
+	}
+
+	// downstream consumers of output directory must not see partial file
+	tmpPath := filepath.Join(filepath.Dir(outputPath), "."+filepath.Base(outputPath)+".tmp")
+	output, err := os.Create(tmpPath)
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmpPath)
+	defer output.Close()
+	buffered := bufio.NewWriter(output)
+	encoder := json.NewEncoder(buffered)
+
+	rows := 0
+	for {
+		address, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return rows, fmt.Errorf("row %d: %w", rows+1, err)
+		}
+		result, err := processRecord(ctx, process, rows+1, address)
+		if err != nil {
+			return rows, err
+		}
+		result.Columns = reader.Passthrough()
+		if err := encoder.Encode(result); err != nil {
+			return rows, err
+		}
+		rows++
+	}
+
+	if err := buffered.Flush(); err != nil {
+		return rows, err
+	}
+	if jobOutputFormat(options.Operation, values) == RecordFormatCSV {
+		return rows, convertWatchedResults(output, tmpPath, outputPath, options.Operation, values, reader.PassthroughColumns())
+	}
+	if err := output.Close(); err != nil {
+		return rows, err
+	}
+	return rows, os.Rename(tmpPath, outputPath)
+}
+
+// convertWatchedResults writes CSV output from NDJSON results
+func convertWatchedResults(results *os.File, resultsPath, outputPath, operation string, values url.Values, passthrough []string) error {
+	if _, err := results.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	tmpPath := resultsPath + ".csv"
+	output, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+	defer output.Close()
+
+	buffered := bufio.NewWriter(output)
+	if err := writeResultsCSV(results, buffered, operation, values, passthrough); err != nil {
+		return err
+	}
+	if err := buffered.Flush(); err != nil {
+		return err
+	}
+	if err := output.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, outputPath)
+}
+
+// moveIntoDir moves file into directory. If directory has file with same
+// name, time is added to the name
+func moveIntoDir(path, dir string) (string, error) {
+	target := filepath.Join(dir, filepath.Base(path))
+	if _, err := os.Stat(target); err == nil {
+		target = filepath.Join(dir, time.Now().UTC().Format("20060102T150405.000000000Z")+"-"+filepath.Base(path))
+	}
+	if err := os.Rename(path, target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// watchCmd processes files, which are dropped into directory
+var watchCmd = &cobra.Command{
+	Use:   "watch <input-dir>",
+	Short: "Process CSV or NDJSON files dropped into directory",
+	Long: `Watch input directory and process every new CSV or NDJSON file (and files, which are
+already there) through parse, expand or normalize. Result is written as NDJSON into output
+directory, original file is moved into done directory, or into failed directory together
+with error report`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// config is loaded once, max_concurrency is applied to every row
+		cfg, err := LoadConfig(viper.GetViper())
+		if err != nil {
+			return err
+		}
+		activeConfig.Store(cfg)
+
+		flags := cmd.Flags()
+		options := WatchOptions{InputDir: args[0], Options: url.Values{}}
+		options.OutputDir, _ = flags.GetString("output_dir")
+		options.DoneDir, _ = flags.GetString("done_dir")
+		options.FailedDir, _ = flags.GetString("failed_dir")
+		options.Operation, _ = flags.GetString("operation")
+		options.Settle, _ = flags.GetDuration("settle")
+		options.Options["column"], _ = flags.GetStringArray("column")
+		for _, flag := range []string{"output_format", "result_prefix", "language", "country", "standard"} {
+			if value, _ := flags.GetString(flag); value != "" {
+				options.Options.Set(flag, value)
+			}
+		}
+		extraOptions, _ := flags.GetStringArray("option")
+		for _, option := range extraOptions {
+			key, value, ok := strings.Cut(option, "=")
+			if !ok || key == "" {
+				return fmt.Errorf("option must be key=value, got %q", option)
+			}
+			options.Options.Add(key, value)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		return watchDirectory(log.Logger.WithContext(ctx), options)
+	},
+}
+
+func init() {
+	flags := watchCmd.Flags()
+	flags.String("output_dir", "", "directory of results (default output in input directory)")
+	flags.String("done_dir", "", "directory of processed files (default done in input directory)")
+	flags.String("failed_dir", "", "directory of failed files and error reports (default failed in input directory)")
+	flags.String("operation", JobOperationParse, "operation: parse, expand or normalize")
+	flags.StringArray("column", []string{defaultRecordColumn}, "CSV column or NDJSON field with address (repeat for address split into several columns)")
+	flags.String("output_format", RecordFormatNDJSON, "output format, ndjson or csv (CSV has other input columns and column per result component)")
+	flags.String("result_prefix", "", "prefix of result columns of CSV output (e.g. parsed_)")
+	flags.String("language", "", "language of addresses for libpostal parser and expansions")
+	flags.String("country", "", "country of addresses for libpostal parser")
+	flags.String("standard", "", "output standard of parse and normalize: usps, schema_org, vcard, osm or libaddressinput")
+	flags.StringArray("option", nil, "other option of the operation as key=value, same as query parameter of the endpoint (e.g. second_pass=true)")
+	flags.Duration("settle", watchDefaultSettle, "file is processed, if it was not changed during this time (upload is finished)")
+
+	rootCmd.AddCommand(watchCmd)
+}
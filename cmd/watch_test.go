@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchFileFormat(t *testing.T) {
+	tests := []struct {
+		path     string
+		format   string
+		accepted bool
+	}{
+		{path: "in/addresses.csv", format: RecordFormatCSV, accepted: true},
+		{path: "in/ADDRESSES.CSV", format: RecordFormatCSV, accepted: true},
+		{path: "in/addresses.ndjson", format: RecordFormatNDJSON, accepted: true},
+		{path: "in/addresses.jsonl", format: RecordFormatNDJSON, accepted: true},
+		{path: "in/addresses.csv.part", accepted: false},
+		{path: "in/addresses.txt", accepted: false},
+		{path: "in/.addresses.csv", accepted: false},
+	}
+	for _, tt := range tests {
+		format, ok := watchFileFormat(tt.path)
+		assert.Equal(t, tt.accepted, ok, tt.path)
+		assert.Equal(t, tt.format, format, tt.path)
+	}
+}
+
+func TestScanWatchedDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"partner.csv", "partner.csv.part", ".partner.csv", "partner.jsonl"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o640))
+	}
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "archive.csv"), 0o750))
+
+	changedAt := time.Now()
+	pending := map[string]time.Time{filepath.Join(dir, "partner.csv"): {}}
+	assert.NoError(t, scanWatchedDir(dir, pending, changedAt))
+	assert.Equal(t, map[string]time.Time{
+		filepath.Join(dir, "partner.csv"):   changedAt,
+		filepath.Join(dir, "partner.jsonl"): changedAt,
+	}, pending)
+
+	assert.Error(t, scanWatchedDir(filepath.Join(dir, "missing"), pending, changedAt))
+}
+
+func TestProcessWatchedFile(t *testing.T) {
+	newOptions := func(t *testing.T) WatchOptions {
+		options := WatchOptions{
+			InputDir:  t.TempDir(),
+			Operation: JobOperationExpand,
+			Options:   url.Values{"column": {"street"}},
+		}.withDefaults()
+		for _, dir := range []string{options.OutputDir, options.DoneDir, options.FailedDir} {
+			assert.NoError(t, os.MkdirAll(dir, 0o750))
+		}
+		return options
+	}
+
+	t.Run("Processed", func(t *testing.T) {
+		options := newOptions(t)
+		path := filepath.Join(options.InputDir, "partner.csv")
+		assert.NoError(t, os.WriteFile(path, []byte("id,street\n1,10 Downing St\n2,781 Franklin Ave\n"), 0o640))
+
+		assert.NoError(t, processWatchedFile(context.Background(), options, path))
+
+		assert.NoFileExists(t, path)
+		assert.FileExists(t, filepath.Join(options.DoneDir, "partner.csv"))
+
+		output, err := os.ReadFile(filepath.Join(options.OutputDir, "partner.ndjson"))
+		assert.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+		if assert.Len(t, lines, 2) {
+			var row JobResultRow
+			assert.NoError(t, json.Unmarshal([]byte(lines[1]), &row))
+			assert.Equal(t, 2, row.Row)
+			assert.Equal(t, "781 Franklin Ave", row.Address)
+		}
+		// temporary output file is removed
+		entries, _ := os.ReadDir(options.OutputDir)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("CSV Output", func(t *testing.T) {
+		options := newOptions(t)
+		options.Options.Set("output_format", RecordFormatCSV)
+		path := filepath.Join(options.InputDir, "partner.csv")
+		assert.NoError(t, os.WriteFile(path, []byte("id,street\n1,10 Downing St\n"), 0o640))
+
+		assert.NoError(t, processWatchedFile(context.Background(), options, path))
+
+		output, err := os.ReadFile(filepath.Join(options.OutputDir, "partner.csv"))
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(output), "id,address,expansion_1"), string(output))
+		assert.Contains(t, string(output), "\n1,10 Downing St,")
+		entries, _ := os.ReadDir(options.OutputDir)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		options := newOptions(t)
+		path := filepath.Join(options.InputDir, "partner.ndjson")
+		assert.NoError(t, os.WriteFile(path, []byte("{\"street\": \"10 Downing St\"}\n{\"street\": 10}\n"), 0o640))
+
+		assert.NoError(t, processWatchedFile(context.Background(), options, path))
+
+		assert.NoFileExists(t, path)
+		assert.FileExists(t, filepath.Join(options.FailedDir, "partner.ndjson"))
+		assert.NoFileExists(t, filepath.Join(options.OutputDir, "partner.ndjson"))
+
+		data, err := os.ReadFile(filepath.Join(options.FailedDir, "partner.ndjson.error.json"))
+		assert.NoError(t, err)
+		var report WatchErrorReport
+		assert.NoError(t, json.Unmarshal(data, &report))
+		assert.Equal(t, "partner.ndjson", report.File)
+		assert.Equal(t, JobOperationExpand, report.Operation)
+		assert.Equal(t, 1, report.Rows)
+		assert.Contains(t, report.Error, "row 2:")
+	})
+
+	t.Run("Removed Before Processing", func(t *testing.T) {
+		options := newOptions(t)
+		assert.NoError(t, processWatchedFile(context.Background(), options, filepath.Join(options.InputDir, "missing.csv")))
+	})
+
+	t.Run("Same Name Processed Again", func(t *testing.T) {
+		options := newOptions(t)
+		for range 2 {
+			path := filepath.Join(options.InputDir, "daily.csv")
+			assert.NoError(t, os.WriteFile(path, []byte("street\n10 Downing St\n"), 0o640))
+			assert.NoError(t, processWatchedFile(context.Background(), options, path))
+		}
+
+		entries, err := os.ReadDir(options.DoneDir)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 2)
+	})
+}
+
+func TestWatchDirectory(t *testing.T) {
+	inputDir := t.TempDir()
+	// file, which was dropped before watcher was started
+	assert.NoError(t, os.WriteFile(filepath.Join(inputDir, "first.csv"), []byte("address\n10 Downing St\n"), 0o640))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- watchDirectory(ctx, WatchOptions{
+			InputDir:  inputDir,
+			Operation: JobOperationParse,
+			Settle:    50 * time.Millisecond,
+		})
+	}()
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(inputDir, "done", "first.csv"))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// upload is finished with rename, partial file is ignored
+	assert.NoError(t, os.WriteFile(filepath.Join(inputDir, "second.csv.part"), []byte("address\n781 Franklin Ave\n"), 0o640))
+	assert.NoError(t, os.Rename(filepath.Join(inputDir, "second.csv.part"), filepath.Join(inputDir, "second.csv")))
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(inputDir, "done", "second.csv"))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.FileExists(t, filepath.Join(inputDir, "output", "first.ndjson"))
+	assert.FileExists(t, filepath.Join(inputDir, "output", "second.ndjson"))
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestWatchDirectoryInvalidOperation(t *testing.T) {
+	err := watchDirectory(context.Background(), WatchOptions{InputDir: t.TempDir(), Operation: "geocode"})
+	assert.ErrorContains(t, err, `unsupported operation "geocode"`)
+}
+
+func TestWatchDirectorySameAsInput(t *testing.T) {
+	inputDir := t.TempDir()
+	for name, options := range map[string]WatchOptions{
+		"output_dir": {InputDir: inputDir, OutputDir: inputDir},
+		"done_dir":   {InputDir: inputDir, DoneDir: filepath.Join(inputDir, ".")},
+		"failed_dir": {InputDir: inputDir, FailedDir: filepath.Join(inputDir, "failed", "..")},
+	} {
+		options.Operation = JobOperationParse
+		err := watchDirectory(context.Background(), options)
+		assert.ErrorContains(t, err, name+" must not be same as input directory", name)
+	}
+}
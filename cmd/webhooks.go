@@ -0,0 +1,292 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// statuses of job webhook
+const (
+	WebhookStatusPending   string = "pending"
+	WebhookStatusDelivered string = "delivered"
+	WebhookStatusFailed    string = "failed"
+)
+
+// headers of webhook request
+const (
+	WebhookEventHeader     string = "X-Postal-Server-Event"
+	WebhookTimestampHeader string = "X-Postal-Server-Timestamp"
+	WebhookSignatureHeader string = "X-Postal-Server-Signature"
+)
+
+const (
+	// delay before second attempt, it is doubled for every next attempt
+	webhookDefaultBackoff time.Duration = time.Second
+	webhookMaxBackoff     time.Duration = 10 * time.Minute
+	// only beginning of response body is kept in delivery log
+	webhookMaxResponseBody int64 = 512
+)
+
+// JobWebhook is callback of the job, which is called when job is done or failed
+type JobWebhook struct {
+	URL        string            `json:"url"`
+	Status     string            `json:"status"`
+	Deliveries []WebhookDelivery `json:"deliveries,omitempty"`
+}
+
+// WebhookDelivery is one delivery attempt of job webhook
+type WebhookDelivery struct {
+	Attempt    int       `json:"attempt"`
+	Event      string    `json:"event"`
+	At         time.Time `json:"at"`
+	DurationMs int64     `json:"duration_ms"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// nonPublicPrefixes are special-purpose networks, which are not covered by
+// netip.Addr methods (shared address space, benchmarking, reserved)
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// errWebhookAddressNotAllowed is returned for connection to non-public address
+var errWebhookAddressNotAllowed = errors.New("address is not public, set webhook_allow_private_networks to allow it")
+
+// isPublicAddr returns false for loopback, private, link-local (e.g. cloud
+// metadata 169.254.169.254) and other non-public addresses
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// webhookDialControl rejects connections to non-public addresses. It is
+// called with resolved address, so host name resolved into private address
+// is rejected too
+func webhookDialControl(network, address string, _ syscall.RawConn) error {
+	if currentConfig().WebhookAllowPrivateNetworks {
+		return nil
+	}
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%s: %w", address, err)
+	}
+	if !isPublicAddr(addrPort.Addr()) {
+		return fmt.Errorf("%s: %w", address, errWebhookAddressNotAllowed)
+	}
+	return nil
+}
+
+// newWebhookClient returns client of webhook deliveries. Callback URL is
+// set by API client, so server must not be used to reach internal services:
+// non-public addresses are rejected and redirects are not followed. Proxy
+// from environment is not used, dial check would see proxy address instead
+// of callback host
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   webhookDialControl,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// WebhookNotification is body of webhook request
+type WebhookNotification struct {
+	Event  string    `json:"event"`
+	SentAt time.Time `json:"sent_at"`
+	Job    Job       `json:"job"`
+}
+
+// validateWebhookURL checks callback URL of the job
+func validateWebhookURL(rawURL string) error {
+	callback, err := url.Parse(rawURL)
+	if err != nil || (callback.Scheme != "http" && callback.Scheme != "https") || callback.Host == "" {
+		return fmt.Errorf("callback_url must be absolute http or https URL, got %q", rawURL)
+	}
+	if currentConfig().WebhookSecret == "" {
+		return errors.New("callback_url requires webhook_secret to be configured")
+	}
+	return nil
+}
+
+// signWebhook returns signature of webhook body. Timestamp is signed too,
+// so receiver can reject replayed requests
+func signWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookEvent returns event of finished job
+func webhookEvent(job *Job) string {
+	return "job." + job.Status
+}
+
+// webhookBackoff returns delay before the attempt
+func webhookBackoff(base time.Duration, attempt int) time.Duration {
+	delay := base
+	for range attempt - 2 {
+		delay *= 2
+		if delay >= webhookMaxBackoff {
+			return webhookMaxBackoff
+		}
+	}
+	return delay
+}
+
+// deliverWebhook sends notification of finished job until receiver accepts
+// it or attempts are exhausted. Every attempt is saved in delivery log of
+// the job, so delivery continues after restart
+func (m *jobManager) deliverWebhook(id string) {
+	for {
+		m.mu.Lock()
+		job, ok := m.jobs[id]
+		if !ok || job.Webhook == nil || job.Webhook.Status != WebhookStatusPending {
+			m.mu.Unlock()
+			return
+		}
+		attempt := len(job.Webhook.Deliveries) + 1
+		callbackURL := job.Webhook.URL
+		notification := WebhookNotification{Event: webhookEvent(job), Job: job.snapshot()}
+		notification.Job.Webhook = nil
+		m.mu.Unlock()
+
+		if attempt > 1 {
+			timer := time.NewTimer(webhookBackoff(m.webhookBackoff, attempt))
+			select {
+			case <-timer.C:
+			case <-m.ctx.Done():
+				timer.Stop()
+				return
+			}
+		}
+
+		delivery := m.sendWebhook(callbackURL, notification, attempt)
+		if m.ctx.Err() != nil {
+			// interrupted by shutdown, attempt is repeated on next start
+			return
+		}
+
+		m.mu.Lock()
+		job, ok = m.jobs[id]
+		if !ok {
+			m.mu.Unlock()
+			return
+		}
+		job.Webhook.Deliveries = append(job.Webhook.Deliveries, delivery)
+		switch {
+		case delivery.Error == "":
+			job.Webhook.Status = WebhookStatusDelivered
+		case attempt >= currentConfig().WebhookMaxAttempts:
+			job.Webhook.Status = WebhookStatusFailed
+		}
+		status := job.Webhook.Status
+		m.save(job)
+		m.mu.Unlock()
+
+		logger := zerolog.Ctx(m.ctx).With().
+			Str("job_id", id).
+			Int("attempt", attempt).
+			Int("status_code", delivery.StatusCode).
+			Logger()
+		switch status {
+		case WebhookStatusDelivered:
+			logger.Info().Msg("Webhook delivered")
+			return
+		case WebhookStatusFailed:
+			logger.Error().Str("error", delivery.Error).Msg("Webhook delivery failed, no attempts left")
+			return
+		}
+		logger.Warn().Str("error", delivery.Error).Msg("Webhook delivery failed, retrying")
+	}
+}
+
+// sendWebhook makes one delivery attempt
+func (m *jobManager) sendWebhook(callbackURL string, notification WebhookNotification, attempt int) WebhookDelivery {
+	cfg := currentConfig()
+	start := time.Now()
+	delivery := WebhookDelivery{Attempt: attempt, Event: notification.Event, At: start.UTC()}
+
+	statusCode, err := func() (int, error) {
+		if cfg.WebhookSecret == "" {
+			return 0, errors.New("webhook_secret is not configured")
+		}
+		notification.SentAt = start.UTC()
+		body, err := json.Marshal(notification)
+		if err != nil {
+			return 0, err
+		}
+
+		ctx, cancel := context.WithTimeout(m.ctx, time.Duration(cfg.WebhookTimeout)*time.Second)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, bytes.NewReader(body))
+		if err != nil {
+			return 0, err
+		}
+		timestamp := strconv.FormatInt(start.Unix(), 10)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "postal_server")
+		req.Header.Set(WebhookEventHeader, notification.Event)
+		req.Header.Set(WebhookTimestampHeader, timestamp)
+		req.Header.Set(WebhookSignatureHeader, signWebhook(cfg.WebhookSecret, timestamp, body))
+
+		resp, err := m.webhookClient.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			message, _ := io.ReadAll(io.LimitReader(resp.Body, webhookMaxResponseBody))
+			return resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+		}
+		io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, nil
+	}()
+
+	delivery.DurationMs = time.Since(start).Milliseconds()
+	delivery.StatusCode = statusCode
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+	return delivery
+}
@@ -0,0 +1,333 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// webhookReceiver records notifications and responds with statuses in order
+// (last status is repeated)
+type webhookReceiver struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	statuses      []int
+	notifications []WebhookNotification
+	headers       []http.Header
+	bodies        [][]byte
+}
+
+func newWebhookReceiver(t *testing.T, statuses ...int) *webhookReceiver {
+	receiver := &webhookReceiver{statuses: statuses}
+	receiver.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var notification WebhookNotification
+		json.Unmarshal(body, &notification)
+
+		receiver.mu.Lock()
+		defer receiver.mu.Unlock()
+		receiver.notifications = append(receiver.notifications, notification)
+		receiver.headers = append(receiver.headers, r.Header.Clone())
+		receiver.bodies = append(receiver.bodies, body)
+		status := receiver.statuses[min(len(receiver.notifications), len(receiver.statuses))-1]
+		w.WriteHeader(status)
+		if status != http.StatusOK {
+			w.Write([]byte("try again later"))
+		}
+	}))
+	t.Cleanup(receiver.Close)
+	return receiver
+}
+
+func (r *webhookReceiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.notifications)
+}
+
+// request returns notification, headers and body of i-th request
+func (r *webhookReceiver) request(i int) (WebhookNotification, http.Header, []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.notifications[i], r.headers[i], r.bodies[i]
+}
+
+// waitForWebhook waits until webhook of the job is delivered or failed
+func waitForWebhook(t *testing.T, manager *jobManager, id string) Job {
+	t.Helper()
+	var job Job
+	assert.Eventually(t, func() bool {
+		job, _ = manager.get(id)
+		return job.Webhook != nil && job.Webhook.Status != WebhookStatusPending
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func webhookConfig() *Config {
+	cfg := validConfig()
+	cfg.WebhookSecret = "webhook-secret"
+	// test receivers listen on loopback
+	cfg.WebhookAllowPrivateNetworks = true
+	return cfg
+}
+
+func TestJobWebhooks(t *testing.T) {
+	ndjson := url.Values{"format": {RecordFormatNDJSON}}
+
+	t.Run("Signed Notification", func(t *testing.T) {
+		useConfig(t, webhookConfig())
+		manager := useTestJobManager(t)
+		receiver := newWebhookReceiver(t, http.StatusOK)
+
+		job, _, err := manager.create(JobOperationParse, ndjson, strings.NewReader("\"10 Downing St\"\n"), "", receiver.URL+"/hooks")
+		assert.NoError(t, err)
+		assert.Equal(t, &JobWebhook{URL: receiver.URL + "/hooks", Status: WebhookStatusPending}, job.Webhook)
+
+		job = waitForWebhook(t, manager, job.ID)
+		assert.Equal(t, WebhookStatusDelivered, job.Webhook.Status)
+		if !assert.Len(t, job.Webhook.Deliveries, 1) {
+			return
+		}
+		assert.Equal(t, 1, job.Webhook.Deliveries[0].Attempt)
+		assert.Equal(t, "job.done", job.Webhook.Deliveries[0].Event)
+		assert.Equal(t, http.StatusOK, job.Webhook.Deliveries[0].StatusCode)
+		assert.Empty(t, job.Webhook.Deliveries[0].Error)
+
+		notification, headers, body := receiver.request(0)
+		assert.Equal(t, "job.done", notification.Event)
+		assert.Equal(t, job.ID, notification.Job.ID)
+		assert.Equal(t, JobStatusDone, notification.Job.Status)
+		assert.Equal(t, 1, notification.Job.Progress.Rows)
+		assert.Nil(t, notification.Job.Webhook)
+
+		assert.Equal(t, "application/json", headers.Get("Content-Type"))
+		assert.Equal(t, "job.done", headers.Get(WebhookEventHeader))
+		assert.Equal(t, signWebhook("webhook-secret", headers.Get(WebhookTimestampHeader), body), headers.Get(WebhookSignatureHeader))
+		assert.NotEqual(t, signWebhook("other-secret", headers.Get(WebhookTimestampHeader), body), headers.Get(WebhookSignatureHeader))
+	})
+
+	t.Run("Failed Job", func(t *testing.T) {
+		useConfig(t, webhookConfig())
+		manager := useTestJobManager(t)
+		receiver := newWebhookReceiver(t, http.StatusNoContent)
+
+		options := url.Values{"format": {RecordFormatCSV}, "column": {"full_address"}}
+		job, _, err := manager.create(JobOperationParse, options, strings.NewReader("address\n10 Downing St\n"), "", receiver.URL)
+		assert.NoError(t, err)
+
+		job = waitForWebhook(t, manager, job.ID)
+		assert.Equal(t, WebhookStatusDelivered, job.Webhook.Status)
+		notification, _, _ := receiver.request(0)
+		assert.Equal(t, "job.failed", notification.Event)
+		assert.Contains(t, notification.Job.Error, `column "full_address" is not found`)
+	})
+
+	t.Run("Retry With Backoff", func(t *testing.T) {
+		useConfig(t, webhookConfig())
+		manager := useTestJobManager(t)
+		manager.webhookBackoff = time.Millisecond
+		receiver := newWebhookReceiver(t, http.StatusInternalServerError, http.StatusBadGateway, http.StatusOK)
+
+		job, _, err := manager.create(JobOperationParse, ndjson, strings.NewReader("\"10 Downing St\"\n"), "", receiver.URL)
+		assert.NoError(t, err)
+
+		job = waitForWebhook(t, manager, job.ID)
+		assert.Equal(t, WebhookStatusDelivered, job.Webhook.Status)
+		assert.Equal(t, 3, receiver.count())
+		if !assert.Len(t, job.Webhook.Deliveries, 3) {
+			return
+		}
+		assert.Equal(t, http.StatusInternalServerError, job.Webhook.Deliveries[0].StatusCode)
+		assert.Equal(t, "unexpected status 500: try again later", job.Webhook.Deliveries[0].Error)
+		assert.Equal(t, http.StatusBadGateway, job.Webhook.Deliveries[1].StatusCode)
+		assert.Equal(t, 3, job.Webhook.Deliveries[2].Attempt)
+		assert.Empty(t, job.Webhook.Deliveries[2].Error)
+	})
+
+	t.Run("Attempts Exhausted", func(t *testing.T) {
+		cfg := webhookConfig()
+		cfg.WebhookMaxAttempts = 2
+		useConfig(t, cfg)
+		manager := useTestJobManager(t)
+		manager.webhookBackoff = time.Millisecond
+		receiver := newWebhookReceiver(t, http.StatusServiceUnavailable)
+
+		job, _, err := manager.create(JobOperationParse, ndjson, strings.NewReader("\"10 Downing St\"\n"), "", receiver.URL)
+		assert.NoError(t, err)
+
+		job = waitForWebhook(t, manager, job.ID)
+		assert.Equal(t, WebhookStatusFailed, job.Webhook.Status)
+		assert.Len(t, job.Webhook.Deliveries, 2)
+		assert.Equal(t, 2, receiver.count())
+	})
+
+	t.Run("Private Network", func(t *testing.T) {
+		cfg := webhookConfig()
+		cfg.WebhookAllowPrivateNetworks = false
+		cfg.WebhookMaxAttempts = 1
+		useConfig(t, cfg)
+		manager := useTestJobManager(t)
+		receiver := newWebhookReceiver(t, http.StatusOK)
+
+		job, _, err := manager.create(JobOperationParse, ndjson, strings.NewReader("\"10 Downing St\"\n"), "", receiver.URL)
+		assert.NoError(t, err)
+
+		job = waitForWebhook(t, manager, job.ID)
+		assert.Equal(t, WebhookStatusFailed, job.Webhook.Status)
+		if assert.Len(t, job.Webhook.Deliveries, 1) {
+			assert.Contains(t, job.Webhook.Deliveries[0].Error, "address is not public")
+		}
+		assert.Equal(t, 0, receiver.count())
+	})
+
+	t.Run("Proxy Is Not Used", func(t *testing.T) {
+		cfg := webhookConfig()
+		cfg.WebhookAllowPrivateNetworks = false
+		cfg.WebhookMaxAttempts = 1
+		useConfig(t, cfg)
+		proxy := newWebhookReceiver(t, http.StatusOK)
+		t.Setenv("HTTP_PROXY", proxy.URL)
+		t.Setenv("HTTPS_PROXY", proxy.URL)
+		manager := useTestJobManager(t)
+
+		assert.Nil(t, manager.webhookClient.Transport.(*http.Transport).Proxy)
+
+		job, _, err := manager.create(JobOperationParse, ndjson, strings.NewReader("\"10 Downing St\"\n"), "", "http://10.0.0.1:8080/hooks")
+		assert.NoError(t, err)
+
+		// callback host is checked, not address of the proxy
+		job = waitForWebhook(t, manager, job.ID)
+		assert.Equal(t, WebhookStatusFailed, job.Webhook.Status)
+		if assert.Len(t, job.Webhook.Deliveries, 1) {
+			assert.Contains(t, job.Webhook.Deliveries[0].Error, "10.0.0.1:8080: address is not public")
+		}
+		assert.Equal(t, 0, proxy.count())
+	})
+
+	t.Run("Redirect Not Followed", func(t *testing.T) {
+		cfg := webhookConfig()
+		cfg.WebhookMaxAttempts = 1
+		useConfig(t, cfg)
+		manager := useTestJobManager(t)
+		target := newWebhookReceiver(t, http.StatusOK)
+		redirect := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+		t.Cleanup(redirect.Close)
+
+		job, _, err := manager.create(JobOperationParse, ndjson, strings.NewReader("\"10 Downing St\"\n"), "", redirect.URL)
+		assert.NoError(t, err)
+
+		job = waitForWebhook(t, manager, job.ID)
+		assert.Equal(t, WebhookStatusFailed, job.Webhook.Status)
+		if assert.Len(t, job.Webhook.Deliveries, 1) {
+			assert.Equal(t, http.StatusTemporaryRedirect, job.Webhook.Deliveries[0].StatusCode)
+		}
+		assert.Equal(t, 0, target.count())
+	})
+
+	t.Run("Delivery Resumed After Restart", func(t *testing.T) {
+		useConfig(t, webhookConfig())
+		receiver := newWebhookReceiver(t, http.StatusOK)
+
+		dir := t.TempDir()
+		assert.NoError(t, os.Mkdir(filepath.Join(dir, "finished"), 0o750))
+		finishedAt := time.Now().UTC()
+		assert.NoError(t, writeJSONFile(filepath.Join(dir, "finished", jobFile), Job{
+			ID:         "finished",
+			Operation:  JobOperationParse,
+			Status:     JobStatusDone,
+			Options:    ndjson,
+			CreatedAt:  finishedAt,
+			FinishedAt: &finishedAt,
+			Webhook: &JobWebhook{
+				URL:        receiver.URL,
+				Status:     WebhookStatusPending,
+				Deliveries: []WebhookDelivery{{Attempt: 1, Event: "job.done", Error: "connection refused"}},
+			},
+		}))
+
+		manager, err := newJobManager(dir, 1, 0)
+		assert.NoError(t, err)
+		defer manager.shutdown()
+
+		job := waitForWebhook(t, manager, "finished")
+		assert.Equal(t, WebhookStatusDelivered, job.Webhook.Status)
+		if assert.Len(t, job.Webhook.Deliveries, 2) {
+			assert.Equal(t, 2, job.Webhook.Deliveries[1].Attempt)
+		}
+	})
+
+	t.Run("Idempotency Key With Other Callback", func(t *testing.T) {
+		useConfig(t, webhookConfig())
+		manager := useTestJobManager(t)
+
+		_, _, err := manager.create(JobOperationParse, ndjson, strings.NewReader("\"10 Downing St\"\n"), "upload-1", "http://localhost:9/first")
+		assert.NoError(t, err)
+
+		_, _, err = manager.create(JobOperationParse, ndjson, strings.NewReader("\"10 Downing St\"\n"), "upload-1", "http://localhost:9/second")
+		assert.ErrorIs(t, err, ErrIdempotencyKeyReused)
+	})
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		useConfig(t, webhookConfig())
+		assert.NoError(t, validateWebhookURL("https://example.com/hooks?source=postal"))
+	})
+
+	t.Run("Invalid URL", func(t *testing.T) {
+		useConfig(t, webhookConfig())
+		for _, callbackURL := range []string{"example.com/hooks", "ftp://example.com", "/hooks", "https://"} {
+			assert.ErrorContains(t, validateWebhookURL(callbackURL), "callback_url must be absolute http or https URL")
+		}
+	})
+
+	t.Run("No Secret", func(t *testing.T) {
+		useConfig(t, validConfig())
+		assert.EqualError(t, validateWebhookURL("https://example.com/hooks"), "callback_url requires webhook_secret to be configured")
+	})
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	for _, addr := range []string{"93.184.216.34", "2606:2800:220:1:248:1893:25c8:1946", "::ffff:93.184.216.34"} {
+		assert.True(t, isPublicAddr(netip.MustParseAddr(addr)), addr)
+	}
+	for _, addr := range []string{
+		"127.0.0.1", "::1", "10.0.0.1", "172.16.0.1", "192.168.1.1", "169.254.169.254", "fe80::1",
+		"fd00::1", "0.0.0.0", "::", "100.64.0.1", "224.0.0.1", "255.255.255.255", "::ffff:127.0.0.1",
+	} {
+		assert.False(t, isPublicAddr(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, webhookBackoff(time.Second, 2))
+	assert.Equal(t, 2*time.Second, webhookBackoff(time.Second, 3))
+	assert.Equal(t, 64*time.Second, webhookBackoff(time.Second, 8))
+	assert.Equal(t, webhookMaxBackoff, webhookBackoff(time.Second, 20))
+}
+
+func TestJobRoutesCallbackURL(t *testing.T) {
+	useConfig(t, validConfig())
+	useTestJobManager(t)
+	router := SetupRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/jobs?operation=parse&callback_url=https%3A%2F%2Fexample.com%2Fhooks", strings.NewReader("\"10 Downing St\"\n"))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "callback_url requires webhook_secret to be configured")
+}
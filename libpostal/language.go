@@ -0,0 +1,59 @@
+package libpostal
+
+/*
+#include <libpostal/libpostal.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"cmp"
+	"slices"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// Language is language of the address (ISO 639-1 code) with its probability
+type Language struct {
+	Language    string  `json:"language"`
+	Probability float64 `json:"probability"`
+}
+
+// ClassifyLanguage returns languages of the address, detected by libpostal
+// language classifier, from the most probable
+func ClassifyLanguage(address string) []Language {
+	if !utf8.ValidString(address) {
+		return nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	cAddress := C.CString(address)
+	defer C.free(unsafe.Pointer(cAddress))
+
+	response := C.libpostal_classify_language(cAddress)
+	if response == nil {
+		return nil
+	}
+	defer C.libpostal_language_classifier_response_destroy(response)
+
+	n := int(response.num_languages)
+	if n == 0 {
+		return []Language{}
+	}
+	cLanguages := unsafe.Slice(response.languages, n)
+	cProbs := unsafe.Slice(response.probs, n)
+
+	languages := make([]Language, 0, n)
+	for i := range n {
+		languages = append(languages, Language{
+			Language:    C.GoString(cLanguages[i]),
+			Probability: float64(cProbs[i]),
+		})
+	}
+	slices.SortStableFunc(languages, func(a, b Language) int {
+		return cmp.Compare(b.Probability, a.Probability)
+	})
+	return languages
+}
@@ -0,0 +1,20 @@
+package libpostal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyLanguage(t *testing.T) {
+	languages := ClassifyLanguage("781 Franklin Ave Crown Heights Brooklyn NY 11216 USA")
+	if assert.NotEmpty(t, languages) {
+		assert.Equal(t, "en", languages[0].Language)
+	}
+	for i := 1; i < len(languages); i++ {
+		assert.GreaterOrEqual(t, languages[i-1].Probability, languages[i].Probability)
+	}
+
+	// invalid UTF-8 is not passed to libpostal
+	assert.Nil(t, ClassifyLanguage("\xff\xfe"))
+}
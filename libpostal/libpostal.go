@@ -0,0 +1,36 @@
+// Package libpostal contains cgo bindings for libpostal functions, which
+// are not wrapped by gopostal (language classifier, tokenizer and string
+// normalization)
+package libpostal
+
+/*
+#cgo pkg-config: libpostal
+#include <libpostal/libpostal.h>
+*/
+import "C"
+
+import (
+	"log"
+	"sync"
+)
+
+// mu serializes every call into libpostal. gopostal locks expand and parse
+// with own mutexes only, but libpostal has global state, which is shared by
+// them and by functions of this package (e.g. language classifier is used by
+// expand and classify_language), so gopostal calls are made under mu too (see
+// WithLock)
+var mu sync.Mutex
+
+// WithLock runs fn under the lock of libpostal calls. Every gopostal call
+// must be made inside it
+func WithLock(fn func()) {
+	mu.Lock()
+	defer mu.Unlock()
+	fn()
+}
+
+func init() {
+	if !bool(C.libpostal_setup()) || !bool(C.libpostal_setup_language_classifier()) {
+		log.Fatal("Could not load libpostal")
+	}
+}
@@ -0,0 +1,37 @@
+package libpostal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithLock(t *testing.T) {
+	locked := make(chan struct{})
+	release := make(chan struct{})
+	go WithLock(func() {
+		close(locked)
+		<-release
+	})
+	<-locked
+
+	// functions of the package wait for gopostal call, which holds the lock
+	done := make(chan struct{})
+	go func() {
+		Tokenize("781 Franklin Ave", false)
+		close(done)
+	}()
+	select {
+	case <-done:
+		assert.Fail(t, "Tokenize did not wait for the lock")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "Tokenize was not called after the lock was released")
+	}
+}
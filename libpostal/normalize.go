@@ -0,0 +1,121 @@
+package libpostal
+
+/*
+#include <libpostal/libpostal.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"unicode/utf8"
+	"unsafe"
+)
+
+// string normalization options of NormalizeString and NormalizedTokens
+const (
+	NormalizeStringLatinASCII       uint64 = C.LIBPOSTAL_NORMALIZE_STRING_LATIN_ASCII
+	NormalizeStringTransliterate    uint64 = C.LIBPOSTAL_NORMALIZE_STRING_TRANSLITERATE
+	NormalizeStringStripAccents     uint64 = C.LIBPOSTAL_NORMALIZE_STRING_STRIP_ACCENTS
+	NormalizeStringDecompose        uint64 = C.LIBPOSTAL_NORMALIZE_STRING_DECOMPOSE
+	NormalizeStringLowercase        uint64 = C.LIBPOSTAL_NORMALIZE_STRING_LOWERCASE
+	NormalizeStringTrim             uint64 = C.LIBPOSTAL_NORMALIZE_STRING_TRIM
+	NormalizeStringReplaceHyphens   uint64 = C.LIBPOSTAL_NORMALIZE_STRING_REPLACE_HYPHENS
+	NormalizeStringCompose          uint64 = C.LIBPOSTAL_NORMALIZE_STRING_COMPOSE
+	NormalizeStringSimpleLatinASCII uint64 = C.LIBPOSTAL_NORMALIZE_STRING_SIMPLE_LATIN_ASCII
+	NormalizeStringReplaceNumex     uint64 = C.LIBPOSTAL_NORMALIZE_STRING_REPLACE_NUMEX
+
+	NormalizeDefaultStringOptions uint64 = C.LIBPOSTAL_NORMALIZE_DEFAULT_STRING_OPTIONS
+)
+
+// token normalization options of NormalizedTokens
+const (
+	NormalizeTokenReplaceHyphens             uint64 = C.LIBPOSTAL_NORMALIZE_TOKEN_REPLACE_HYPHENS
+	NormalizeTokenDeleteHyphens              uint64 = C.LIBPOSTAL_NORMALIZE_TOKEN_DELETE_HYPHENS
+	NormalizeTokenDeleteFinalPeriod          uint64 = C.LIBPOSTAL_NORMALIZE_TOKEN_DELETE_FINAL_PERIOD
+	NormalizeTokenDeleteAcronymPeriods       uint64 = C.LIBPOSTAL_NORMALIZE_TOKEN_DELETE_ACRONYM_PERIODS
+	NormalizeTokenDropEnglishPossessives     uint64 = C.LIBPOSTAL_NORMALIZE_TOKEN_DROP_ENGLISH_POSSESSIVES
+	NormalizeTokenDeleteOtherApostrophe      uint64 = C.LIBPOSTAL_NORMALIZE_TOKEN_DELETE_OTHER_APOSTROPHE
+	NormalizeTokenSplitAlphaFromNumeric      uint64 = C.LIBPOSTAL_NORMALIZE_TOKEN_SPLIT_ALPHA_FROM_NUMERIC
+	NormalizeTokenReplaceDigits              uint64 = C.LIBPOSTAL_NORMALIZE_TOKEN_REPLACE_DIGITS
+	NormalizeTokenReplaceNumericTokenLetters uint64 = C.LIBPOSTAL_NORMALIZE_TOKEN_REPLACE_NUMERIC_TOKEN_LETTERS
+	NormalizeTokenReplaceNumericHyphens      uint64 = C.LIBPOSTAL_NORMALIZE_TOKEN_REPLACE_NUMERIC_HYPHENS
+
+	NormalizeDefaultTokenOptions uint64 = C.LIBPOSTAL_NORMALIZE_DEFAULT_TOKEN_OPTIONS
+)
+
+// NormalizedToken is normalized form of the token. Offset and length refer
+// to the string, which libpostal normalized before tokenization, not to
+// the input
+type NormalizedToken struct {
+	Normalized string `json:"normalized"`
+	Type       string `json:"type"`
+	Offset     int    `json:"offset"`
+	Length     int    `json:"length"`
+}
+
+// NormalizeString normalizes input with string options (NormalizeString* flags),
+// independent of address expansion
+func NormalizeString(input string, options uint64) string {
+	if !utf8.ValidString(input) {
+		return ""
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	cInput := C.CString(input)
+	defer C.free(unsafe.Pointer(cInput))
+
+	return normalizeString(cInput, options)
+}
+
+// normalizeString calls libpostal string normalization, caller holds mu
+func normalizeString(cInput *C.char, options uint64) string {
+	cNormalized := C.libpostal_normalize_string(cInput, C.uint64_t(options))
+	if cNormalized == nil {
+		return ""
+	}
+	defer C.free(unsafe.Pointer(cNormalized))
+
+	return C.GoString(cNormalized)
+}
+
+// NormalizedTokens tokenizes input and normalizes every token with string
+// (NormalizeString* flags) and token (NormalizeToken* flags) options.
+// Whitespace tokens are returned only if whitespace is true
+func NormalizedTokens(input string, stringOptions, tokenOptions uint64, whitespace bool) []NormalizedToken {
+	if !utf8.ValidString(input) {
+		return nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	cInput := C.CString(input)
+	defer C.free(unsafe.Pointer(cInput))
+
+	var n C.size_t
+	cTokens := C.libpostal_normalized_tokens(
+		cInput,
+		C.uint64_t(stringOptions),
+		C.uint64_t(tokenOptions),
+		C.bool(whitespace),
+		&n,
+	)
+	if cTokens == nil {
+		return nil
+	}
+	defer C.free(unsafe.Pointer(cTokens))
+
+	tokens := make([]NormalizedToken, 0, int(n))
+	for _, token := range unsafe.Slice(cTokens, int(n)) {
+		tokens = append(tokens, NormalizedToken{
+			Normalized: C.GoString(token.str),
+			Type:       tokenTypeName(token.token._type),
+			Offset:     int(token.token.offset),
+			Length:     int(token.token.len),
+		})
+		C.free(unsafe.Pointer(token.str))
+	}
+	return tokens
+}
@@ -0,0 +1,37 @@
+package libpostal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeString(t *testing.T) {
+	assert.Equal(t, "781 franklin ave", NormalizeString("781 Franklin Ave", NormalizeDefaultStringOptions))
+	assert.Equal(t, "781 Franklin Ave", NormalizeString("781 Franklin Ave", NormalizeStringTrim))
+
+	// invalid UTF-8 is not passed to libpostal
+	assert.Equal(t, "", NormalizeString("\xff\xfe", NormalizeDefaultStringOptions))
+}
+
+func TestNormalizedTokens(t *testing.T) {
+	tokens := NormalizedTokens("781 Franklin Ave", NormalizeDefaultStringOptions, NormalizeDefaultTokenOptions, false)
+	if assert.Len(t, tokens, 3) {
+		assert.Equal(t, "franklin", tokens[1].Normalized)
+		assert.Equal(t, "word", tokens[1].Type)
+		assert.Equal(t, 4, tokens[1].Offset)
+		assert.Equal(t, 8, tokens[1].Length)
+	}
+
+	// transliteration changes the string, every token is still returned
+	for _, input := range []string{"北京市朝阳区", "Straße 5, Köln", "улица Ленина 10"} {
+		options := NormalizeDefaultStringOptions | NormalizeStringTransliterate
+		tokens := NormalizedTokens(input, options, NormalizeDefaultTokenOptions, false)
+		assert.NotEmpty(t, tokens, input)
+		for _, token := range tokens {
+			assert.NotEmpty(t, token.Normalized, input)
+		}
+	}
+
+	assert.Nil(t, NormalizedTokens("\xff\xfe", NormalizeDefaultStringOptions, NormalizeDefaultTokenOptions, false))
+}
@@ -0,0 +1,119 @@
+package libpostal
+
+/*
+#include <libpostal/libpostal.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"unicode/utf8"
+	"unsafe"
+)
+
+// Token is token of the input with its type. Offset and length are in bytes
+type Token struct {
+	Token  string `json:"token"`
+	Type   string `json:"type"`
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+}
+
+// tokenTypes are names of libpostal token types
+var tokenTypes = map[C.uint16_t]string{
+	C.LIBPOSTAL_TOKEN_TYPE_END:                "end",
+	C.LIBPOSTAL_TOKEN_TYPE_WORD:               "word",
+	C.LIBPOSTAL_TOKEN_TYPE_ABBREVIATION:       "abbreviation",
+	C.LIBPOSTAL_TOKEN_TYPE_IDEOGRAPHIC_CHAR:   "ideographic_char",
+	C.LIBPOSTAL_TOKEN_TYPE_HANGUL_SYLLABLE:    "hangul_syllable",
+	C.LIBPOSTAL_TOKEN_TYPE_ACRONYM:            "acronym",
+	C.LIBPOSTAL_TOKEN_TYPE_PHRASE:             "phrase",
+	C.LIBPOSTAL_TOKEN_TYPE_EMAIL:              "email",
+	C.LIBPOSTAL_TOKEN_TYPE_URL:                "url",
+	C.LIBPOSTAL_TOKEN_TYPE_US_PHONE:           "us_phone",
+	C.LIBPOSTAL_TOKEN_TYPE_INTL_PHONE:         "intl_phone",
+	C.LIBPOSTAL_TOKEN_TYPE_NUMERIC:            "numeric",
+	C.LIBPOSTAL_TOKEN_TYPE_ORDINAL:            "ordinal",
+	C.LIBPOSTAL_TOKEN_TYPE_ROMAN_NUMERAL:      "roman_numeral",
+	C.LIBPOSTAL_TOKEN_TYPE_IDEOGRAPHIC_NUMBER: "ideographic_number",
+	C.LIBPOSTAL_TOKEN_TYPE_PERIOD:             "period",
+	C.LIBPOSTAL_TOKEN_TYPE_EXCLAMATION:        "exclamation",
+	C.LIBPOSTAL_TOKEN_TYPE_QUESTION_MARK:      "question_mark",
+	C.LIBPOSTAL_TOKEN_TYPE_COMMA:              "comma",
+	C.LIBPOSTAL_TOKEN_TYPE_COLON:              "colon",
+	C.LIBPOSTAL_TOKEN_TYPE_SEMICOLON:          "semicolon",
+	C.LIBPOSTAL_TOKEN_TYPE_PLUS:               "plus",
+	C.LIBPOSTAL_TOKEN_TYPE_AMPERSAND:          "ampersand",
+	C.LIBPOSTAL_TOKEN_TYPE_AT_SIGN:            "at_sign",
+	C.LIBPOSTAL_TOKEN_TYPE_POUND:              "pound",
+	C.LIBPOSTAL_TOKEN_TYPE_ELLIPSIS:           "ellipsis",
+	C.LIBPOSTAL_TOKEN_TYPE_DASH:               "dash",
+	C.LIBPOSTAL_TOKEN_TYPE_BREAKING_DASH:      "breaking_dash",
+	C.LIBPOSTAL_TOKEN_TYPE_HYPHEN:             "hyphen",
+	C.LIBPOSTAL_TOKEN_TYPE_PUNCT_OPEN:         "punct_open",
+	C.LIBPOSTAL_TOKEN_TYPE_PUNCT_CLOSE:        "punct_close",
+	C.LIBPOSTAL_TOKEN_TYPE_DOUBLE_QUOTE:       "double_quote",
+	C.LIBPOSTAL_TOKEN_TYPE_SINGLE_QUOTE:       "single_quote",
+	C.LIBPOSTAL_TOKEN_TYPE_OPEN_QUOTE:         "open_quote",
+	C.LIBPOSTAL_TOKEN_TYPE_CLOSE_QUOTE:        "close_quote",
+	C.LIBPOSTAL_TOKEN_TYPE_SLASH:              "slash",
+	C.LIBPOSTAL_TOKEN_TYPE_BACKSLASH:          "backslash",
+	C.LIBPOSTAL_TOKEN_TYPE_GREATER_THAN:       "greater_than",
+	C.LIBPOSTAL_TOKEN_TYPE_LESS_THAN:          "less_than",
+	C.LIBPOSTAL_TOKEN_TYPE_OTHER_PUNCTUATION:  "other_punctuation",
+	C.LIBPOSTAL_TOKEN_TYPE_OTHER:              "other",
+	C.LIBPOSTAL_TOKEN_TYPE_WHITESPACE:         "whitespace",
+	C.LIBPOSTAL_TOKEN_TYPE_NEWLINE:            "newline",
+	C.LIBPOSTAL_TOKEN_TYPE_INVALID_CHAR:       "invalid_char",
+}
+
+// tokenTypeName returns name of libpostal token type
+func tokenTypeName(tokenType C.uint16_t) string {
+	if name, ok := tokenTypes[tokenType]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// newToken returns token of the input. Token out of input (should not be
+// returned by libpostal) has empty text instead of panic
+func newToken(input string, token C.libpostal_token_t) Token {
+	offset, length := int(token.offset), int(token.len)
+	text := ""
+	if offset >= 0 && length >= 0 && offset+length <= len(input) {
+		text = input[offset : offset+length]
+	}
+	return Token{
+		Token:  text,
+		Type:   tokenTypeName(token._type),
+		Offset: offset,
+		Length: length,
+	}
+}
+
+// Tokenize splits input into tokens with libpostal tokenizer. Whitespace
+// tokens are returned only if whitespace is true
+func Tokenize(input string, whitespace bool) []Token {
+	if !utf8.ValidString(input) {
+		return nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	cInput := C.CString(input)
+	defer C.free(unsafe.Pointer(cInput))
+
+	var n C.size_t
+	cTokens := C.libpostal_tokenize(cInput, C.bool(whitespace), &n)
+	if cTokens == nil {
+		return nil
+	}
+	defer C.free(unsafe.Pointer(cTokens))
+
+	tokens := make([]Token, 0, int(n))
+	for _, token := range unsafe.Slice(cTokens, int(n)) {
+		tokens = append(tokens, newToken(input, token))
+	}
+	return tokens
+}
@@ -0,0 +1,30 @@
+package libpostal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	tokens := Tokenize("Quatre-vingt-douze Ave des Champs-Élysées", false)
+	if assert.NotEmpty(t, tokens) {
+		assert.Equal(t, "word", tokens[0].Type)
+	}
+	for _, token := range tokens {
+		assert.NotEqual(t, "whitespace", token.Type)
+		assert.Equal(t, len(token.Token), token.Length)
+	}
+
+	tokens = Tokenize("781 Franklin Ave", true)
+	assert.Equal(t, []Token{
+		{Token: "781", Type: "numeric", Offset: 0, Length: 3},
+		{Token: " ", Type: "whitespace", Offset: 3, Length: 1},
+		{Token: "Franklin", Type: "word", Offset: 4, Length: 8},
+		{Token: " ", Type: "whitespace", Offset: 12, Length: 1},
+		{Token: "Ave", Type: "word", Offset: 13, Length: 3},
+	}, tokens)
+
+	// invalid UTF-8 is not passed to libpostal
+	assert.Nil(t, Tokenize("\xff\xfe", false))
+}
@@ -0,0 +1,2749 @@
+// Protobuf responses of postal_server (Accept: application/x-protobuf).
+// Field names are same as names of JSON response fields.
+//
+// Go code is generated with:
+//
+//   protoc --go_out=. --go_opt=paths=source_relative postalpb/postal_server.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: postalpb/postal_server.proto
+
+package postalpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Error is response of failed request
+type Error struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Error         string                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	RequestId     string                 `protobuf:"bytes,2,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Error) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Error) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+// Health is response of GET /health
+type Health struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Health) Reset() {
+	*x = Health{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Health) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Health) ProtoMessage() {}
+
+func (x *Health) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Health.ProtoReflect.Descriptor instead.
+func (*Health) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Health) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+// Version is response of GET /
+type Version struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Version) Reset() {
+	*x = Version{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Version) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Version) ProtoMessage() {}
+
+func (x *Version) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Version.ProtoReflect.Descriptor instead.
+func (*Version) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Version) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+// ParsedComponent is component of parsed address
+type ParsedComponent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Label         string                 `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	CountryCode   string                 `protobuf:"bytes,3,opt,name=country_code,json=countryCode,proto3" json:"country_code,omitempty"`
+	StateCode     string                 `protobuf:"bytes,4,opt,name=state_code,json=stateCode,proto3" json:"state_code,omitempty"`
+	Canonical     string                 `protobuf:"bytes,5,opt,name=canonical,proto3" json:"canonical,omitempty"`
+	Valid         *bool                  `protobuf:"varint,6,opt,name=valid,proto3,oneof" json:"valid,omitempty"`
+	ParsedAs      []string               `protobuf:"bytes,7,rep,name=parsed_as,json=parsedAs,proto3" json:"parsed_as,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ParsedComponent) Reset() {
+	*x = ParsedComponent{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParsedComponent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParsedComponent) ProtoMessage() {}
+
+func (x *ParsedComponent) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParsedComponent.ProtoReflect.Descriptor instead.
+func (*ParsedComponent) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ParsedComponent) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *ParsedComponent) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *ParsedComponent) GetCountryCode() string {
+	if x != nil {
+		return x.CountryCode
+	}
+	return ""
+}
+
+func (x *ParsedComponent) GetStateCode() string {
+	if x != nil {
+		return x.StateCode
+	}
+	return ""
+}
+
+func (x *ParsedComponent) GetCanonical() string {
+	if x != nil {
+		return x.Canonical
+	}
+	return ""
+}
+
+func (x *ParsedComponent) GetValid() bool {
+	if x != nil && x.Valid != nil {
+		return *x.Valid
+	}
+	return false
+}
+
+func (x *ParsedComponent) GetParsedAs() []string {
+	if x != nil {
+		return x.ParsedAs
+	}
+	return nil
+}
+
+// ParseResponse is response of /parse
+type ParseResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Components    []*ParsedComponent     `protobuf:"bytes,1,rep,name=components,proto3" json:"components,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ParseResponse) Reset() {
+	*x = ParseResponse{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParseResponse) ProtoMessage() {}
+
+func (x *ParseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParseResponse.ProtoReflect.Descriptor instead.
+func (*ParseResponse) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ParseResponse) GetComponents() []*ParsedComponent {
+	if x != nil {
+		return x.Components
+	}
+	return nil
+}
+
+// ExpandResponse is response of GET /expand
+type ExpandResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Expansions    []string               `protobuf:"bytes,1,rep,name=expansions,proto3" json:"expansions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExpandResponse) Reset() {
+	*x = ExpandResponse{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExpandResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExpandResponse) ProtoMessage() {}
+
+func (x *ExpandResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExpandResponse.ProtoReflect.Descriptor instead.
+func (*ExpandResponse) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ExpandResponse) GetExpansions() []string {
+	if x != nil {
+		return x.Expansions
+	}
+	return nil
+}
+
+// CanonicalExpansion is response of GET /expand with canonical parameter
+type CanonicalExpansion struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Canonical     string                 `protobuf:"bytes,1,opt,name=canonical,proto3" json:"canonical,omitempty"`
+	Hash          string                 `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	RuleVersion   int32                  `protobuf:"varint,3,opt,name=rule_version,json=ruleVersion,proto3" json:"rule_version,omitempty"`
+	Expansions    []string               `protobuf:"bytes,4,rep,name=expansions,proto3" json:"expansions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CanonicalExpansion) Reset() {
+	*x = CanonicalExpansion{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CanonicalExpansion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CanonicalExpansion) ProtoMessage() {}
+
+func (x *CanonicalExpansion) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CanonicalExpansion.ProtoReflect.Descriptor instead.
+func (*CanonicalExpansion) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CanonicalExpansion) GetCanonical() string {
+	if x != nil {
+		return x.Canonical
+	}
+	return ""
+}
+
+func (x *CanonicalExpansion) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *CanonicalExpansion) GetRuleVersion() int32 {
+	if x != nil {
+		return x.RuleVersion
+	}
+	return 0
+}
+
+func (x *CanonicalExpansion) GetExpansions() []string {
+	if x != nil {
+		return x.Expansions
+	}
+	return nil
+}
+
+// ExplainedExpansion is expansion with transformations of the address
+type ExplainedExpansion struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Expansion       string                 `protobuf:"bytes,1,opt,name=expansion,proto3" json:"expansion,omitempty"`
+	Transformations []string               `protobuf:"bytes,2,rep,name=transformations,proto3" json:"transformations,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ExplainedExpansion) Reset() {
+	*x = ExplainedExpansion{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExplainedExpansion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExplainedExpansion) ProtoMessage() {}
+
+func (x *ExplainedExpansion) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExplainedExpansion.ProtoReflect.Descriptor instead.
+func (*ExplainedExpansion) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ExplainedExpansion) GetExpansion() string {
+	if x != nil {
+		return x.Expansion
+	}
+	return ""
+}
+
+func (x *ExplainedExpansion) GetTransformations() []string {
+	if x != nil {
+		return x.Transformations
+	}
+	return nil
+}
+
+// ExplainResponse is response of GET /expand with explain parameter
+type ExplainResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Expansions    []*ExplainedExpansion  `protobuf:"bytes,1,rep,name=expansions,proto3" json:"expansions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExplainResponse) Reset() {
+	*x = ExplainResponse{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExplainResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExplainResponse) ProtoMessage() {}
+
+func (x *ExplainResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExplainResponse.ProtoReflect.Descriptor instead.
+func (*ExplainResponse) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ExplainResponse) GetExpansions() []*ExplainedExpansion {
+	if x != nil {
+		return x.Expansions
+	}
+	return nil
+}
+
+// NormalizedComponent is parsed component with its expansions
+type NormalizedComponent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Label         string                 `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Canonical     string                 `protobuf:"bytes,3,opt,name=canonical,proto3" json:"canonical,omitempty"`
+	Expansions    []string               `protobuf:"bytes,4,rep,name=expansions,proto3" json:"expansions,omitempty"`
+	ParsedAs      []string               `protobuf:"bytes,5,rep,name=parsed_as,json=parsedAs,proto3" json:"parsed_as,omitempty"`
+	Valid         *bool                  `protobuf:"varint,6,opt,name=valid,proto3,oneof" json:"valid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NormalizedComponent) Reset() {
+	*x = NormalizedComponent{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NormalizedComponent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NormalizedComponent) ProtoMessage() {}
+
+func (x *NormalizedComponent) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NormalizedComponent.ProtoReflect.Descriptor instead.
+func (*NormalizedComponent) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *NormalizedComponent) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *NormalizedComponent) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *NormalizedComponent) GetCanonical() string {
+	if x != nil {
+		return x.Canonical
+	}
+	return ""
+}
+
+func (x *NormalizedComponent) GetExpansions() []string {
+	if x != nil {
+		return x.Expansions
+	}
+	return nil
+}
+
+func (x *NormalizedComponent) GetParsedAs() []string {
+	if x != nil {
+		return x.ParsedAs
+	}
+	return nil
+}
+
+func (x *NormalizedComponent) GetValid() bool {
+	if x != nil && x.Valid != nil {
+		return *x.Valid
+	}
+	return false
+}
+
+// NormalizedAddress is response of /normalize
+type NormalizedAddress struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Canonical     map[string]string      `protobuf:"bytes,1,rep,name=canonical,proto3" json:"canonical,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Components    []*NormalizedComponent `protobuf:"bytes,2,rep,name=components,proto3" json:"components,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NormalizedAddress) Reset() {
+	*x = NormalizedAddress{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NormalizedAddress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NormalizedAddress) ProtoMessage() {}
+
+func (x *NormalizedAddress) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NormalizedAddress.ProtoReflect.Descriptor instead.
+func (*NormalizedAddress) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *NormalizedAddress) GetCanonical() map[string]string {
+	if x != nil {
+		return x.Canonical
+	}
+	return nil
+}
+
+func (x *NormalizedAddress) GetComponents() []*NormalizedComponent {
+	if x != nil {
+		return x.Components
+	}
+	return nil
+}
+
+// NormalizedComponents is response of POST /expand
+type NormalizedComponents struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Components    []*NormalizedComponent `protobuf:"bytes,1,rep,name=components,proto3" json:"components,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NormalizedComponents) Reset() {
+	*x = NormalizedComponents{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NormalizedComponents) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NormalizedComponents) ProtoMessage() {}
+
+func (x *NormalizedComponents) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NormalizedComponents.ProtoReflect.Descriptor instead.
+func (*NormalizedComponents) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *NormalizedComponents) GetComponents() []*NormalizedComponent {
+	if x != nil {
+		return x.Components
+	}
+	return nil
+}
+
+// Language is language of the address with its probability
+type Language struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Language      string                 `protobuf:"bytes,1,opt,name=language,proto3" json:"language,omitempty"`
+	Probability   float64                `protobuf:"fixed64,2,opt,name=probability,proto3" json:"probability,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Language) Reset() {
+	*x = Language{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Language) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Language) ProtoMessage() {}
+
+func (x *Language) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Language.ProtoReflect.Descriptor instead.
+func (*Language) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Language) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *Language) GetProbability() float64 {
+	if x != nil {
+		return x.Probability
+	}
+	return 0
+}
+
+// LanguagesResponse is response of /languages
+type LanguagesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Languages     []*Language            `protobuf:"bytes,1,rep,name=languages,proto3" json:"languages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LanguagesResponse) Reset() {
+	*x = LanguagesResponse{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LanguagesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LanguagesResponse) ProtoMessage() {}
+
+func (x *LanguagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LanguagesResponse.ProtoReflect.Descriptor instead.
+func (*LanguagesResponse) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *LanguagesResponse) GetLanguages() []*Language {
+	if x != nil {
+		return x.Languages
+	}
+	return nil
+}
+
+// ExtractedAddress is address found in the text
+type ExtractedAddress struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	Start         int64                  `protobuf:"varint,2,opt,name=start,proto3" json:"start,omitempty"`
+	End           int64                  `protobuf:"varint,3,opt,name=end,proto3" json:"end,omitempty"`
+	Confidence    float64                `protobuf:"fixed64,4,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	Components    []*ParsedComponent     `protobuf:"bytes,5,rep,name=components,proto3" json:"components,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtractedAddress) Reset() {
+	*x = ExtractedAddress{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtractedAddress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtractedAddress) ProtoMessage() {}
+
+func (x *ExtractedAddress) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtractedAddress.ProtoReflect.Descriptor instead.
+func (*ExtractedAddress) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ExtractedAddress) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *ExtractedAddress) GetStart() int64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *ExtractedAddress) GetEnd() int64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *ExtractedAddress) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *ExtractedAddress) GetComponents() []*ParsedComponent {
+	if x != nil {
+		return x.Components
+	}
+	return nil
+}
+
+// ExtractResponse is response of /extract
+type ExtractResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Addresses     []*ExtractedAddress    `protobuf:"bytes,1,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtractResponse) Reset() {
+	*x = ExtractResponse{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtractResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtractResponse) ProtoMessage() {}
+
+func (x *ExtractResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtractResponse.ProtoReflect.Descriptor instead.
+func (*ExtractResponse) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ExtractResponse) GetAddresses() []*ExtractedAddress {
+	if x != nil {
+		return x.Addresses
+	}
+	return nil
+}
+
+// ComponentSimilarity is similarity of one label of both addresses
+type ComponentSimilarity struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Label            string                 `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	Value1           string                 `protobuf:"bytes,2,opt,name=value1,proto3" json:"value1,omitempty"`
+	Value2           string                 `protobuf:"bytes,3,opt,name=value2,proto3" json:"value2,omitempty"`
+	ExpansionOverlap float64                `protobuf:"fixed64,4,opt,name=expansion_overlap,json=expansionOverlap,proto3" json:"expansion_overlap,omitempty"`
+	TokenJaccard     float64                `protobuf:"fixed64,5,opt,name=token_jaccard,json=tokenJaccard,proto3" json:"token_jaccard,omitempty"`
+	NumericEqual     *bool                  `protobuf:"varint,6,opt,name=numeric_equal,json=numericEqual,proto3,oneof" json:"numeric_equal,omitempty"`
+	Score            float64                `protobuf:"fixed64,7,opt,name=score,proto3" json:"score,omitempty"`
+	Weight           float64                `protobuf:"fixed64,8,opt,name=weight,proto3" json:"weight,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ComponentSimilarity) Reset() {
+	*x = ComponentSimilarity{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComponentSimilarity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComponentSimilarity) ProtoMessage() {}
+
+func (x *ComponentSimilarity) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComponentSimilarity.ProtoReflect.Descriptor instead.
+func (*ComponentSimilarity) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ComponentSimilarity) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *ComponentSimilarity) GetValue1() string {
+	if x != nil {
+		return x.Value1
+	}
+	return ""
+}
+
+func (x *ComponentSimilarity) GetValue2() string {
+	if x != nil {
+		return x.Value2
+	}
+	return ""
+}
+
+func (x *ComponentSimilarity) GetExpansionOverlap() float64 {
+	if x != nil {
+		return x.ExpansionOverlap
+	}
+	return 0
+}
+
+func (x *ComponentSimilarity) GetTokenJaccard() float64 {
+	if x != nil {
+		return x.TokenJaccard
+	}
+	return 0
+}
+
+func (x *ComponentSimilarity) GetNumericEqual() bool {
+	if x != nil && x.NumericEqual != nil {
+		return *x.NumericEqual
+	}
+	return false
+}
+
+func (x *ComponentSimilarity) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *ComponentSimilarity) GetWeight() float64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+// AddressSimilarity is response of /compare with single pair
+type AddressSimilarity struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Score         float64                `protobuf:"fixed64,1,opt,name=score,proto3" json:"score,omitempty"`
+	Components    []*ComponentSimilarity `protobuf:"bytes,2,rep,name=components,proto3" json:"components,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddressSimilarity) Reset() {
+	*x = AddressSimilarity{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddressSimilarity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddressSimilarity) ProtoMessage() {}
+
+func (x *AddressSimilarity) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddressSimilarity.ProtoReflect.Descriptor instead.
+func (*AddressSimilarity) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *AddressSimilarity) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *AddressSimilarity) GetComponents() []*ComponentSimilarity {
+	if x != nil {
+		return x.Components
+	}
+	return nil
+}
+
+// CompareResponse is response of /compare with batch of pairs
+type CompareResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Similarities  []*AddressSimilarity   `protobuf:"bytes,1,rep,name=similarities,proto3" json:"similarities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompareResponse) Reset() {
+	*x = CompareResponse{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompareResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompareResponse) ProtoMessage() {}
+
+func (x *CompareResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompareResponse.ProtoReflect.Descriptor instead.
+func (*CompareResponse) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *CompareResponse) GetSimilarities() []*AddressSimilarity {
+	if x != nil {
+		return x.Similarities
+	}
+	return nil
+}
+
+// Token is token of the address
+type Token struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Offset        int64                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	Length        int64                  `protobuf:"varint,4,opt,name=length,proto3" json:"length,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Token) Reset() {
+	*x = Token{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Token) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Token) ProtoMessage() {}
+
+func (x *Token) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Token.ProtoReflect.Descriptor instead.
+func (*Token) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *Token) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *Token) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Token) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *Token) GetLength() int64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+// TokenizeResponse is response of /tokenize
+type TokenizeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tokens        []*Token               `protobuf:"bytes,1,rep,name=tokens,proto3" json:"tokens,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TokenizeResponse) Reset() {
+	*x = TokenizeResponse{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenizeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenizeResponse) ProtoMessage() {}
+
+func (x *TokenizeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenizeResponse.ProtoReflect.Descriptor instead.
+func (*TokenizeResponse) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *TokenizeResponse) GetTokens() []*Token {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+// NormalizedToken is normalized token of the string
+type NormalizedToken struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Normalized    string                 `protobuf:"bytes,1,opt,name=normalized,proto3" json:"normalized,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Offset        int64                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	Length        int64                  `protobuf:"varint,4,opt,name=length,proto3" json:"length,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NormalizedToken) Reset() {
+	*x = NormalizedToken{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NormalizedToken) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NormalizedToken) ProtoMessage() {}
+
+func (x *NormalizedToken) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NormalizedToken.ProtoReflect.Descriptor instead.
+func (*NormalizedToken) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *NormalizedToken) GetNormalized() string {
+	if x != nil {
+		return x.Normalized
+	}
+	return ""
+}
+
+func (x *NormalizedToken) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *NormalizedToken) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *NormalizedToken) GetLength() int64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+// NormalizedString is response of /normalize_string
+type NormalizedString struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Normalized    string                 `protobuf:"bytes,1,opt,name=normalized,proto3" json:"normalized,omitempty"`
+	Tokens        []*NormalizedToken     `protobuf:"bytes,2,rep,name=tokens,proto3" json:"tokens,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NormalizedString) Reset() {
+	*x = NormalizedString{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NormalizedString) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NormalizedString) ProtoMessage() {}
+
+func (x *NormalizedString) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NormalizedString.ProtoReflect.Descriptor instead.
+func (*NormalizedString) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *NormalizedString) GetNormalized() string {
+	if x != nil {
+		return x.Normalized
+	}
+	return ""
+}
+
+func (x *NormalizedString) GetTokens() []*NormalizedToken {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+// PostcodeResult is response of /postcode
+type PostcodeResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Postcode      string                 `protobuf:"bytes,1,opt,name=postcode,proto3" json:"postcode,omitempty"`
+	Country       string                 `protobuf:"bytes,2,opt,name=country,proto3" json:"country,omitempty"`
+	Supported     bool                   `protobuf:"varint,3,opt,name=supported,proto3" json:"supported,omitempty"`
+	Valid         bool                   `protobuf:"varint,4,opt,name=valid,proto3" json:"valid,omitempty"`
+	Canonical     string                 `protobuf:"bytes,5,opt,name=canonical,proto3" json:"canonical,omitempty"`
+	Countries     []string               `protobuf:"bytes,6,rep,name=countries,proto3" json:"countries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PostcodeResult) Reset() {
+	*x = PostcodeResult{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PostcodeResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PostcodeResult) ProtoMessage() {}
+
+func (x *PostcodeResult) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PostcodeResult.ProtoReflect.Descriptor instead.
+func (*PostcodeResult) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *PostcodeResult) GetPostcode() string {
+	if x != nil {
+		return x.Postcode
+	}
+	return ""
+}
+
+func (x *PostcodeResult) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *PostcodeResult) GetSupported() bool {
+	if x != nil {
+		return x.Supported
+	}
+	return false
+}
+
+func (x *PostcodeResult) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *PostcodeResult) GetCanonical() string {
+	if x != nil {
+		return x.Canonical
+	}
+	return ""
+}
+
+func (x *PostcodeResult) GetCountries() []string {
+	if x != nil {
+		return x.Countries
+	}
+	return nil
+}
+
+// USPSAddress is response of standard=usps
+type USPSAddress struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	DeliveryLine        string                 `protobuf:"bytes,1,opt,name=delivery_line,json=deliveryLine,proto3" json:"delivery_line,omitempty"`
+	LastLine            string                 `protobuf:"bytes,2,opt,name=last_line,json=lastLine,proto3" json:"last_line,omitempty"`
+	PrimaryNumber       string                 `protobuf:"bytes,3,opt,name=primary_number,json=primaryNumber,proto3" json:"primary_number,omitempty"`
+	Predirectional      string                 `protobuf:"bytes,4,opt,name=predirectional,proto3" json:"predirectional,omitempty"`
+	StreetName          string                 `protobuf:"bytes,5,opt,name=street_name,json=streetName,proto3" json:"street_name,omitempty"`
+	Suffix              string                 `protobuf:"bytes,6,opt,name=suffix,proto3" json:"suffix,omitempty"`
+	Postdirectional     string                 `protobuf:"bytes,7,opt,name=postdirectional,proto3" json:"postdirectional,omitempty"`
+	SecondaryDesignator string                 `protobuf:"bytes,8,opt,name=secondary_designator,json=secondaryDesignator,proto3" json:"secondary_designator,omitempty"`
+	SecondaryNumber     string                 `protobuf:"bytes,9,opt,name=secondary_number,json=secondaryNumber,proto3" json:"secondary_number,omitempty"`
+	PoBox               string                 `protobuf:"bytes,10,opt,name=po_box,json=poBox,proto3" json:"po_box,omitempty"`
+	City                string                 `protobuf:"bytes,11,opt,name=city,proto3" json:"city,omitempty"`
+	State               string                 `protobuf:"bytes,12,opt,name=state,proto3" json:"state,omitempty"`
+	ZipCode             string                 `protobuf:"bytes,13,opt,name=zip_code,json=zipCode,proto3" json:"zip_code,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *USPSAddress) Reset() {
+	*x = USPSAddress{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *USPSAddress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*USPSAddress) ProtoMessage() {}
+
+func (x *USPSAddress) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use USPSAddress.ProtoReflect.Descriptor instead.
+func (*USPSAddress) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *USPSAddress) GetDeliveryLine() string {
+	if x != nil {
+		return x.DeliveryLine
+	}
+	return ""
+}
+
+func (x *USPSAddress) GetLastLine() string {
+	if x != nil {
+		return x.LastLine
+	}
+	return ""
+}
+
+func (x *USPSAddress) GetPrimaryNumber() string {
+	if x != nil {
+		return x.PrimaryNumber
+	}
+	return ""
+}
+
+func (x *USPSAddress) GetPredirectional() string {
+	if x != nil {
+		return x.Predirectional
+	}
+	return ""
+}
+
+func (x *USPSAddress) GetStreetName() string {
+	if x != nil {
+		return x.StreetName
+	}
+	return ""
+}
+
+func (x *USPSAddress) GetSuffix() string {
+	if x != nil {
+		return x.Suffix
+	}
+	return ""
+}
+
+func (x *USPSAddress) GetPostdirectional() string {
+	if x != nil {
+		return x.Postdirectional
+	}
+	return ""
+}
+
+func (x *USPSAddress) GetSecondaryDesignator() string {
+	if x != nil {
+		return x.SecondaryDesignator
+	}
+	return ""
+}
+
+func (x *USPSAddress) GetSecondaryNumber() string {
+	if x != nil {
+		return x.SecondaryNumber
+	}
+	return ""
+}
+
+func (x *USPSAddress) GetPoBox() string {
+	if x != nil {
+		return x.PoBox
+	}
+	return ""
+}
+
+func (x *USPSAddress) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *USPSAddress) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *USPSAddress) GetZipCode() string {
+	if x != nil {
+		return x.ZipCode
+	}
+	return ""
+}
+
+// SchemaOrgPostalAddress is response of standard=schema_org, JSON-LD
+// "@context" and "@type" are context and type
+type SchemaOrgPostalAddress struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Context             string                 `protobuf:"bytes,1,opt,name=context,proto3" json:"context,omitempty"`
+	Type                string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Name                string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	StreetAddress       string                 `protobuf:"bytes,4,opt,name=street_address,json=streetAddress,proto3" json:"street_address,omitempty"`
+	PostOfficeBoxNumber string                 `protobuf:"bytes,5,opt,name=post_office_box_number,json=postOfficeBoxNumber,proto3" json:"post_office_box_number,omitempty"`
+	AddressLocality     string                 `protobuf:"bytes,6,opt,name=address_locality,json=addressLocality,proto3" json:"address_locality,omitempty"`
+	AddressRegion       string                 `protobuf:"bytes,7,opt,name=address_region,json=addressRegion,proto3" json:"address_region,omitempty"`
+	PostalCode          string                 `protobuf:"bytes,8,opt,name=postal_code,json=postalCode,proto3" json:"postal_code,omitempty"`
+	AddressCountry      string                 `protobuf:"bytes,9,opt,name=address_country,json=addressCountry,proto3" json:"address_country,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *SchemaOrgPostalAddress) Reset() {
+	*x = SchemaOrgPostalAddress{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SchemaOrgPostalAddress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SchemaOrgPostalAddress) ProtoMessage() {}
+
+func (x *SchemaOrgPostalAddress) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SchemaOrgPostalAddress.ProtoReflect.Descriptor instead.
+func (*SchemaOrgPostalAddress) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *SchemaOrgPostalAddress) GetContext() string {
+	if x != nil {
+		return x.Context
+	}
+	return ""
+}
+
+func (x *SchemaOrgPostalAddress) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SchemaOrgPostalAddress) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SchemaOrgPostalAddress) GetStreetAddress() string {
+	if x != nil {
+		return x.StreetAddress
+	}
+	return ""
+}
+
+func (x *SchemaOrgPostalAddress) GetPostOfficeBoxNumber() string {
+	if x != nil {
+		return x.PostOfficeBoxNumber
+	}
+	return ""
+}
+
+func (x *SchemaOrgPostalAddress) GetAddressLocality() string {
+	if x != nil {
+		return x.AddressLocality
+	}
+	return ""
+}
+
+func (x *SchemaOrgPostalAddress) GetAddressRegion() string {
+	if x != nil {
+		return x.AddressRegion
+	}
+	return ""
+}
+
+func (x *SchemaOrgPostalAddress) GetPostalCode() string {
+	if x != nil {
+		return x.PostalCode
+	}
+	return ""
+}
+
+func (x *SchemaOrgPostalAddress) GetAddressCountry() string {
+	if x != nil {
+		return x.AddressCountry
+	}
+	return ""
+}
+
+// VCardAddress is response of standard=vcard
+type VCardAddress struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Adr             string                 `protobuf:"bytes,1,opt,name=adr,proto3" json:"adr,omitempty"`
+	PostOfficeBox   string                 `protobuf:"bytes,2,opt,name=post_office_box,json=postOfficeBox,proto3" json:"post_office_box,omitempty"`
+	ExtendedAddress string                 `protobuf:"bytes,3,opt,name=extended_address,json=extendedAddress,proto3" json:"extended_address,omitempty"`
+	StreetAddress   string                 `protobuf:"bytes,4,opt,name=street_address,json=streetAddress,proto3" json:"street_address,omitempty"`
+	Locality        string                 `protobuf:"bytes,5,opt,name=locality,proto3" json:"locality,omitempty"`
+	Region          string                 `protobuf:"bytes,6,opt,name=region,proto3" json:"region,omitempty"`
+	PostalCode      string                 `protobuf:"bytes,7,opt,name=postal_code,json=postalCode,proto3" json:"postal_code,omitempty"`
+	CountryName     string                 `protobuf:"bytes,8,opt,name=country_name,json=countryName,proto3" json:"country_name,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *VCardAddress) Reset() {
+	*x = VCardAddress{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VCardAddress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VCardAddress) ProtoMessage() {}
+
+func (x *VCardAddress) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VCardAddress.ProtoReflect.Descriptor instead.
+func (*VCardAddress) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *VCardAddress) GetAdr() string {
+	if x != nil {
+		return x.Adr
+	}
+	return ""
+}
+
+func (x *VCardAddress) GetPostOfficeBox() string {
+	if x != nil {
+		return x.PostOfficeBox
+	}
+	return ""
+}
+
+func (x *VCardAddress) GetExtendedAddress() string {
+	if x != nil {
+		return x.ExtendedAddress
+	}
+	return ""
+}
+
+func (x *VCardAddress) GetStreetAddress() string {
+	if x != nil {
+		return x.StreetAddress
+	}
+	return ""
+}
+
+func (x *VCardAddress) GetLocality() string {
+	if x != nil {
+		return x.Locality
+	}
+	return ""
+}
+
+func (x *VCardAddress) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *VCardAddress) GetPostalCode() string {
+	if x != nil {
+		return x.PostalCode
+	}
+	return ""
+}
+
+func (x *VCardAddress) GetCountryName() string {
+	if x != nil {
+		return x.CountryName
+	}
+	return ""
+}
+
+// OSMAddress is response of standard=osm
+type OSMAddress struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tags          map[string]string      `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OSMAddress) Reset() {
+	*x = OSMAddress{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OSMAddress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OSMAddress) ProtoMessage() {}
+
+func (x *OSMAddress) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OSMAddress.ProtoReflect.Descriptor instead.
+func (*OSMAddress) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *OSMAddress) GetTags() map[string]string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+// LibaddressinputAddress is response of standard=libaddressinput
+type LibaddressinputAddress struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	RegionCode         string                 `protobuf:"bytes,1,opt,name=region_code,json=regionCode,proto3" json:"region_code,omitempty"`
+	AdministrativeArea string                 `protobuf:"bytes,2,opt,name=administrative_area,json=administrativeArea,proto3" json:"administrative_area,omitempty"`
+	Locality           string                 `protobuf:"bytes,3,opt,name=locality,proto3" json:"locality,omitempty"`
+	DependentLocality  string                 `protobuf:"bytes,4,opt,name=dependent_locality,json=dependentLocality,proto3" json:"dependent_locality,omitempty"`
+	PostalCode         string                 `protobuf:"bytes,5,opt,name=postal_code,json=postalCode,proto3" json:"postal_code,omitempty"`
+	AddressLine        []string               `protobuf:"bytes,6,rep,name=address_line,json=addressLine,proto3" json:"address_line,omitempty"`
+	Organization       string                 `protobuf:"bytes,7,opt,name=organization,proto3" json:"organization,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *LibaddressinputAddress) Reset() {
+	*x = LibaddressinputAddress{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LibaddressinputAddress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LibaddressinputAddress) ProtoMessage() {}
+
+func (x *LibaddressinputAddress) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LibaddressinputAddress.ProtoReflect.Descriptor instead.
+func (*LibaddressinputAddress) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *LibaddressinputAddress) GetRegionCode() string {
+	if x != nil {
+		return x.RegionCode
+	}
+	return ""
+}
+
+func (x *LibaddressinputAddress) GetAdministrativeArea() string {
+	if x != nil {
+		return x.AdministrativeArea
+	}
+	return ""
+}
+
+func (x *LibaddressinputAddress) GetLocality() string {
+	if x != nil {
+		return x.Locality
+	}
+	return ""
+}
+
+func (x *LibaddressinputAddress) GetDependentLocality() string {
+	if x != nil {
+		return x.DependentLocality
+	}
+	return ""
+}
+
+func (x *LibaddressinputAddress) GetPostalCode() string {
+	if x != nil {
+		return x.PostalCode
+	}
+	return ""
+}
+
+func (x *LibaddressinputAddress) GetAddressLine() []string {
+	if x != nil {
+		return x.AddressLine
+	}
+	return nil
+}
+
+func (x *LibaddressinputAddress) GetOrganization() string {
+	if x != nil {
+		return x.Organization
+	}
+	return ""
+}
+
+// Job is async job
+type Job struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Operation      string                 `protobuf:"bytes,2,opt,name=operation,proto3" json:"operation,omitempty"`
+	Status         string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	Error          string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	Options        map[string]*Job_Values `protobuf:"bytes,5,rep,name=options,proto3" json:"options,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Progress       *JobProgress           `protobuf:"bytes,6,opt,name=progress,proto3" json:"progress,omitempty"`
+	IdempotencyKey string                 `protobuf:"bytes,7,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	InputSha256    string                 `protobuf:"bytes,8,opt,name=input_sha256,json=inputSha256,proto3" json:"input_sha256,omitempty"`
+	Webhook        *JobWebhook            `protobuf:"bytes,9,opt,name=webhook,proto3" json:"webhook,omitempty"`
+	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	StartedAt      *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt     *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Job) Reset() {
+	*x = Job{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Job) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Job) ProtoMessage() {}
+
+func (x *Job) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Job.ProtoReflect.Descriptor instead.
+func (*Job) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *Job) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Job) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+func (x *Job) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Job) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Job) GetOptions() map[string]*Job_Values {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *Job) GetProgress() *JobProgress {
+	if x != nil {
+		return x.Progress
+	}
+	return nil
+}
+
+func (x *Job) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *Job) GetInputSha256() string {
+	if x != nil {
+		return x.InputSha256
+	}
+	return ""
+}
+
+func (x *Job) GetWebhook() *JobWebhook {
+	if x != nil {
+		return x.Webhook
+	}
+	return nil
+}
+
+func (x *Job) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Job) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *Job) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
+
+// JobProgress is progress of async job
+type JobProgress struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rows          int64                  `protobuf:"varint,1,opt,name=rows,proto3" json:"rows,omitempty"`
+	Dedupe        *DedupeProgress        `protobuf:"bytes,2,opt,name=dedupe,proto3" json:"dedupe,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobProgress) Reset() {
+	*x = JobProgress{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobProgress) ProtoMessage() {}
+
+func (x *JobProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobProgress.ProtoReflect.Descriptor instead.
+func (*JobProgress) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *JobProgress) GetRows() int64 {
+	if x != nil {
+		return x.Rows
+	}
+	return 0
+}
+
+func (x *JobProgress) GetDedupe() *DedupeProgress {
+	if x != nil {
+		return x.Dedupe
+	}
+	return nil
+}
+
+// DedupeProgress is progress of dedupe job
+type DedupeProgress struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Phase         string                 `protobuf:"bytes,1,opt,name=phase,proto3" json:"phase,omitempty"`
+	Rows          int64                  `protobuf:"varint,2,opt,name=rows,proto3" json:"rows,omitempty"`
+	Blocks        int64                  `protobuf:"varint,3,opt,name=blocks,proto3" json:"blocks,omitempty"`
+	SkippedBlocks int64                  `protobuf:"varint,4,opt,name=skipped_blocks,json=skippedBlocks,proto3" json:"skipped_blocks,omitempty"`
+	Comparisons   int64                  `protobuf:"varint,5,opt,name=comparisons,proto3" json:"comparisons,omitempty"`
+	Clusters      int64                  `protobuf:"varint,6,opt,name=clusters,proto3" json:"clusters,omitempty"`
+	Duplicates    int64                  `protobuf:"varint,7,opt,name=duplicates,proto3" json:"duplicates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DedupeProgress) Reset() {
+	*x = DedupeProgress{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DedupeProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DedupeProgress) ProtoMessage() {}
+
+func (x *DedupeProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DedupeProgress.ProtoReflect.Descriptor instead.
+func (*DedupeProgress) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *DedupeProgress) GetPhase() string {
+	if x != nil {
+		return x.Phase
+	}
+	return ""
+}
+
+func (x *DedupeProgress) GetRows() int64 {
+	if x != nil {
+		return x.Rows
+	}
+	return 0
+}
+
+func (x *DedupeProgress) GetBlocks() int64 {
+	if x != nil {
+		return x.Blocks
+	}
+	return 0
+}
+
+func (x *DedupeProgress) GetSkippedBlocks() int64 {
+	if x != nil {
+		return x.SkippedBlocks
+	}
+	return 0
+}
+
+func (x *DedupeProgress) GetComparisons() int64 {
+	if x != nil {
+		return x.Comparisons
+	}
+	return 0
+}
+
+func (x *DedupeProgress) GetClusters() int64 {
+	if x != nil {
+		return x.Clusters
+	}
+	return 0
+}
+
+func (x *DedupeProgress) GetDuplicates() int64 {
+	if x != nil {
+		return x.Duplicates
+	}
+	return 0
+}
+
+// JobWebhook is callback of async job
+type JobWebhook struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Deliveries    []*WebhookDelivery     `protobuf:"bytes,3,rep,name=deliveries,proto3" json:"deliveries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobWebhook) Reset() {
+	*x = JobWebhook{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobWebhook) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobWebhook) ProtoMessage() {}
+
+func (x *JobWebhook) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobWebhook.ProtoReflect.Descriptor instead.
+func (*JobWebhook) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *JobWebhook) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *JobWebhook) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *JobWebhook) GetDeliveries() []*WebhookDelivery {
+	if x != nil {
+		return x.Deliveries
+	}
+	return nil
+}
+
+// WebhookDelivery is one delivery attempt of job webhook
+type WebhookDelivery struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Attempt       int64                  `protobuf:"varint,1,opt,name=attempt,proto3" json:"attempt,omitempty"`
+	Event         string                 `protobuf:"bytes,2,opt,name=event,proto3" json:"event,omitempty"`
+	At            *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=at,proto3" json:"at,omitempty"`
+	DurationMs    int64                  `protobuf:"varint,4,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	StatusCode    int64                  `protobuf:"varint,5,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
+	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WebhookDelivery) Reset() {
+	*x = WebhookDelivery{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WebhookDelivery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WebhookDelivery) ProtoMessage() {}
+
+func (x *WebhookDelivery) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WebhookDelivery.ProtoReflect.Descriptor instead.
+func (*WebhookDelivery) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *WebhookDelivery) GetAttempt() int64 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
+}
+
+func (x *WebhookDelivery) GetEvent() string {
+	if x != nil {
+		return x.Event
+	}
+	return ""
+}
+
+func (x *WebhookDelivery) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.At
+	}
+	return nil
+}
+
+func (x *WebhookDelivery) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *WebhookDelivery) GetStatusCode() int64 {
+	if x != nil {
+		return x.StatusCode
+	}
+	return 0
+}
+
+func (x *WebhookDelivery) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type Job_Values struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Values        []string               `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Job_Values) Reset() {
+	*x = Job_Values{}
+	mi := &file_postalpb_postal_server_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Job_Values) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Job_Values) ProtoMessage() {}
+
+func (x *Job_Values) ProtoReflect() protoreflect.Message {
+	mi := &file_postalpb_postal_server_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Job_Values.ProtoReflect.Descriptor instead.
+func (*Job_Values) Descriptor() ([]byte, []int) {
+	return file_postalpb_postal_server_proto_rawDescGZIP(), []int{29, 0}
+}
+
+func (x *Job_Values) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+var File_postalpb_postal_server_proto protoreflect.FileDescriptor
+
+const file_postalpb_postal_server_proto_rawDesc = "" +
+	"\n" +
+	"\x1cpostalpb/postal_server.proto\x12\x10postal_server.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"<\n" +
+	"\x05Error\x12\x14\n" +
+	"\x05error\x18\x01 \x01(\tR\x05error\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x02 \x01(\tR\trequestId\" \n" +
+	"\x06Health\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\"#\n" +
+	"\aVersion\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\"\xdf\x01\n" +
+	"\x0fParsedComponent\x12\x14\n" +
+	"\x05label\x18\x01 \x01(\tR\x05label\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\x12!\n" +
+	"\fcountry_code\x18\x03 \x01(\tR\vcountryCode\x12\x1d\n" +
+	"\n" +
+	"state_code\x18\x04 \x01(\tR\tstateCode\x12\x1c\n" +
+	"\tcanonical\x18\x05 \x01(\tR\tcanonical\x12\x19\n" +
+	"\x05valid\x18\x06 \x01(\bH\x00R\x05valid\x88\x01\x01\x12\x1b\n" +
+	"\tparsed_as\x18\a \x03(\tR\bparsedAsB\b\n" +
+	"\x06_valid\"R\n" +
+	"\rParseResponse\x12A\n" +
+	"\n" +
+	"components\x18\x01 \x03(\v2!.postal_server.v1.ParsedComponentR\n" +
+	"components\"0\n" +
+	"\x0eExpandResponse\x12\x1e\n" +
+	"\n" +
+	"expansions\x18\x01 \x03(\tR\n" +
+	"expansions\"\x89\x01\n" +
+	"\x12CanonicalExpansion\x12\x1c\n" +
+	"\tcanonical\x18\x01 \x01(\tR\tcanonical\x12\x12\n" +
+	"\x04hash\x18\x02 \x01(\tR\x04hash\x12!\n" +
+	"\frule_version\x18\x03 \x01(\x05R\vruleVersion\x12\x1e\n" +
+	"\n" +
+	"expansions\x18\x04 \x03(\tR\n" +
+	"expansions\"\\\n" +
+	"\x12ExplainedExpansion\x12\x1c\n" +
+	"\texpansion\x18\x01 \x01(\tR\texpansion\x12(\n" +
+	"\x0ftransformations\x18\x02 \x03(\tR\x0ftransformations\"W\n" +
+	"\x0fExplainResponse\x12D\n" +
+	"\n" +
+	"expansions\x18\x01 \x03(\v2$.postal_server.v1.ExplainedExpansionR\n" +
+	"expansions\"\xc1\x01\n" +
+	"\x13NormalizedComponent\x12\x14\n" +
+	"\x05label\x18\x01 \x01(\tR\x05label\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\x12\x1c\n" +
+	"\tcanonical\x18\x03 \x01(\tR\tcanonical\x12\x1e\n" +
+	"\n" +
+	"expansions\x18\x04 \x03(\tR\n" +
+	"expansions\x12\x1b\n" +
+	"\tparsed_as\x18\x05 \x03(\tR\bparsedAs\x12\x19\n" +
+	"\x05valid\x18\x06 \x01(\bH\x00R\x05valid\x88\x01\x01B\b\n" +
+	"\x06_valid\"\xea\x01\n" +
+	"\x11NormalizedAddress\x12P\n" +
+	"\tcanonical\x18\x01 \x03(\v22.postal_server.v1.NormalizedAddress.CanonicalEntryR\tcanonical\x12E\n" +
+	"\n" +
+	"components\x18\x02 \x03(\v2%.postal_server.v1.NormalizedComponentR\n" +
+	"components\x1a<\n" +
+	"\x0eCanonicalEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"]\n" +
+	"\x14NormalizedComponents\x12E\n" +
+	"\n" +
+	"components\x18\x01 \x03(\v2%.postal_server.v1.NormalizedComponentR\n" +
+	"components\"H\n" +
+	"\bLanguage\x12\x1a\n" +
+	"\blanguage\x18\x01 \x01(\tR\blanguage\x12 \n" +
+	"\vprobability\x18\x02 \x01(\x01R\vprobability\"M\n" +
+	"\x11LanguagesResponse\x128\n" +
+	"\tlanguages\x18\x01 \x03(\v2\x1a.postal_server.v1.LanguageR\tlanguages\"\xb1\x01\n" +
+	"\x10ExtractedAddress\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\x14\n" +
+	"\x05start\x18\x02 \x01(\x03R\x05start\x12\x10\n" +
+	"\x03end\x18\x03 \x01(\x03R\x03end\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x04 \x01(\x01R\n" +
+	"confidence\x12A\n" +
+	"\n" +
+	"components\x18\x05 \x03(\v2!.postal_server.v1.ParsedComponentR\n" +
+	"components\"S\n" +
+	"\x0fExtractResponse\x12@\n" +
+	"\taddresses\x18\x01 \x03(\v2\".postal_server.v1.ExtractedAddressR\taddresses\"\x97\x02\n" +
+	"\x13ComponentSimilarity\x12\x14\n" +
+	"\x05label\x18\x01 \x01(\tR\x05label\x12\x16\n" +
+	"\x06value1\x18\x02 \x01(\tR\x06value1\x12\x16\n" +
+	"\x06value2\x18\x03 \x01(\tR\x06value2\x12+\n" +
+	"\x11expansion_overlap\x18\x04 \x01(\x01R\x10expansionOverlap\x12#\n" +
+	"\rtoken_jaccard\x18\x05 \x01(\x01R\ftokenJaccard\x12(\n" +
+	"\rnumeric_equal\x18\x06 \x01(\bH\x00R\fnumericEqual\x88\x01\x01\x12\x14\n" +
+	"\x05score\x18\a \x01(\x01R\x05score\x12\x16\n" +
+	"\x06weight\x18\b \x01(\x01R\x06weightB\x10\n" +
+	"\x0e_numeric_equal\"p\n" +
+	"\x11AddressSimilarity\x12\x14\n" +
+	"\x05score\x18\x01 \x01(\x01R\x05score\x12E\n" +
+	"\n" +
+	"components\x18\x02 \x03(\v2%.postal_server.v1.ComponentSimilarityR\n" +
+	"components\"Z\n" +
+	"\x0fCompareResponse\x12G\n" +
+	"\fsimilarities\x18\x01 \x03(\v2#.postal_server.v1.AddressSimilarityR\fsimilarities\"a\n" +
+	"\x05Token\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x03R\x06offset\x12\x16\n" +
+	"\x06length\x18\x04 \x01(\x03R\x06length\"C\n" +
+	"\x10TokenizeResponse\x12/\n" +
+	"\x06tokens\x18\x01 \x03(\v2\x17.postal_server.v1.TokenR\x06tokens\"u\n" +
+	"\x0fNormalizedToken\x12\x1e\n" +
+	"\n" +
+	"normalized\x18\x01 \x01(\tR\n" +
+	"normalized\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x03R\x06offset\x12\x16\n" +
+	"\x06length\x18\x04 \x01(\x03R\x06length\"m\n" +
+	"\x10NormalizedString\x12\x1e\n" +
+	"\n" +
+	"normalized\x18\x01 \x01(\tR\n" +
+	"normalized\x129\n" +
+	"\x06tokens\x18\x02 \x03(\v2!.postal_server.v1.NormalizedTokenR\x06tokens\"\xb6\x01\n" +
+	"\x0ePostcodeResult\x12\x1a\n" +
+	"\bpostcode\x18\x01 \x01(\tR\bpostcode\x12\x18\n" +
+	"\acountry\x18\x02 \x01(\tR\acountry\x12\x1c\n" +
+	"\tsupported\x18\x03 \x01(\bR\tsupported\x12\x14\n" +
+	"\x05valid\x18\x04 \x01(\bR\x05valid\x12\x1c\n" +
+	"\tcanonical\x18\x05 \x01(\tR\tcanonical\x12\x1c\n" +
+	"\tcountries\x18\x06 \x03(\tR\tcountries\"\xbb\x03\n" +
+	"\vUSPSAddress\x12#\n" +
+	"\rdelivery_line\x18\x01 \x01(\tR\fdeliveryLine\x12\x1b\n" +
+	"\tlast_line\x18\x02 \x01(\tR\blastLine\x12%\n" +
+	"\x0eprimary_number\x18\x03 \x01(\tR\rprimaryNumber\x12&\n" +
+	"\x0epredirectional\x18\x04 \x01(\tR\x0epredirectional\x12\x1f\n" +
+	"\vstreet_name\x18\x05 \x01(\tR\n" +
+	"streetName\x12\x16\n" +
+	"\x06suffix\x18\x06 \x01(\tR\x06suffix\x12(\n" +
+	"\x0fpostdirectional\x18\a \x01(\tR\x0fpostdirectional\x121\n" +
+	"\x14secondary_designator\x18\b \x01(\tR\x13secondaryDesignator\x12)\n" +
+	"\x10secondary_number\x18\t \x01(\tR\x0fsecondaryNumber\x12\x15\n" +
+	"\x06po_box\x18\n" +
+	" \x01(\tR\x05poBox\x12\x12\n" +
+	"\x04city\x18\v \x01(\tR\x04city\x12\x14\n" +
+	"\x05state\x18\f \x01(\tR\x05state\x12\x19\n" +
+	"\bzip_code\x18\r \x01(\tR\azipCode\"\xd2\x02\n" +
+	"\x16SchemaOrgPostalAddress\x12\x18\n" +
+	"\acontext\x18\x01 \x01(\tR\acontext\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12%\n" +
+	"\x0estreet_address\x18\x04 \x01(\tR\rstreetAddress\x123\n" +
+	"\x16post_office_box_number\x18\x05 \x01(\tR\x13postOfficeBoxNumber\x12)\n" +
+	"\x10address_locality\x18\x06 \x01(\tR\x0faddressLocality\x12%\n" +
+	"\x0eaddress_region\x18\a \x01(\tR\raddressRegion\x12\x1f\n" +
+	"\vpostal_code\x18\b \x01(\tR\n" +
+	"postalCode\x12'\n" +
+	"\x0faddress_country\x18\t \x01(\tR\x0eaddressCountry\"\x92\x02\n" +
+	"\fVCardAddress\x12\x10\n" +
+	"\x03adr\x18\x01 \x01(\tR\x03adr\x12&\n" +
+	"\x0fpost_office_box\x18\x02 \x01(\tR\rpostOfficeBox\x12)\n" +
+	"\x10extended_address\x18\x03 \x01(\tR\x0fextendedAddress\x12%\n" +
+	"\x0estreet_address\x18\x04 \x01(\tR\rstreetAddress\x12\x1a\n" +
+	"\blocality\x18\x05 \x01(\tR\blocality\x12\x16\n" +
+	"\x06region\x18\x06 \x01(\tR\x06region\x12\x1f\n" +
+	"\vpostal_code\x18\a \x01(\tR\n" +
+	"postalCode\x12!\n" +
+	"\fcountry_name\x18\b \x01(\tR\vcountryName\"\x81\x01\n" +
+	"\n" +
+	"OSMAddress\x12:\n" +
+	"\x04tags\x18\x01 \x03(\v2&.postal_server.v1.OSMAddress.TagsEntryR\x04tags\x1a7\n" +
+	"\tTagsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x9d\x02\n" +
+	"\x16LibaddressinputAddress\x12\x1f\n" +
+	"\vregion_code\x18\x01 \x01(\tR\n" +
+	"regionCode\x12/\n" +
+	"\x13administrative_area\x18\x02 \x01(\tR\x12administrativeArea\x12\x1a\n" +
+	"\blocality\x18\x03 \x01(\tR\blocality\x12-\n" +
+	"\x12dependent_locality\x18\x04 \x01(\tR\x11dependentLocality\x12\x1f\n" +
+	"\vpostal_code\x18\x05 \x01(\tR\n" +
+	"postalCode\x12!\n" +
+	"\faddress_line\x18\x06 \x03(\tR\vaddressLine\x12\"\n" +
+	"\forganization\x18\a \x01(\tR\forganization\"\x8d\x05\n" +
+	"\x03Job\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1c\n" +
+	"\toperation\x18\x02 \x01(\tR\toperation\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x12<\n" +
+	"\aoptions\x18\x05 \x03(\v2\".postal_server.v1.Job.OptionsEntryR\aoptions\x129\n" +
+	"\bprogress\x18\x06 \x01(\v2\x1d.postal_server.v1.JobProgressR\bprogress\x12'\n" +
+	"\x0fidempotency_key\x18\a \x01(\tR\x0eidempotencyKey\x12!\n" +
+	"\finput_sha256\x18\b \x01(\tR\vinputSha256\x126\n" +
+	"\awebhook\x18\t \x01(\v2\x1c.postal_server.v1.JobWebhookR\awebhook\x129\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"started_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12;\n" +
+	"\vfinished_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt\x1a \n" +
+	"\x06Values\x12\x16\n" +
+	"\x06values\x18\x01 \x03(\tR\x06values\x1aX\n" +
+	"\fOptionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x122\n" +
+	"\x05value\x18\x02 \x01(\v2\x1c.postal_server.v1.Job.ValuesR\x05value:\x028\x01\"[\n" +
+	"\vJobProgress\x12\x12\n" +
+	"\x04rows\x18\x01 \x01(\x03R\x04rows\x128\n" +
+	"\x06dedupe\x18\x02 \x01(\v2 .postal_server.v1.DedupeProgressR\x06dedupe\"\xd7\x01\n" +
+	"\x0eDedupeProgress\x12\x14\n" +
+	"\x05phase\x18\x01 \x01(\tR\x05phase\x12\x12\n" +
+	"\x04rows\x18\x02 \x01(\x03R\x04rows\x12\x16\n" +
+	"\x06blocks\x18\x03 \x01(\x03R\x06blocks\x12%\n" +
+	"\x0eskipped_blocks\x18\x04 \x01(\x03R\rskippedBlocks\x12 \n" +
+	"\vcomparisons\x18\x05 \x01(\x03R\vcomparisons\x12\x1a\n" +
+	"\bclusters\x18\x06 \x01(\x03R\bclusters\x12\x1e\n" +
+	"\n" +
+	"duplicates\x18\a \x01(\x03R\n" +
+	"duplicates\"y\n" +
+	"\n" +
+	"JobWebhook\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12A\n" +
+	"\n" +
+	"deliveries\x18\x03 \x03(\v2!.postal_server.v1.WebhookDeliveryR\n" +
+	"deliveries\"\xc5\x01\n" +
+	"\x0fWebhookDelivery\x12\x18\n" +
+	"\aattempt\x18\x01 \x01(\x03R\aattempt\x12\x14\n" +
+	"\x05event\x18\x02 \x01(\tR\x05event\x12*\n" +
+	"\x02at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x02at\x12\x1f\n" +
+	"\vduration_ms\x18\x04 \x01(\x03R\n" +
+	"durationMs\x12\x1f\n" +
+	"\vstatus_code\x18\x05 \x01(\x03R\n" +
+	"statusCode\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05errorB+Z)github.com/le0pard/postal_server/postalpbb\x06proto3"
+
+var (
+	file_postalpb_postal_server_proto_rawDescOnce sync.Once
+	file_postalpb_postal_server_proto_rawDescData []byte
+)
+
+func file_postalpb_postal_server_proto_rawDescGZIP() []byte {
+	file_postalpb_postal_server_proto_rawDescOnce.Do(func() {
+		file_postalpb_postal_server_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_postalpb_postal_server_proto_rawDesc), len(file_postalpb_postal_server_proto_rawDesc)))
+	})
+	return file_postalpb_postal_server_proto_rawDescData
+}
+
+var file_postalpb_postal_server_proto_msgTypes = make([]protoimpl.MessageInfo, 38)
+var file_postalpb_postal_server_proto_goTypes = []any{
+	(*Error)(nil),                  // 0: postal_server.v1.Error
+	(*Health)(nil),                 // 1: postal_server.v1.Health
+	(*Version)(nil),                // 2: postal_server.v1.Version
+	(*ParsedComponent)(nil),        // 3: postal_server.v1.ParsedComponent
+	(*ParseResponse)(nil),          // 4: postal_server.v1.ParseResponse
+	(*ExpandResponse)(nil),         // 5: postal_server.v1.ExpandResponse
+	(*CanonicalExpansion)(nil),     // 6: postal_server.v1.CanonicalExpansion
+	(*ExplainedExpansion)(nil),     // 7: postal_server.v1.ExplainedExpansion
+	(*ExplainResponse)(nil),        // 8: postal_server.v1.ExplainResponse
+	(*NormalizedComponent)(nil),    // 9: postal_server.v1.NormalizedComponent
+	(*NormalizedAddress)(nil),      // 10: postal_server.v1.NormalizedAddress
+	(*NormalizedComponents)(nil),   // 11: postal_server.v1.NormalizedComponents
+	(*Language)(nil),               // 12: postal_server.v1.Language
+	(*LanguagesResponse)(nil),      // 13: postal_server.v1.LanguagesResponse
+	(*ExtractedAddress)(nil),       // 14: postal_server.v1.ExtractedAddress
+	(*ExtractResponse)(nil),        // 15: postal_server.v1.ExtractResponse
+	(*ComponentSimilarity)(nil),    // 16: postal_server.v1.ComponentSimilarity
+	(*AddressSimilarity)(nil),      // 17: postal_server.v1.AddressSimilarity
+	(*CompareResponse)(nil),        // 18: postal_server.v1.CompareResponse
+	(*Token)(nil),                  // 19: postal_server.v1.Token
+	(*TokenizeResponse)(nil),       // 20: postal_server.v1.TokenizeResponse
+	(*NormalizedToken)(nil),        // 21: postal_server.v1.NormalizedToken
+	(*NormalizedString)(nil),       // 22: postal_server.v1.NormalizedString
+	(*PostcodeResult)(nil),         // 23: postal_server.v1.PostcodeResult
+	(*USPSAddress)(nil),            // 24: postal_server.v1.USPSAddress
+	(*SchemaOrgPostalAddress)(nil), // 25: postal_server.v1.SchemaOrgPostalAddress
+	(*VCardAddress)(nil),           // 26: postal_server.v1.VCardAddress
+	(*OSMAddress)(nil),             // 27: postal_server.v1.OSMAddress
+	(*LibaddressinputAddress)(nil), // 28: postal_server.v1.LibaddressinputAddress
+	(*Job)(nil),                    // 29: postal_server.v1.Job
+	(*JobProgress)(nil),            // 30: postal_server.v1.JobProgress
+	(*DedupeProgress)(nil),         // 31: postal_server.v1.DedupeProgress
+	(*JobWebhook)(nil),             // 32: postal_server.v1.JobWebhook
+	(*WebhookDelivery)(nil),        // 33: postal_server.v1.WebhookDelivery
+	nil,                            // 34: postal_server.v1.NormalizedAddress.CanonicalEntry
+	nil,                            // 35: postal_server.v1.OSMAddress.TagsEntry
+	(*Job_Values)(nil),             // 36: postal_server.v1.Job.Values
+	nil,                            // 37: postal_server.v1.Job.OptionsEntry
+	(*timestamppb.Timestamp)(nil),  // 38: google.protobuf.Timestamp
+}
+var file_postalpb_postal_server_proto_depIdxs = []int32{
+	3,  // 0: postal_server.v1.ParseResponse.components:type_name -> postal_server.v1.ParsedComponent
+	7,  // 1: postal_server.v1.ExplainResponse.expansions:type_name -> postal_server.v1.ExplainedExpansion
+	34, // 2: postal_server.v1.NormalizedAddress.canonical:type_name -> postal_server.v1.NormalizedAddress.CanonicalEntry
+	9,  // 3: postal_server.v1.NormalizedAddress.components:type_name -> postal_server.v1.NormalizedComponent
+	9,  // 4: postal_server.v1.NormalizedComponents.components:type_name -> postal_server.v1.NormalizedComponent
+	12, // 5: postal_server.v1.LanguagesResponse.languages:type_name -> postal_server.v1.Language
+	3,  // 6: postal_server.v1.ExtractedAddress.components:type_name -> postal_server.v1.ParsedComponent
+	14, // 7: postal_server.v1.ExtractResponse.addresses:type_name -> postal_server.v1.ExtractedAddress
+	16, // 8: postal_server.v1.AddressSimilarity.components:type_name -> postal_server.v1.ComponentSimilarity
+	17, // 9: postal_server.v1.CompareResponse.similarities:type_name -> postal_server.v1.AddressSimilarity
+	19, // 10: postal_server.v1.TokenizeResponse.tokens:type_name -> postal_server.v1.Token
+	21, // 11: postal_server.v1.NormalizedString.tokens:type_name -> postal_server.v1.NormalizedToken
+	35, // 12: postal_server.v1.OSMAddress.tags:type_name -> postal_server.v1.OSMAddress.TagsEntry
+	37, // 13: postal_server.v1.Job.options:type_name -> postal_server.v1.Job.OptionsEntry
+	30, // 14: postal_server.v1.Job.progress:type_name -> postal_server.v1.JobProgress
+	32, // 15: postal_server.v1.Job.webhook:type_name -> postal_server.v1.JobWebhook
+	38, // 16: postal_server.v1.Job.created_at:type_name -> google.protobuf.Timestamp
+	38, // 17: postal_server.v1.Job.started_at:type_name -> google.protobuf.Timestamp
+	38, // 18: postal_server.v1.Job.finished_at:type_name -> google.protobuf.Timestamp
+	31, // 19: postal_server.v1.JobProgress.dedupe:type_name -> postal_server.v1.DedupeProgress
+	33, // 20: postal_server.v1.JobWebhook.deliveries:type_name -> postal_server.v1.WebhookDelivery
+	38, // 21: postal_server.v1.WebhookDelivery.at:type_name -> google.protobuf.Timestamp
+	36, // 22: postal_server.v1.Job.OptionsEntry.value:type_name -> postal_server.v1.Job.Values
+	23, // [23:23] is the sub-list for method output_type
+	23, // [23:23] is the sub-list for method input_type
+	23, // [23:23] is the sub-list for extension type_name
+	23, // [23:23] is the sub-list for extension extendee
+	0,  // [0:23] is the sub-list for field type_name
+}
+
+func init() { file_postalpb_postal_server_proto_init() }
+func file_postalpb_postal_server_proto_init() {
+	if File_postalpb_postal_server_proto != nil {
+		return
+	}
+	file_postalpb_postal_server_proto_msgTypes[3].OneofWrappers = []any{}
+	file_postalpb_postal_server_proto_msgTypes[9].OneofWrappers = []any{}
+	file_postalpb_postal_server_proto_msgTypes[16].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_postalpb_postal_server_proto_rawDesc), len(file_postalpb_postal_server_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   38,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_postalpb_postal_server_proto_goTypes,
+		DependencyIndexes: file_postalpb_postal_server_proto_depIdxs,
+		MessageInfos:      file_postalpb_postal_server_proto_msgTypes,
+	}.Build()
+	File_postalpb_postal_server_proto = out.File
+	file_postalpb_postal_server_proto_goTypes = nil
+	file_postalpb_postal_server_proto_depIdxs = nil
+}